@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
+	// Embed the IANA time zone database so --billing-timezone and --session-timezone validate on
+	// hosts and distroless images without /usr/share/zoneinfo
+	_ "time/tzdata"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/grafana/databricks-prometheus-exporter/collector"
@@ -24,24 +29,134 @@ var (
 	warehouseHTTPPath = kingpin.Flag("warehouse-http-path", "The HTTP path of the SQL Warehouse (e.g., /sql/1.0/warehouses/abc123def456).").Envar("DATABRICKS_EXPORTER_WAREHOUSE_HTTP_PATH").Required().String()
 	clientID          = kingpin.Flag("client-id", "The OAuth2 Client ID (Application ID) for Service Principal authentication.").Envar("DATABRICKS_EXPORTER_CLIENT_ID").Required().String()
 	clientSecret      = kingpin.Flag("client-secret", "The OAuth2 Client Secret for Service Principal authentication.").Envar("DATABRICKS_EXPORTER_CLIENT_SECRET").Required().String()
+	serverPort        = kingpin.Flag("server-port", "The Databricks server port used by the SQL connector.").Default("443").Envar("DATABRICKS_EXPORTER_SERVER_PORT").Int()
+
+	// Warehouse failover settings (defaults match collector.DefaultFailoverThreshold/DefaultFailbackInterval)
+	failoverWarehouseHTTPPaths = kingpin.Flag("warehouse-failover-http-path", "HTTP path of a fallback SQL Warehouse, tried in order when the primary is unreachable. Repeatable.").Envar("DATABRICKS_EXPORTER_WAREHOUSE_FAILOVER_HTTP_PATHS").Strings()
+	failoverThreshold          = kingpin.Flag("warehouse-failover-threshold", "Consecutive connection failures before failing over to the next SQL Warehouse.").Default("3").Envar("DATABRICKS_EXPORTER_WAREHOUSE_FAILOVER_THRESHOLD").Int()
+	failbackInterval           = kingpin.Flag("warehouse-failback-interval", "How often to retry the primary SQL Warehouse while failed over.").Default("10m").Envar("DATABRICKS_EXPORTER_WAREHOUSE_FAILBACK_INTERVAL").Duration()
+
+	// Warehouse routing
+	warehouseRoutes = kingpin.Flag("warehouse-route", "Route a collector (billing, jobs, pipelines, queries) or a single query (e.g. billing_cost) to a dedicated SQL Warehouse, as NAME=HTTP_PATH. Repeatable.").Envar("DATABRICKS_EXPORTER_WAREHOUSE_ROUTES").StringMap()
+
+	// Outbound transport settings (SQL connector and OAuth token client)
+	proxyURL    = kingpin.Flag("proxy-url", "HTTP(S) proxy URL for outbound connections to Databricks. Defaults to the HTTPS_PROXY/NO_PROXY environment variables.").Envar("DATABRICKS_EXPORTER_PROXY_URL").String()
+	tlsCAFile   = kingpin.Flag("tls-ca-file", "PEM file with additional CA certificates to trust (e.g. for a TLS-inspecting proxy).").Envar("DATABRICKS_EXPORTER_TLS_CA_FILE").String()
+	tlsCertFile = kingpin.Flag("tls-cert-file", "PEM client certificate for mutual TLS. Requires --tls-key-file.").Envar("DATABRICKS_EXPORTER_TLS_CERT_FILE").String()
+	tlsKeyFile  = kingpin.Flag("tls-key-file", "PEM private key for --tls-cert-file.").Envar("DATABRICKS_EXPORTER_TLS_KEY_FILE").String()
+
+	// Connection pool settings (defaults match collector.DefaultMaxOpenConns etc.)
+	maxOpenConns    = kingpin.Flag("max-open-conns", "Maximum number of open connections to the SQL Warehouse.").Default("10").Envar("DATABRICKS_EXPORTER_MAX_OPEN_CONNS").Int()
+	maxIdleConns    = kingpin.Flag("max-idle-conns", "Maximum number of idle connections kept in the pool; 0 keeps up to 5 within --max-open-conns, -1 keeps none.").Default("0").Envar("DATABRICKS_EXPORTER_MAX_IDLE_CONNS").Int()
+	connMaxLifetime = kingpin.Flag("conn-max-lifetime", "Maximum amount of time a connection may be reused.").Default("5m").Envar("DATABRICKS_EXPORTER_CONN_MAX_LIFETIME").Duration()
+	connMaxIdleTime = kingpin.Flag("conn-max-idle-time", "Maximum amount of time a connection may be idle before being closed.").Default("1m").Envar("DATABRICKS_EXPORTER_CONN_MAX_IDLE_TIME").Duration()
+
+	// Session settings
+	catalog          = kingpin.Flag("catalog", "Initial catalog for SQL sessions.").Envar("DATABRICKS_EXPORTER_CATALOG").String()
+	schema           = kingpin.Flag("schema", "Initial schema for SQL sessions.").Envar("DATABRICKS_EXPORTER_SCHEMA").String()
+	sessionTimezone  = kingpin.Flag("session-timezone", "Time zone for SQL sessions (IANA name, e.g. UTC).").Envar("DATABRICKS_EXPORTER_SESSION_TIMEZONE").String()
+	statementTimeout = kingpin.Flag("statement-timeout", "Server-side statement timeout for SQL sessions (0 uses the warehouse default).").Default("0s").Envar("DATABRICKS_EXPORTER_STATEMENT_TIMEOUT").Duration()
+	sessionParams    = kingpin.Flag("session-param", "Additional Spark/SQL session configuration as KEY=VALUE. Repeatable.").Envar("DATABRICKS_EXPORTER_SESSION_PARAMS").StringMap()
 
 	// Query settings
 	queryTimeout = kingpin.Flag("query-timeout", "Timeout for database queries.").Default("5m").Envar("DATABRICKS_EXPORTER_QUERY_TIMEOUT").Duration()
 
+	// Readiness settings
+	healthCheckInterval = kingpin.Flag("health-check-interval", "How often the background readiness check fetches an OAuth token and rechecks the last scrape's SQL Warehouse connections.").Default("1m").Envar("DATABRICKS_EXPORTER_HEALTH_CHECK_INTERVAL").Duration()
+
 	// Lookback windows
 	billingLookback   = kingpin.Flag("billing-lookback", "How far back to look for billing data.").Default("24h").Envar("DATABRICKS_EXPORTER_BILLING_LOOKBACK").Duration()
 	jobsLookback      = kingpin.Flag("jobs-lookback", "How far back to look for job runs.").Default("3h").Envar("DATABRICKS_EXPORTER_JOBS_LOOKBACK").Duration()
 	pipelinesLookback = kingpin.Flag("pipelines-lookback", "How far back to look for pipeline runs.").Default("3h").Envar("DATABRICKS_EXPORTER_PIPELINES_LOOKBACK").Duration()
 	queriesLookback   = kingpin.Flag("queries-lookback", "How far back to look for SQL warehouse queries.").Default("2h").Envar("DATABRICKS_EXPORTER_QUERIES_LOOKBACK").Duration()
 
-	// SLA settings (default matches collector.DefaultSLAThresholdSeconds)
+	// Job runs in progress (default matches collector.DefaultJobsActiveLookback)
+	jobsActiveLookback  = kingpin.Flag("jobs-active-lookback", "How far back to look for the start of job runs still in progress.").Default("48h").Envar("DATABRICKS_EXPORTER_JOBS_ACTIVE_LOOKBACK").Duration()
+	jobsLiveRuns        = kingpin.Flag("jobs-live-runs", "Read job runs in progress from the Jobs API of the connected workspace instead of system tables.").Default("false").Envar("DATABRICKS_EXPORTER_JOBS_LIVE_RUNS").Bool()
+	jobsLiveWorkspaceID = kingpin.Flag("jobs-live-workspace-id", "Workspace ID of --server-hostname, reported by live job runs when the Jobs API response does not include it.").Envar("DATABRICKS_EXPORTER_JOBS_LIVE_WORKSPACE_ID").String()
+
+	// SLA settings (defaults match collector.DefaultSLAThresholdSeconds and collector.DefaultJobSLATag)
 	slaThreshold = kingpin.Flag("sla-threshold", "Duration threshold (in seconds) for job SLA miss detection.").Default("3600").Envar("DATABRICKS_EXPORTER_SLA_THRESHOLD").Int()
+	jobSLAFile   = kingpin.Flag("job-sla-file", "YAML file with per-job SLA thresholds by job_id or job name pattern, overriding job tags and --sla-threshold.").Envar("DATABRICKS_EXPORTER_JOB_SLA_FILE").String()
+	jobSLATag    = kingpin.Flag("job-sla-tag", "Job tag holding a per-job SLA threshold in seconds, overriding --sla-threshold.").Default("sla_seconds").Envar("DATABRICKS_EXPORTER_JOB_SLA_TAG").String()
+
+	// Job run duration (default buckets match collector.DefaultJobDurationBuckets)
+	jobDurationBuckets          = kingpin.Flag("job-duration-bucket", "Upper bound in seconds of a job run duration histogram bucket. Repeatable.").Default("60", "300", "600", "1800", "3600", "7200", "14400", "28800", "86400").Envar("DATABRICKS_EXPORTER_JOB_DURATION_BUCKETS").Float64List()
+	collectJobDurationQuantiles = kingpin.Flag("collect-job-duration-quantiles", "Also collect the p50/p95/p99 job run duration gauges (deprecated; use the histogram).").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_JOB_DURATION_QUANTILES").Bool()
+
+	// Job and task run phase durations (default buckets match collector.DefaultJobPhaseDurationBuckets)
+	collectJobPhaseDurations  = kingpin.Flag("collect-job-phase-durations", "Collect queue, setup, execution and cleanup duration histograms for job runs.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_JOB_PHASE_DURATIONS").Bool()
+	collectTaskPhaseDurations = kingpin.Flag("collect-task-phase-durations", "Collect setup, execution and cleanup duration histograms for task runs (high cardinality due to task_key label).").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_TASK_PHASE_DURATIONS").Bool()
+	jobPhaseDurationBuckets   = kingpin.Flag("job-phase-duration-bucket", "Upper bound in seconds of a job and task run phase duration histogram bucket. Repeatable.").Default("10", "30", "60", "120", "300", "600", "1800", "3600", "14400", "86400").Envar("DATABRICKS_EXPORTER_JOB_PHASE_DURATION_BUCKETS").Float64List()
 
 	// Cardinality controls
 	collectTaskRetries = kingpin.Flag("collect-task-retries", "Collect task retry metrics (high cardinality due to task_key label).").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_TASK_RETRIES").Bool()
 
+	// Billing cost settings
+	pricingOverridesFile = kingpin.Flag("pricing-overrides-file", "YAML file with negotiated discounts or unit prices per SKU or product, used for effective cost metrics.").Envar("DATABRICKS_EXPORTER_PRICING_OVERRIDES_FILE").String()
+	fxRatesFile          = kingpin.Flag("fx-rates-file", "YAML file with static FX rates to convert list prices into a reporting currency.").Envar("DATABRICKS_EXPORTER_FX_RATES_FILE").String()
+	billingCostMode      = kingpin.Flag("billing-cost-mode", "How billing usage is priced: current (current list prices, cheap) or historical (price effective at usage time, accurate but slower).").Default(collector.BillingCostModeCurrent).Envar("DATABRICKS_EXPORTER_BILLING_COST_MODE").Enum(collector.BillingCostModeCurrent, collector.BillingCostModeHistorical)
+
+	// Budgets
+	budgetsFile = kingpin.Flag("budgets-file", "YAML file with monthly budgets per workspace, SKU or tag, used for budget burn and month-end forecast metrics.").Envar("DATABRICKS_EXPORTER_BUDGETS_FILE").String()
+
+	// Calendar-aligned billing periods
+	collectBillingCalendar = kingpin.Flag("collect-billing-calendar", "Collect billing for today, yesterday, month-to-date and the previous month (scans up to two months of usage).").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_CALENDAR").Bool()
+	billingTimezone        = kingpin.Flag("billing-timezone", "IANA time zone for calendar billing period and budget month boundaries.").Default(collector.DefaultBillingTimezone).Envar("DATABRICKS_EXPORTER_BILLING_TIMEZONE").String()
+
+	// Billing origin product breakdown
+	collectBillingByProduct = kingpin.Flag("collect-billing-by-product", "Collect billing per billing origin product (jobs, DLT, SQL, model serving, ...).").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_PRODUCT").Bool()
+
+	// Serverless and classic compute split
+	collectBillingByCompute = kingpin.Flag("collect-billing-by-compute", "Collect billing split into serverless and classic compute and by Photon use.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_COMPUTE").Bool()
+
+	// Spend by identity (default matches collector.DefaultBillingIdentityLimit)
+	collectBillingByIdentity = kingpin.Flag("collect-billing-by-identity", "Collect billing per run_as or owner identity.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_IDENTITY").Bool()
+	billingIdentityLimit     = kingpin.Flag("billing-identity-limit", "Maximum identities per workspace; the rest are rolled up into \"__other__\".").Default("10").Envar("DATABRICKS_EXPORTER_BILLING_IDENTITY_LIMIT").Int()
+
+	// Cost anomaly detection (defaults match collector.DefaultBillingAnomalyWeeks and DefaultBillingAnomalyOffset)
+	collectBillingAnomaly = kingpin.Flag("collect-billing-anomaly", "Score a recent day's cost per workspace and SKU against the same weekday in previous weeks.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_ANOMALY").Bool()
+	billingAnomalyWeeks   = kingpin.Flag("billing-anomaly-weeks", "Number of previous weeks in the cost anomaly baseline.").Default("4").Envar("DATABRICKS_EXPORTER_BILLING_ANOMALY_WEEKS").Int()
+	billingAnomalyOffset  = kingpin.Flag("billing-anomaly-offset-days", "Days before today of the day whose cost is scored; billing data lags 24-48 hours.").Default("2").Envar("DATABRICKS_EXPORTER_BILLING_ANOMALY_OFFSET_DAYS").Int()
+
+	// Idle SQL warehouse usage
+	collectWarehouseIdle = kingpin.Flag("collect-warehouse-idle", "Collect DBUs and cost of SQL warehouse usage with no query activity.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_WAREHOUSE_IDLE").Bool()
+
+	// Per-query cost attribution (default matches collector.DefaultQueryCostUserLimit)
+	collectQueryCost   = kingpin.Flag("collect-query-cost", "Apportion SQL warehouse cost to queries by execution time, per user and query source.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_QUERY_COST").Bool()
+	queryCostUserLimit = kingpin.Flag("query-cost-user-limit", "Maximum users per warehouse for query cost; the rest are rolled up into \"__other__\".").Default("10").Envar("DATABRICKS_EXPORTER_QUERY_COST_USER_LIMIT").Int()
+
+	// Job and pipeline cost over the billing window
+	collectJobCost      = kingpin.Flag("collect-job-cost", "Collect estimated cost per job and cost per successful run over the billing window.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_JOB_COST").Bool()
+	collectPipelineCost = kingpin.Flag("collect-pipeline-cost", "Collect estimated cost per pipeline and cost per successful update over the billing window.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_PIPELINE_COST").Bool()
+
+	// Billing attribution by usage_metadata key (defaults match collector.DefaultBillingAttributionLimit)
+	collectBillingByJobID         = kingpin.Flag("collect-billing-by-job-id", "Collect billing attributed to usage_metadata.job_id.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_JOB_ID").Bool()
+	billingJobIDLimit             = kingpin.Flag("billing-job-id-limit", "Maximum job_id series per workspace; the rest are rolled up into \"__other__\".").Default("100").Envar("DATABRICKS_EXPORTER_BILLING_JOB_ID_LIMIT").Int()
+	collectBillingByWarehouseID   = kingpin.Flag("collect-billing-by-warehouse-id", "Collect billing attributed to usage_metadata.warehouse_id.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_WAREHOUSE_ID").Bool()
+	billingWarehouseIDLimit       = kingpin.Flag("billing-warehouse-id-limit", "Maximum warehouse_id series per workspace; the rest are rolled up into \"__other__\".").Default("100").Envar("DATABRICKS_EXPORTER_BILLING_WAREHOUSE_ID_LIMIT").Int()
+	collectBillingByClusterID     = kingpin.Flag("collect-billing-by-cluster-id", "Collect billing attributed to usage_metadata.cluster_id.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_CLUSTER_ID").Bool()
+	billingClusterIDLimit         = kingpin.Flag("billing-cluster-id-limit", "Maximum cluster_id series per workspace; the rest are rolled up into \"__other__\".").Default("100").Envar("DATABRICKS_EXPORTER_BILLING_CLUSTER_ID_LIMIT").Int()
+	collectBillingByDLTPipelineID = kingpin.Flag("collect-billing-by-dlt-pipeline-id", "Collect billing attributed to usage_metadata.dlt_pipeline_id.").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_DLT_PIPELINE_ID").Bool()
+	billingDLTPipelineIDLimit     = kingpin.Flag("billing-dlt-pipeline-id-limit", "Maximum dlt_pipeline_id series per workspace; the rest are rolled up into \"__other__\".").Default("100").Envar("DATABRICKS_EXPORTER_BILLING_DLT_PIPELINE_ID_LIMIT").Int()
+	collectBillingByEndpointName  = kingpin.Flag("collect-billing-by-endpoint-name", "Collect billing attributed to usage_metadata.endpoint_name (model serving).").Default("false").Envar("DATABRICKS_EXPORTER_COLLECT_BILLING_BY_ENDPOINT_NAME").Bool()
+	billingEndpointNameLimit      = kingpin.Flag("billing-endpoint-name-limit", "Maximum endpoint_name series per workspace; the rest are rolled up into \"__other__\".").Default("100").Envar("DATABRICKS_EXPORTER_BILLING_ENDPOINT_NAME_LIMIT").Int()
+
+	// Cost allocation by custom tags (default matches collector.DefaultBillingTagPlaceholder)
+	billingTagKeys        = kingpin.Flag("billing-tag-key", "Custom tag key to allocate billing by, exported as a tag_<key> label. Repeatable.").Envar("DATABRICKS_EXPORTER_BILLING_TAG_KEYS").Strings()
+	billingTagPlaceholder = kingpin.Flag("billing-tag-placeholder", "Label value for usage without an allowlisted tag.").Default("untagged").Envar("DATABRICKS_EXPORTER_BILLING_TAG_PLACEHOLDER").String()
+
 	// Table availability settings
 	tableCheckInterval = kingpin.Flag("table-check-interval", "Number of scrapes between table availability checks (for optional tables like pipelines).").Default("10").Envar("DATABRICKS_EXPORTER_TABLE_CHECK_INTERVAL").Int()
+
+	// Commands: serving metrics is the default; reports run one query and exit
+	serveCmd          = kingpin.Command("serve", "Serve Prometheus metrics (default).").Default()
+	reportCmd         = kingpin.Command("report", "Write a billing report and exit.")
+	chargebackCmd     = reportCmd.Command("chargeback", "Write usage and list-price cost for a calendar month per group, workspace and SKU.")
+	chargebackMonth   = chargebackCmd.Flag("month", "Month to report, as YYYY-MM in --billing-timezone (default: the previous month).").String()
+	chargebackGroupBy = chargebackCmd.Flag("group-by", "Group by tag:<key>, product, identity, or a usage_metadata key (job_id, warehouse_id, cluster_id, dlt_pipeline_id, endpoint_name).").Default("product").String()
+	chargebackFormat  = chargebackCmd.Flag("format", "Report format: csv or json.").Default(collector.ChargebackFormatCSV).Enum(collector.ChargebackFormatCSV, collector.ChargebackFormatJSON)
+	chargebackOutput  = chargebackCmd.Flag("output", "File to write the report to (default: standard output).").String()
 )
 
 const (
@@ -52,6 +167,8 @@ const (
 	<body>
 		<h1>Databricks exporter</h1>
 		<p><a href='%s'>Metrics</a></p>
+		<p><a href='/-/healthy'>Health</a></p>
+		<p><a href='/-/ready'>Readiness</a></p>
 	</body>
 </html>`
 )
@@ -63,7 +180,7 @@ func main() {
 
 	flag.AddFlags(kingpin.CommandLine, promslogConfig)
 	kingpin.HelpFlag.Short('h')
-	kingpin.Parse()
+	command := kingpin.Parse()
 
 	logger := promslog.New(promslogConfig)
 
@@ -74,29 +191,161 @@ func main() {
 		WarehouseHTTPPath: *warehouseHTTPPath,
 		ClientID:          *clientID,
 		ClientSecret:      *clientSecret,
+		Port:              *serverPort,
 		QueryTimeout:      *queryTimeout,
 
+		// Readiness settings
+		HealthCheckInterval: *healthCheckInterval,
+
+		// Connection pool settings
+		MaxOpenConns:    *maxOpenConns,
+		MaxIdleConns:    *maxIdleConns,
+		ConnMaxLifetime: *connMaxLifetime,
+		ConnMaxIdleTime: *connMaxIdleTime,
+
+		// Session settings
+		Catalog:          *catalog,
+		Schema:           *schema,
+		SessionTimezone:  *sessionTimezone,
+		StatementTimeout: *statementTimeout,
+		SessionParams:    *sessionParams,
+
+		// Warehouse failover settings
+		FailoverWarehouseHTTPPaths: *failoverWarehouseHTTPPaths,
+		FailoverThreshold:          *failoverThreshold,
+		FailbackInterval:           *failbackInterval,
+
+		// Warehouse routing
+		WarehouseRoutes: *warehouseRoutes,
+
+		// Outbound transport settings
+		ProxyURL:    *proxyURL,
+		TLSCAFile:   *tlsCAFile,
+		TLSCertFile: *tlsCertFile,
+		TLSKeyFile:  *tlsKeyFile,
+
 		// Lookback windows
 		BillingLookback:   *billingLookback,
 		JobsLookback:      *jobsLookback,
 		PipelinesLookback: *pipelinesLookback,
 		QueriesLookback:   *queriesLookback,
 
+		// Job runs in progress
+		JobsActiveLookback:     *jobsActiveLookback,
+		LiveJobRuns:            *jobsLiveRuns,
+		LiveJobRunsWorkspaceID: *jobsLiveWorkspaceID,
+
 		// SLA settings
 		SLAThresholdSeconds: *slaThreshold,
+		JobSLATag:           *jobSLATag,
+
+		// Job run duration
+		JobDurationBuckets:          *jobDurationBuckets,
+		CollectJobDurationQuantiles: *collectJobDurationQuantiles,
+
+		// Job and task run phase durations
+		CollectJobPhaseDurations:  *collectJobPhaseDurations,
+		CollectTaskPhaseDurations: *collectTaskPhaseDurations,
+		JobPhaseDurationBuckets:   *jobPhaseDurationBuckets,
+
+		// Billing cost settings
+		BillingCostMode: *billingCostMode,
+
+		// Calendar-aligned billing periods
+		CollectBillingCalendar: *collectBillingCalendar,
+		BillingTimezone:        *billingTimezone,
+
+		// Billing origin product breakdown
+		CollectBillingByProduct: *collectBillingByProduct,
+
+		// Serverless and classic compute split
+		CollectBillingByCompute: *collectBillingByCompute,
+
+		// Spend by identity
+		CollectBillingByIdentity: *collectBillingByIdentity,
+		BillingIdentityLimit:     *billingIdentityLimit,
+
+		// Cost anomaly detection
+		CollectBillingAnomaly: *collectBillingAnomaly,
+		BillingAnomalyWeeks:   *billingAnomalyWeeks,
+		BillingAnomalyOffset:  *billingAnomalyOffset,
+
+		// Idle SQL warehouse usage
+		CollectWarehouseIdle: *collectWarehouseIdle,
+
+		// Per-query cost attribution
+		CollectQueryCost:   *collectQueryCost,
+		QueryCostUserLimit: *queryCostUserLimit,
+
+		CollectJobCost:      *collectJobCost,
+		CollectPipelineCost: *collectPipelineCost,
 
 		// Cardinality controls
-		CollectTaskRetries: *collectTaskRetries,
+		CollectTaskRetries:       *collectTaskRetries,
+		BillingAttributionLimits: billingAttributionLimits(),
+
+		// Cost allocation by custom tags
+		BillingTagKeys:        *billingTagKeys,
+		BillingTagPlaceholder: *billingTagPlaceholder,
 
 		// Table availability settings
 		TableCheckInterval: *tableCheckInterval,
 	}
 
+	if *pricingOverridesFile != "" {
+		overrides, err := collector.LoadPricingOverrides(*pricingOverridesFile)
+		if err != nil {
+			logger.Error("Failed to load pricing overrides.", "err", err)
+			os.Exit(1)
+		}
+		c.PricingOverrides = overrides
+	}
+
+	if *fxRatesFile != "" {
+		rates, err := collector.LoadFXRates(*fxRatesFile)
+		if err != nil {
+			logger.Error("Failed to load FX rates.", "err", err)
+			os.Exit(1)
+		}
+		c.FXRates = rates
+	}
+
+	if *jobSLAFile != "" {
+		slas, err := collector.LoadJobSLAs(*jobSLAFile)
+		if err != nil {
+			logger.Error("Failed to load job SLAs.", "err", err)
+			os.Exit(1)
+		}
+		c.JobSLAs = slas
+	}
+
+	if *budgetsFile != "" {
+		budgets, err := collector.LoadBudgets(*budgetsFile)
+		if err != nil {
+			logger.Error("Failed to load budgets.", "err", err)
+			os.Exit(1)
+		}
+		c.Budgets = budgets
+	}
+
 	if err := c.Validate(); err != nil {
 		logger.Error("Configuration is invalid.", "err", err)
 		os.Exit(1)
 	}
 
+	switch command {
+	case serveCmd.FullCommand():
+		runExporter(logger, c)
+	case chargebackCmd.FullCommand():
+		if err := writeChargebackReport(logger, c); err != nil {
+			logger.Error("Failed to write chargeback report.", "err", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// runExporter registers the collector and serves metrics until the HTTP server fails.
+func runExporter(logger *slog.Logger, c *collector.Config) {
 	// Add component prefix to logger for better log correlation
 	collectorLogger := logger.With("component", "databricks-exporter")
 	col := collector.NewCollector(collectorLogger, c)
@@ -104,13 +353,69 @@ func main() {
 	// Register collector with prometheus client library
 	prometheus.MustRegister(col)
 
-	serveMetrics(logger)
+	// Readiness reflects the last background check, so probes never trigger queries
+	go col.RunHealthChecks(context.Background(), c.HealthCheckInterval)
+
+	serveMetrics(logger, col)
+}
+
+// billingAttributionLimits returns the series limit of each enabled billing attribution key.
+func billingAttributionLimits() map[string]int {
+	limits := make(map[string]int)
+	for key, opt := range map[string]struct {
+		enabled bool
+		limit   int
+	}{
+		"job_id":          {*collectBillingByJobID, *billingJobIDLimit},
+		"warehouse_id":    {*collectBillingByWarehouseID, *billingWarehouseIDLimit},
+		"cluster_id":      {*collectBillingByClusterID, *billingClusterIDLimit},
+		"dlt_pipeline_id": {*collectBillingByDLTPipelineID, *billingDLTPipelineIDLimit},
+		"endpoint_name":   {*collectBillingByEndpointName, *billingEndpointNameLimit},
+	} {
+		if opt.enabled {
+			limits[key] = opt.limit
+		}
+	}
+	return limits
+}
+
+// writeChargebackReport queries the chargeback report selected by the report chargeback flags and
+// writes it to the output file, or standard output.
+func writeChargebackReport(logger *slog.Logger, c *collector.Config) error {
+	month := *chargebackMonth
+	if month == "" {
+		loc, err := time.LoadLocation(c.BillingTimezone)
+		if err != nil {
+			return err
+		}
+		month = collector.PreviousChargebackMonth(time.Now(), loc)
+	}
+
+	report, err := collector.GenerateChargebackReport(context.Background(), logger, c, month, *chargebackGroupBy)
+	if err != nil {
+		return err
+	}
+
+	if *chargebackOutput == "" {
+		return report.Write(os.Stdout, *chargebackFormat)
+	}
+	f, err := os.Create(*chargebackOutput)
+	if err != nil {
+		return err
+	}
+	if err := report.Write(f, *chargebackFormat); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func serveMetrics(logger *slog.Logger) {
+func serveMetrics(logger *slog.Logger, col *collector.Collector) {
 	landingPage := []byte(fmt.Sprintf(landingPageHTML, *metricPath))
 
 	http.Handle(*metricPath, promhttp.Handler())
+	http.Handle("/-/healthy", collector.HealthyHandler())
+	http.Handle("/-/ready", col.ReadyHandler())
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
 		if _, err := w.Write(landingPage); err != nil {
@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	// madScale converts a median absolute deviation into a standard deviation estimate for normal data.
+	madScale = 1.4826
+
+	// Floors on the spread that deviations are scored against, so a baseline with no variation
+	// or a SKU costing cents a day doesn't turn small changes into large scores.
+	anomalyMinRelativeSpread = 0.1 // Fraction of the expected cost
+	anomalyMinSpread         = 1.0 // Currency units
+
+	// anomalyMinHistory is how many baseline days must have usage before a cost is scored, so a
+	// new SKU or workspace isn't scored against a baseline of zeros.
+	anomalyMinHistory = 2
+)
+
+// costAnomaly is the daily cost of a workspace and SKU compared with its baseline.
+type costAnomaly struct {
+	observed float64
+	expected float64
+	score    float64
+}
+
+// scoreCostAnomaly compares observed daily cost with the same weekday in previous weeks.
+//
+// The expected cost is the median of history, and the score is the deviation from it in robust
+// standard deviations (scaled median absolute deviation), floored by anomalyMinRelativeSpread of
+// the expected cost and anomalyMinSpread. Weeks without usage must be included in history as 0.
+// It reports false when fewer than anomalyMinHistory baseline days (or all of them, for a shorter
+// baseline) had usage.
+func scoreCostAnomaly(observed float64, history []float64) (costAnomaly, bool) {
+	nonZero := 0
+	for _, cost := range history {
+		if cost != 0 {
+			nonZero++
+		}
+	}
+	if nonZero == 0 || nonZero < min(anomalyMinHistory, len(history)) {
+		return costAnomaly{}, false
+	}
+
+	expected := median(history)
+
+	deviations := make([]float64, len(history))
+	for i, cost := range history {
+		deviations[i] = math.Abs(cost - expected)
+	}
+	spread := max(madScale*median(deviations), anomalyMinRelativeSpread*expected, anomalyMinSpread)
+
+	return costAnomaly{
+		observed: observed,
+		expected: expected,
+		score:    (observed - expected) / spread,
+	}, true
+}
+
+// median returns the median of values, or 0 when there are none.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 0.0, median(nil))
+	assert.Equal(t, 3.0, median([]float64{5, 1, 3}))
+	assert.Equal(t, 2.5, median([]float64{4, 1, 3, 2}))
+
+	values := []float64{3, 1, 2}
+	median(values)
+	assert.Equal(t, []float64{3, 1, 2}, values, "median must not reorder its input")
+}
+
+func TestScoreCostAnomaly(t *testing.T) {
+	tests := []struct {
+		name      string
+		observed  float64
+		history   []float64
+		expected  float64
+		score     float64
+		notScored bool
+	}{
+		{
+			// Median 105, deviations 15, 5, 5, 15 -> MAD 10, spread 14.826
+			name:     "spike above a varying baseline",
+			observed: 200,
+			history:  []float64{90, 100, 110, 120},
+			expected: 105,
+			score:    (200 - 105) / (madScale * 10),
+		},
+		{
+			name:     "steady baseline uses the relative floor",
+			observed: 150,
+			history:  []float64{100, 100, 100, 100},
+			expected: 100,
+			score:    5,
+		},
+		{
+			name:     "spend below the baseline scores negative",
+			observed: 50,
+			history:  []float64{100, 100, 100, 100},
+			expected: 100,
+			score:    -5,
+		},
+		{
+			// A single outlier week doesn't move the median or the spread
+			name:     "outlier in the baseline",
+			observed: 100,
+			history:  []float64{100, 100, 100, 1000},
+			expected: 100,
+			score:    0,
+		},
+		{
+			// Median 0.5, MAD 0.5 -> spread 0.74, below the absolute floor
+			name:     "cheap SKU uses the absolute floor",
+			observed: 3,
+			history:  []float64{0, 0, 1, 1},
+			expected: 0.5,
+			score:    2.5,
+		},
+		{
+			name:      "new SKU without history is not scored",
+			observed:  3,
+			history:   []float64{0, 0, 0, 0},
+			notScored: true,
+		},
+		{
+			name:      "a single day of history is not scored",
+			observed:  300,
+			history:   []float64{0, 0, 0, 40},
+			notScored: true,
+		},
+		{
+			name:     "a one-week baseline needs its only day",
+			observed: 150,
+			history:  []float64{100},
+			expected: 100,
+			score:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomaly, ok := scoreCostAnomaly(tt.observed, tt.history)
+			if tt.notScored {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.observed, anomaly.observed)
+			assert.InDelta(t, tt.expected, anomaly.expected, 1e-9)
+			assert.InDelta(t, tt.score, anomaly.score, 1e-9)
+		})
+	}
+}
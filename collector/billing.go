@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,21 +16,23 @@ import (
 
 // BillingCollector collects billing and cost metrics from Databricks System Tables.
 type BillingCollector struct {
-	db      *sql.DB
+	router  *queryRouter // Resolves the warehouse for each query
 	metrics *MetricDescriptors
 	logger  *slog.Logger
 	ctx     context.Context
 	config  *Config
+	now     func() time.Time // For testing
 }
 
 // NewBillingCollector creates a new billing metrics collector.
-func NewBillingCollector(ctx context.Context, db *sql.DB, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *BillingCollector {
+func NewBillingCollector(ctx context.Context, router *queryRouter, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *BillingCollector {
 	return &BillingCollector{
 		logger:  logger,
-		db:      db,
+		router:  router,
 		metrics: metrics,
 		ctx:     ctx,
 		config:  config,
+		now:     time.Now,
 	}
 }
 
@@ -36,9 +40,35 @@ func NewBillingCollector(ctx context.Context, db *sql.DB, metrics *MetricDescrip
 func (c *BillingCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.metrics.BillingDBUs
 	ch <- c.metrics.BillingCostEstimateUSD
+	ch <- c.metrics.BillingCorrectionsDBUs
+	ch <- c.metrics.BillingCostEffective
 	ch <- c.metrics.PriceChangeEvents
 	ch <- c.metrics.BillingScrapeErrors
+	ch <- c.metrics.BudgetAmount
+	ch <- c.metrics.BudgetSpendMonthToDate
+	ch <- c.metrics.BudgetBurnRatio
+	ch <- c.metrics.BudgetProjectedMonthEnd
+	ch <- c.metrics.BillingCostObserved
+	ch <- c.metrics.BillingCostExpected
+	ch <- c.metrics.BillingCostAnomalyScore
+	ch <- c.metrics.BillingDBUsCalendar
+	ch <- c.metrics.BillingCostCalendar
+	ch <- c.metrics.BillingDBUsByProduct
+	ch <- c.metrics.BillingCostByProduct
+	ch <- c.metrics.BillingDBUsByCompute
+	ch <- c.metrics.BillingCostByCompute
+	ch <- c.metrics.BillingDBUsByIdentity
+	ch <- c.metrics.BillingCostByIdentity
+	ch <- c.metrics.BillingAttributedDBUs
+	ch <- c.metrics.BillingAttributedCost
+	ch <- c.metrics.WarehouseIdleDBUs
+	ch <- c.metrics.WarehouseIdleCost
+	if c.metrics.BillingDBUsByTag != nil {
+		ch <- c.metrics.BillingDBUsByTag
+		ch <- c.metrics.BillingCostByTag
+	}
 	ch <- c.metrics.ScrapeStatus
+	ch <- c.metrics.QueryScrapeDuration
 }
 
 // Collect retrieves and emits all billing metrics.
@@ -55,7 +85,7 @@ func (c *BillingCollector) Collect(ch chan<- prometheus.Metric) {
 		defer wg.Done()
 		if err := c.collectBillingDBUs(ch); err != nil {
 			c.logger.Error("Failed to collect billing DBUs", "err", err)
-			c.emitError(ch, "billing_dbus")
+			c.emitError(ch, queryBillingDBUs)
 			hasError.Store(true)
 		}
 	}()
@@ -64,7 +94,7 @@ func (c *BillingCollector) Collect(ch chan<- prometheus.Metric) {
 		defer wg.Done()
 		if err := c.collectBillingCost(ch); err != nil {
 			c.logger.Error("Failed to collect billing cost estimates", "err", err)
-			c.emitError(ch, "billing_cost")
+			c.emitError(ch, queryBillingCost)
 			hasError.Store(true)
 		}
 	}()
@@ -73,11 +103,141 @@ func (c *BillingCollector) Collect(ch chan<- prometheus.Metric) {
 		defer wg.Done()
 		if err := c.collectPriceChangeEvents(ch); err != nil {
 			c.logger.Error("Failed to collect price change events", "err", err)
-			c.emitError(ch, "price_changes")
+			c.emitError(ch, queryPriceChanges)
 			hasError.Store(true)
 		}
 	}()
 
+	// Billing origin product breakdown is opt-in
+	if c.config.CollectBillingByProduct {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingByProduct(ch); err != nil {
+				c.logger.Error("Failed to collect billing by product", "err", err)
+				c.emitError(ch, queryBillingByProduct)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Serverless and classic compute split is opt-in
+	if c.config.CollectBillingByCompute {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingByCompute(ch); err != nil {
+				c.logger.Error("Failed to collect billing by compute", "err", err)
+				c.emitError(ch, queryBillingByCompute)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Spend by identity is opt-in
+	if c.config.CollectBillingByIdentity {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingByIdentity(ch); err != nil {
+				c.logger.Error("Failed to collect billing by identity", "err", err)
+				c.emitError(ch, queryBillingByIdentity)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Attribution by usage_metadata is opt-in per key
+	if len(c.config.BillingAttributionLimits) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingAttribution(ch); err != nil {
+				c.logger.Error("Failed to collect billing attribution", "err", err)
+				c.emitError(ch, queryBillingAttribution)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Effective cost only runs when pricing overrides are configured
+	if len(c.config.PricingOverrides) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingCostEffective(ch); err != nil {
+				c.logger.Error("Failed to collect effective billing cost", "err", err)
+				c.emitError(ch, queryBillingCostEffective)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Calendar-aligned periods scan up to two months of usage, so they are opt-in
+	if c.config.CollectBillingCalendar {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingCalendar(ch); err != nil {
+				c.logger.Error("Failed to collect calendar billing", "err", err)
+				c.emitError(ch, queryBillingCalendar)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Cost anomaly detection is opt-in
+	if c.config.CollectBillingAnomaly {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingAnomaly(ch); err != nil {
+				c.logger.Error("Failed to collect billing cost anomalies", "err", err)
+				c.emitError(ch, queryBillingAnomaly)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Budgets only run when configured
+	if len(c.config.Budgets) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBudgets(ch); err != nil {
+				c.logger.Error("Failed to collect budgets", "err", err)
+				c.emitError(ch, queryBudgetSpend)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Cost allocation by custom tags only runs when tag keys are allowlisted
+	if len(c.config.BillingTagKeys) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectBillingByTag(ch); err != nil {
+				c.logger.Error("Failed to collect billing by tag", "err", err)
+				c.emitError(ch, queryBillingByTag)
+				hasError.Store(true)
+			}
+		}()
+	}
+
+	// Idle warehouse usage joins billing with query history, so it is opt-in
+	if c.config.CollectWarehouseIdle {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.collectWarehouseIdle(ch); err != nil {
+				c.logger.Error("Failed to collect idle warehouse usage", "err", err)
+				c.emitError(ch, queryWarehouseIdle)
+				hasError.Store(true)
+			}
+		}()
+	}
+
 	wg.Wait()
 
 	// Emit scrape status
@@ -85,12 +245,13 @@ func (c *BillingCollector) Collect(ch chan<- prometheus.Metric) {
 	if hasError.Load() {
 		status = 0.0
 	}
-	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, "billing")
+	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, collectorBilling)
 
 	c.logger.Debug("Finished collecting billing metrics", "duration_seconds", time.Since(start).Seconds())
 }
 
-// collectBillingDBUs retrieves total DBU consumption per workspace and SKU.
+// collectBillingDBUs retrieves total DBU consumption per workspace and SKU, and the part of it
+// that comes from retractions and restatements.
 func (c *BillingCollector) collectBillingDBUs(ch chan<- prometheus.Metric) error {
 	c.logger.Debug("Querying billing DBUs")
 
@@ -99,7 +260,7 @@ func (c *BillingCollector) collectBillingDBUs(ch chan<- prometheus.Metric) error
 		lookback = DefaultBillingLookback
 	}
 	query := BuildBillingDBUsQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryBillingDBUs, query)
 	if err != nil {
 		return fmt.Errorf("failed to query billing DBUs: %w", err)
 	}
@@ -107,10 +268,11 @@ func (c *BillingCollector) collectBillingDBUs(ch chan<- prometheus.Metric) error
 
 	count := 0
 	for rows.Next() {
-		var workspaceID, skuName sql.NullString
+		var workspaceID, skuName, usageUnit sql.NullString
 		var dbusTotal float64
+		var correctionsDBUs sql.NullFloat64
 
-		if err := rows.Scan(&workspaceID, &skuName, &dbusTotal); err != nil {
+		if err := rows.Scan(&workspaceID, &skuName, &usageUnit, &dbusTotal, &correctionsDBUs); err != nil {
 			c.logger.Error("Failed to scan billing DBUs row", "err", err)
 			continue
 		}
@@ -127,6 +289,15 @@ func (c *BillingCollector) collectBillingDBUs(ch chan<- prometheus.Metric) error
 			dbusTotal,
 			workspaceID.String,
 			skuName.String,
+			usageUnit.String,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.metrics.BillingCorrectionsDBUs,
+			prometheus.GaugeValue,
+			correctionsDBUs.Float64,
+			workspaceID.String,
+			skuName.String,
+			usageUnit.String,
 		)
 		count++
 	}
@@ -143,8 +314,8 @@ func (c *BillingCollector) collectBillingCost(ch chan<- prometheus.Metric) error
 	if lookback == 0 {
 		lookback = DefaultBillingLookback
 	}
-	query := BuildBillingCostEstimateQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	query := BuildBillingCostEstimateQuery(lookback, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingCost, query)
 	if err != nil {
 		return fmt.Errorf("failed to query billing cost: %w", err)
 	}
@@ -152,10 +323,10 @@ func (c *BillingCollector) collectBillingCost(ch chan<- prometheus.Metric) error
 
 	count := 0
 	for rows.Next() {
-		var workspaceID, skuName sql.NullString
+		var workspaceID, skuName, currencyCode sql.NullString
 		var costEstimateUSD float64
 
-		if err := rows.Scan(&workspaceID, &skuName, &costEstimateUSD); err != nil {
+		if err := rows.Scan(&workspaceID, &skuName, &currencyCode, &costEstimateUSD); err != nil {
 			c.logger.Error("Failed to scan billing cost row", "err", err)
 			continue
 		}
@@ -172,6 +343,7 @@ func (c *BillingCollector) collectBillingCost(ch chan<- prometheus.Metric) error
 			costEstimateUSD,
 			workspaceID.String,
 			skuName.String,
+			currencyCode.String,
 		)
 		count++
 	}
@@ -180,6 +352,271 @@ func (c *BillingCollector) collectBillingCost(ch chan<- prometheus.Metric) error
 	return rows.Err()
 }
 
+// collectBillingCostEffective retrieves cost after pricing overrides per workspace and SKU.
+// Overrides are applied per usage date, so daily rows are summed here rather than in SQL.
+func (c *BillingCollector) collectBillingCostEffective(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying effective billing cost")
+
+	book, err := newPriceBook(c.config.PricingOverrides)
+	if err != nil {
+		return fmt.Errorf("invalid pricing overrides: %w", err)
+	}
+
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildBillingEffectiveCostQuery(lookback, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingCostEffective, query)
+	if err != nil {
+		return fmt.Errorf("failed to query effective billing cost: %w", err)
+	}
+	defer rows.Close()
+
+	costs := newGaugeSums(c.metrics.BillingCostEffective)
+	for rows.Next() {
+		var workspaceID, skuName, product, currencyCode sql.NullString
+		var usageDate sql.NullTime
+		var dbusTotal, costEstimateUSD float64
+
+		if err := rows.Scan(&workspaceID, &skuName, &product, &usageDate, &currencyCode, &dbusTotal, &costEstimateUSD); err != nil {
+			c.logger.Error("Failed to scan effective billing cost row", "err", err)
+			continue
+		}
+
+		// Skip rows with NULL workspace_id, sku_name or usage_date (invalid data)
+		if !workspaceID.Valid || !skuName.Valid || !usageDate.Valid {
+			c.logger.Debug("Skipping effective billing cost row with NULL workspace_id, sku_name or usage_date")
+			continue
+		}
+
+		// Override dates are calendar dates, so compare on the UTC date of usage_date
+		y, m, d := usageDate.Time.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+		cost, unitPriced := book.effectiveCost(skuName.String, product.String, date, dbusTotal, costEstimateUSD)
+		currency := currencyCode.String
+		if unitPriced {
+			currency = c.config.reportingCurrency()
+		}
+		costs.add(cost, workspaceID.String, skuName.String, currency)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	costs.emit(ch)
+	c.logger.Debug("Collected effective billing cost", "count", len(costs.keys))
+	return nil
+}
+
+// collectBillingCalendar retrieves DBUs and cost estimates per workspace and SKU for
+// today, yesterday, month-to-date and the previous month.
+func (c *BillingCollector) collectBillingCalendar(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying calendar billing")
+
+	loc, err := c.config.billingLocation()
+	if err != nil {
+		return fmt.Errorf("invalid billing timezone: %w", err)
+	}
+	query := BuildBillingCalendarQuery(c.now(), loc, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingCalendar, query)
+	if err != nil {
+		return fmt.Errorf("failed to query calendar billing: %w", err)
+	}
+	defer rows.Close()
+
+	dbuSums := newGaugeSums(c.metrics.BillingDBUsCalendar)
+	costSums := newGaugeSums(c.metrics.BillingCostCalendar)
+	count := 0
+	for rows.Next() {
+		var workspaceID, skuName, usageUnit, currencyCode sql.NullString
+		// One DBU and one cost column per calendar period, in calendarPeriods order
+		dbus := make([]sql.NullFloat64, len(calendarPeriods))
+		costs := make([]sql.NullFloat64, len(calendarPeriods))
+		dest := []any{&workspaceID, &skuName, &usageUnit, &currencyCode}
+		for i := range dbus {
+			dest = append(dest, &dbus[i])
+		}
+		for i := range costs {
+			dest = append(dest, &costs[i])
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			c.logger.Error("Failed to scan calendar billing row", "err", err)
+			continue
+		}
+
+		// Skip rows with NULL workspace_id or sku_name (invalid data)
+		if !workspaceID.Valid || !skuName.Valid {
+			c.logger.Debug("Skipping calendar billing row with NULL workspace_id or sku_name")
+			continue
+		}
+
+		for i, period := range calendarPeriods {
+			dbuSums.add(dbus[i].Float64, workspaceID.String, skuName.String, period, usageUnit.String)
+			costSums.add(costs[i].Float64, workspaceID.String, skuName.String, period, currencyCode.String)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dbuSums.emit(ch)
+	costSums.emit(ch)
+	c.logger.Debug("Collected calendar billing", "count", count)
+	return nil
+}
+
+// collectBudgets retrieves the spend of each budget and emits its month-to-date spend,
+// burn ratio and projected month-end spend.
+func (c *BillingCollector) collectBudgets(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying budget spend")
+
+	loc, err := c.config.billingLocation()
+	if err != nil {
+		return fmt.Errorf("invalid billing timezone: %w", err)
+	}
+	now := c.now()
+	query := BuildBudgetSpendQuery(now, loc, c.config.Budgets, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBudgetSpend, query)
+	if err != nil {
+		return fmt.Errorf("failed to query budget spend: %w", err)
+	}
+	defer rows.Close()
+
+	// Row layout: hour_start, currency_code, one cost column per budget. Hours are bucketed into
+	// days in loc. Budget amounts are in the reporting currency, so cost in any other currency
+	// (a list price without an FX rate) is left out of the spend and logged.
+	currency := c.config.reportingCurrency()
+	daily := make([]map[string]float64, len(c.config.Budgets))
+	for i := range daily {
+		daily[i] = make(map[string]float64)
+	}
+	unconverted := make(map[string]float64)
+	var hourStart sql.NullInt64
+	var currencyCode sql.NullString
+	costs := make([]sql.NullFloat64, len(c.config.Budgets))
+	dest := []any{&hourStart, &currencyCode}
+	for i := range costs {
+		dest = append(dest, &costs[i])
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			c.logger.Error("Failed to scan budget spend row", "err", err)
+			continue
+		}
+		if !hourStart.Valid {
+			c.logger.Debug("Skipping budget spend row with NULL hour_start")
+			continue
+		}
+
+		if currencyCode.String != currency {
+			for _, cost := range costs {
+				unconverted[currencyCode.String] += cost.Float64
+			}
+			continue
+		}
+
+		day := time.Unix(hourStart.Int64, 0).In(loc).Format(dateLayout)
+		for i, cost := range costs {
+			daily[i][day] += cost.Float64
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for code, cost := range unconverted {
+		if cost != 0 {
+			c.logger.Warn("Budget spend excludes cost in a currency without an FX rate",
+				"currency_code", code, "reporting_currency", currency, "cost", cost)
+		}
+	}
+
+	for i, budget := range c.config.Budgets {
+		forecast := forecastMonthEnd(daily[i], now, loc)
+		ch <- prometheus.MustNewConstMetric(c.metrics.BudgetAmount, prometheus.GaugeValue, budget.Amount, budget.Name, currency)
+		ch <- prometheus.MustNewConstMetric(c.metrics.BudgetSpendMonthToDate, prometheus.GaugeValue, forecast.monthToDate, budget.Name, currency)
+		ch <- prometheus.MustNewConstMetric(c.metrics.BudgetBurnRatio, prometheus.GaugeValue, forecast.monthToDate/budget.Amount, budget.Name)
+		ch <- prometheus.MustNewConstMetric(c.metrics.BudgetProjectedMonthEnd, prometheus.GaugeValue, forecast.linear, budget.Name, forecastLinear, currency)
+		ch <- prometheus.MustNewConstMetric(c.metrics.BudgetProjectedMonthEnd, prometheus.GaugeValue, forecast.weekday, budget.Name, forecastWeekday, currency)
+	}
+
+	c.logger.Debug("Collected budgets", "count", len(c.config.Budgets))
+	return nil
+}
+
+// collectBillingAnomaly scores the cost per workspace and SKU on the day BillingAnomalyOffset days
+// before today against the same weekday in previous weeks. Series without enough history are
+// not emitted.
+func (c *BillingCollector) collectBillingAnomaly(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying billing cost anomalies")
+
+	loc, err := c.config.billingLocation()
+	if err != nil {
+		return fmt.Errorf("invalid billing timezone: %w", err)
+	}
+	weeks := c.config.BillingAnomalyWeeks
+	if weeks == 0 {
+		weeks = DefaultBillingAnomalyWeeks
+	}
+	query := BuildBillingAnomalyQuery(c.now(), loc, weeks, c.config.BillingAnomalyOffset, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingAnomaly, query)
+	if err != nil {
+		return fmt.Errorf("failed to query billing cost anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	// Daily cost per series, indexed by weeks_ago; days without a row stay 0
+	type seriesKey struct{ workspaceID, skuName, currencyCode string }
+	var keys []seriesKey
+	costs := make(map[seriesKey][]float64)
+	for rows.Next() {
+		var workspaceID, skuName, currencyCode sql.NullString
+		var weeksAgo sql.NullInt64
+		var cost sql.NullFloat64
+
+		if err := rows.Scan(&workspaceID, &skuName, &currencyCode, &weeksAgo, &cost); err != nil {
+			c.logger.Error("Failed to scan billing cost anomaly row", "err", err)
+			continue
+		}
+
+		// Skip rows with NULL workspace_id or sku_name, or outside the queried days (invalid data)
+		if !workspaceID.Valid || !skuName.Valid || !weeksAgo.Valid || weeksAgo.Int64 < 0 || weeksAgo.Int64 > int64(weeks) {
+			c.logger.Debug("Skipping billing cost anomaly row with NULL workspace_id or sku_name, or invalid weeks_ago")
+			continue
+		}
+
+		key := seriesKey{workspaceID.String, skuName.String, currencyCode.String}
+		if costs[key] == nil {
+			costs[key] = make([]float64, weeks+1)
+			keys = append(keys, key)
+		}
+		costs[key][weeksAgo.Int64] += cost.Float64
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	scored := 0
+	for _, key := range keys {
+		anomaly, ok := scoreCostAnomaly(costs[key][0], costs[key][1:])
+		if !ok {
+			continue
+		}
+		scored++
+		labels := []string{key.workspaceID, key.skuName, key.currencyCode}
+		ch <- prometheus.MustNewConstMetric(c.metrics.BillingCostObserved, prometheus.GaugeValue, anomaly.observed, labels...)
+		ch <- prometheus.MustNewConstMetric(c.metrics.BillingCostExpected, prometheus.GaugeValue, anomaly.expected, labels...)
+		ch <- prometheus.MustNewConstMetric(c.metrics.BillingCostAnomalyScore, prometheus.GaugeValue, anomaly.score, labels...)
+	}
+
+	c.logger.Debug("Collected billing cost anomalies", "count", scored, "skipped", len(keys)-scored)
+	return nil
+}
+
 // collectPriceChangeEvents tracks price changes from the list_prices table.
 func (c *BillingCollector) collectPriceChangeEvents(ch chan<- prometheus.Metric) error {
 	c.logger.Debug("Querying price change events")
@@ -189,7 +626,7 @@ func (c *BillingCollector) collectPriceChangeEvents(ch chan<- prometheus.Metric)
 		lookback = DefaultBillingLookback
 	}
 	query := BuildPriceChangeEventsQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryPriceChanges, query)
 	if err != nil {
 		return fmt.Errorf("failed to query price changes: %w", err)
 	}
@@ -224,6 +661,305 @@ func (c *BillingCollector) collectPriceChangeEvents(ch chan<- prometheus.Metric)
 	return rows.Err()
 }
 
+// collectBillingByProduct retrieves DBUs and cost estimates per workspace and billing origin product.
+func (c *BillingCollector) collectBillingByProduct(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying billing by product")
+
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildBillingByProductQuery(lookback, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingByProduct, query)
+	if err != nil {
+		return fmt.Errorf("failed to query billing by product: %w", err)
+	}
+	defer rows.Close()
+
+	dbuSums := newGaugeSums(c.metrics.BillingDBUsByProduct)
+	costSums := newGaugeSums(c.metrics.BillingCostByProduct)
+	count := 0
+	for rows.Next() {
+		var workspaceID, product, usageUnit, currencyCode sql.NullString
+		var dbusTotal, costEstimateUSD float64
+
+		if err := rows.Scan(&workspaceID, &product, &usageUnit, &currencyCode, &dbusTotal, &costEstimateUSD); err != nil {
+			c.logger.Error("Failed to scan billing by product row", "err", err)
+			continue
+		}
+
+		// Skip rows with NULL workspace_id or billing_origin_product (invalid data)
+		if !workspaceID.Valid || !product.Valid {
+			c.logger.Debug("Skipping billing by product row with NULL workspace_id or billing_origin_product")
+			continue
+		}
+
+		dbuSums.add(dbusTotal, workspaceID.String, product.String, usageUnit.String)
+		costSums.add(costEstimateUSD, workspaceID.String, product.String, currencyCode.String)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dbuSums.emit(ch)
+	costSums.emit(ch)
+	c.logger.Debug("Collected billing by product", "count", count)
+	return nil
+}
+
+// collectBillingByCompute retrieves DBUs and cost estimates per workspace and billing origin
+// product, split by serverless or classic compute and Photon use.
+func (c *BillingCollector) collectBillingByCompute(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying billing by compute")
+
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildBillingByComputeQuery(lookback, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingByCompute, query)
+	if err != nil {
+		return fmt.Errorf("failed to query billing by compute: %w", err)
+	}
+	defer rows.Close()
+
+	dbuSums := newGaugeSums(c.metrics.BillingDBUsByCompute)
+	costSums := newGaugeSums(c.metrics.BillingCostByCompute)
+	count := 0
+	for rows.Next() {
+		var workspaceID, product, computeType, photon, usageUnit, currencyCode sql.NullString
+		var dbusTotal, costEstimateUSD float64
+
+		if err := rows.Scan(&workspaceID, &product, &computeType, &photon, &usageUnit, &currencyCode, &dbusTotal, &costEstimateUSD); err != nil {
+			c.logger.Error("Failed to scan billing by compute row", "err", err)
+			continue
+		}
+
+		// Skip rows with NULL workspace_id or billing_origin_product (invalid data)
+		if !workspaceID.Valid || !product.Valid {
+			c.logger.Debug("Skipping billing by compute row with NULL workspace_id or billing_origin_product")
+			continue
+		}
+
+		dbuSums.add(dbusTotal, workspaceID.String, product.String, computeType.String, photon.String, usageUnit.String)
+		costSums.add(costEstimateUSD, workspaceID.String, product.String, computeType.String, photon.String, currencyCode.String)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dbuSums.emit(ch)
+	costSums.emit(ch)
+	c.logger.Debug("Collected billing by compute", "count", count)
+	return nil
+}
+
+// collectBillingByIdentity retrieves DBUs and cost estimates per workspace for the identities
+// with the highest cost. Cardinality is capped per workspace in SQL (see BuildBillingByIdentityQuery).
+func (c *BillingCollector) collectBillingByIdentity(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying billing by identity")
+
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildBillingByIdentityQuery(lookback, c.config.BillingIdentityLimit, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingByIdentity, query)
+	if err != nil {
+		return fmt.Errorf("failed to query billing by identity: %w", err)
+	}
+	defer rows.Close()
+
+	dbuSums := newGaugeSums(c.metrics.BillingDBUsByIdentity)
+	costSums := newGaugeSums(c.metrics.BillingCostByIdentity)
+	count := 0
+	for rows.Next() {
+		var workspaceID, identity, usageUnit, currencyCode sql.NullString
+		var dbusTotal, costEstimateUSD float64
+
+		if err := rows.Scan(&workspaceID, &identity, &usageUnit, &currencyCode, &dbusTotal, &costEstimateUSD); err != nil {
+			c.logger.Error("Failed to scan billing by identity row", "err", err)
+			continue
+		}
+
+		// Skip rows with NULL workspace_id or identity (invalid data)
+		if !workspaceID.Valid || !identity.Valid {
+			c.logger.Debug("Skipping billing by identity row with NULL workspace_id or identity")
+			continue
+		}
+
+		dbuSums.add(dbusTotal, workspaceID.String, identity.String, usageUnit.String)
+		costSums.add(costEstimateUSD, workspaceID.String, identity.String, currencyCode.String)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dbuSums.emit(ch)
+	costSums.emit(ch)
+	c.logger.Debug("Collected billing by identity", "count", count)
+	return nil
+}
+
+// collectBillingAttribution retrieves DBUs and cost estimates per workspace for each enabled
+// usage_metadata key. Cardinality is capped per key in SQL (see BuildBillingAttributionQuery).
+func (c *BillingCollector) collectBillingAttribution(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying billing attribution")
+
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildBillingAttributionQuery(lookback, c.config.BillingAttributionLimits, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingAttribution, query)
+	if err != nil {
+		return fmt.Errorf("failed to query billing attribution: %w", err)
+	}
+	defer rows.Close()
+
+	dbuSums := newGaugeSums(c.metrics.BillingAttributedDBUs)
+	costSums := newGaugeSums(c.metrics.BillingAttributedCost)
+	count := 0
+	for rows.Next() {
+		var key, workspaceID, value, usageUnit, currencyCode sql.NullString
+		var dbusTotal, costEstimateUSD float64
+
+		if err := rows.Scan(&key, &workspaceID, &value, &usageUnit, &currencyCode, &dbusTotal, &costEstimateUSD); err != nil {
+			c.logger.Error("Failed to scan billing attribution row", "err", err)
+			continue
+		}
+
+		// Skip rows with NULL labels (invalid data)
+		if !key.Valid || !workspaceID.Valid || !value.Valid {
+			c.logger.Debug("Skipping billing attribution row with NULL attribution_key, workspace_id or attribution_value")
+			continue
+		}
+
+		dbuSums.add(dbusTotal, workspaceID.String, key.String, value.String, usageUnit.String)
+		costSums.add(costEstimateUSD, workspaceID.String, key.String, value.String, currencyCode.String)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dbuSums.emit(ch)
+	costSums.emit(ch)
+	c.logger.Debug("Collected billing attribution", "count", count)
+	return nil
+}
+
+// collectBillingByTag retrieves DBUs and cost estimates per workspace and allowlisted custom tag values.
+func (c *BillingCollector) collectBillingByTag(ch chan<- prometheus.Metric) error {
+	c.logger.Debug("Querying billing by tag")
+
+	// Descriptors are created from the tag keys in NewCollector
+	if c.metrics.BillingDBUsByTag == nil {
+		return fmt.Errorf("billing tag metrics are not initialized")
+	}
+
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	placeholder := c.config.BillingTagPlaceholder
+	if placeholder == "" {
+		placeholder = DefaultBillingTagPlaceholder
+	}
+	query := BuildBillingByTagQuery(lookback, c.config.BillingTagKeys, placeholder, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryBillingByTag, query)
+	if err != nil {
+		return fmt.Errorf("failed to query billing by tag: %w", err)
+	}
+	defer rows.Close()
+
+	// Row layout: workspace_id, one column per tag key, usage_unit, currency_code, dbus_total, cost_estimate_usd
+	labels := make([]sql.NullString, 1+len(c.config.BillingTagKeys))
+	var usageUnit, currencyCode sql.NullString
+	var dbusTotal, costEstimateUSD float64
+	dest := make([]any, 0, len(labels)+4)
+	for i := range labels {
+		dest = append(dest, &labels[i])
+	}
+	dest = append(dest, &usageUnit, &currencyCode, &dbusTotal, &costEstimateUSD)
+
+	dbuSums := newGaugeSums(c.metrics.BillingDBUsByTag)
+	costSums := newGaugeSums(c.metrics.BillingCostByTag)
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			c.logger.Error("Failed to scan billing by tag row", "err", err)
+			continue
+		}
+
+		// Tag columns are never NULL (placeholder), so only workspace_id can be invalid
+		if !labels[0].Valid {
+			c.logger.Debug("Skipping billing by tag row with NULL workspace_id")
+			continue
+		}
+
+		values := make([]string, len(labels))
+		for i, label := range labels {
+			values[i] = label.String
+		}
+
+		dbuSums.add(dbusTotal, append(slices.Clone(values), usageUnit.String)...)
+		costSums.add(costEstimateUSD, append(values, currencyCode.String)...)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dbuSums.emit(ch)
+	costSums.emit(ch)
+	c.logger.Debug("Collected billing by tag", "count", count)
+	return nil
+}
+
+// collectWarehouseIdle collects the DBUs and cost of SQL warehouse usage with no query activity
+// over the billing window. It joins billing usage with query history, so it runs with the billing
+// queries rather than on every warehouse query scrape.
+func (c *BillingCollector) collectWarehouseIdle(ch chan<- prometheus.Metric) error {
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	c.logger.Debug("Querying idle warehouse usage")
+
+	query := BuildWarehouseIdleQuery(lookback, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryWarehouseIdle, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute idle warehouse query: %w", err)
+	}
+	defer rows.Close()
+
+	dbuSums := newGaugeSums(c.metrics.WarehouseIdleDBUs)
+	costSums := newGaugeSums(c.metrics.WarehouseIdleCost)
+	for rows.Next() {
+		var workspaceID, warehouseID, usageUnit, currencyCode sql.NullString
+		var idleDBUs, idleCost sql.NullFloat64
+
+		if err := rows.Scan(&workspaceID, &warehouseID, &usageUnit, &currencyCode, &idleDBUs, &idleCost); err != nil {
+			return fmt.Errorf("failed to scan idle warehouse row: %w", err)
+		}
+
+		dbuSums.add(idleDBUs.Float64, workspaceID.String, warehouseID.String, usageUnit.String)
+		costSums.add(idleCost.Float64, workspaceID.String, warehouseID.String, currencyCode.String)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	dbuSums.emit(ch)
+	costSums.emit(ch)
+	c.logger.Debug("Collected idle warehouse usage", "count", len(dbuSums.values))
+	return nil
+}
+
 // emitError emits a billing scrape error metric for the given stage.
 func (c *BillingCollector) emitError(ch chan<- prometheus.Metric, stage string) {
 	ch <- prometheus.MustNewConstMetric(
@@ -233,3 +969,38 @@ func (c *BillingCollector) emitError(ch chan<- prometheus.Metric, stage string)
 		stage,
 	)
 }
+
+// gaugeSums sums gauge values per label set before emitting them. Query rows split by
+// usage_unit and currency_code repeat the labels of a DBU metric once per currency and of a
+// cost metric once per unit, and a metric must not be emitted twice with the same labels.
+type gaugeSums struct {
+	desc   *prometheus.Desc
+	keys   []string // Label sets in first-seen order
+	labels map[string][]string
+	values map[string]float64
+}
+
+func newGaugeSums(desc *prometheus.Desc) *gaugeSums {
+	return &gaugeSums{
+		desc:   desc,
+		labels: make(map[string][]string),
+		values: make(map[string]float64),
+	}
+}
+
+// add adds value to the gauge with the given label values.
+func (s *gaugeSums) add(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+		s.labels[key] = labelValues
+	}
+	s.values[key] += value
+}
+
+// emit sends one gauge per label set.
+func (s *gaugeSums) emit(ch chan<- prometheus.Metric) {
+	for _, key := range s.keys {
+		ch <- prometheus.MustNewConstMetric(s.desc, prometheus.GaugeValue, s.values[key], s.labels[key]...)
+	}
+}
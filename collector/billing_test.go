@@ -3,7 +3,9 @@ package collector
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/prometheus/client_golang/prometheus"
@@ -21,10 +23,10 @@ func TestBillingCollector_Initialization(t *testing.T) {
 	metrics := NewMetricDescriptors()
 	logger := promslog.NewNopLogger()
 
-	collector := NewBillingCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	require.NotNil(t, collector, "NewBillingCollector returned nil")
-	assert.Equal(t, db, collector.db, "db not set correctly")
+	assert.Equal(t, db, collector.router.fallback.db, "db not set correctly")
 	assert.Equal(t, metrics, collector.metrics, "metrics not set correctly")
 }
 
@@ -34,17 +36,17 @@ func TestBillingCollector_CollectBillingDBUs(t *testing.T) {
 	defer db.Close()
 
 	// Set up mock expectations
-	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "dbus_total"}).
-		AddRow("87654321", "STANDARD_ALL_PURPOSE_COMPUTE", 125.5).
-		AddRow("87654321", "PREMIUM_JOBS_COMPUTE", 450.25).
-		AddRow("87654322", "STANDARD_ALL_PURPOSE_COMPUTE", 89.75)
+	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "usage_unit", "dbus_total", "corrections_dbus"}).
+		AddRow("87654321", "STANDARD_ALL_PURPOSE_COMPUTE", "DBU", 125.5, 0.0).
+		AddRow("87654321", "PREMIUM_JOBS_COMPUTE", "DBU", 450.25, -12.5).
+		AddRow("87654322", "STANDARD_ALL_PURPOSE_COMPUTE", "DBU", 89.75, nil)
 
 	mock.ExpectQuery("SELECT (.+) FROM system.billing.usage").
 		WillReturnRows(rows)
 
 	metrics := NewMetricDescriptors()
 	logger := promslog.NewNopLogger()
-	collector := NewBillingCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Collect metrics
 	ch := make(chan prometheus.Metric, 10)
@@ -55,13 +57,22 @@ func TestBillingCollector_CollectBillingDBUs(t *testing.T) {
 
 	// Verify metrics
 	count := 0
+	corrections := make(map[string]float64)
 	for m := range ch {
-		count++
-
 		// Extract metric details
 		pb := &dto.Metric{}
 		require.NoError(t, m.Write(pb), "failed to write metric")
 
+		if m.Desc() == metrics.BillingCorrectionsDBUs {
+			labels := make(map[string]string)
+			for _, lp := range pb.Label {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			corrections[labels[labelWorkspaceID]+"/"+labels[labelSKUName]] = pb.Gauge.GetValue()
+			continue
+		}
+		count++
+
 		// Verify it's a gauge
 		require.NotNil(t, pb.Gauge, "expected gauge metric")
 
@@ -73,6 +84,7 @@ func TestBillingCollector_CollectBillingDBUs(t *testing.T) {
 
 		assert.Contains(t, labels, "workspace_id", "missing workspace_id label")
 		assert.Contains(t, labels, "sku_name", "missing sku_name label")
+		assert.Equal(t, "DBU", labels["usage_unit"], "missing usage_unit label")
 
 		// Verify value
 		assert.Greater(t, pb.Gauge.GetValue(), float64(0), "expected positive value")
@@ -80,6 +92,13 @@ func TestBillingCollector_CollectBillingDBUs(t *testing.T) {
 
 	assert.Equal(t, 3, count, "expected 3 metrics")
 
+	// Corrections are emitted per row, with NULL treated as no corrections
+	assert.Equal(t, map[string]float64{
+		"87654321/PREMIUM_JOBS_COMPUTE":         -12.5,
+		"87654321/STANDARD_ALL_PURPOSE_COMPUTE": 0,
+		"87654322/STANDARD_ALL_PURPOSE_COMPUTE": 0,
+	}, corrections)
+
 	// Verify all expectations were met
 	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
 }
@@ -92,16 +111,16 @@ func TestBillingCollector_CollectBillingCost(t *testing.T) {
 	defer db.Close()
 
 	// Set up mock expectations
-	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "cost_estimate_usd"}).
-		AddRow("87654321", "STANDARD_ALL_PURPOSE_COMPUTE", 69.025).
-		AddRow("87654321", "PREMIUM_JOBS_COMPUTE", 337.6875)
+	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "currency_code", "cost_estimate_usd"}).
+		AddRow("87654321", "STANDARD_ALL_PURPOSE_COMPUTE", "USD", 69.025).
+		AddRow("87654321", "PREMIUM_JOBS_COMPUTE", "USD", 337.6875)
 
 	mock.ExpectQuery("SELECT (.+) FROM system.billing.usage u").
 		WillReturnRows(rows)
 
 	metrics := NewMetricDescriptors()
 	logger := promslog.NewNopLogger()
-	collector := NewBillingCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Collect metrics
 	ch := make(chan prometheus.Metric, 10)
@@ -159,7 +178,7 @@ func TestBillingCollector_CollectPriceChangeEvents(t *testing.T) {
 
 	metrics := NewMetricDescriptors()
 	logger := promslog.NewNopLogger()
-	collector := NewBillingCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Collect metrics
 	ch := make(chan prometheus.Metric, 10)
@@ -217,7 +236,7 @@ func TestBillingCollector_CollectWithError(t *testing.T) {
 
 	metrics := NewMetricDescriptors()
 	logger := promslog.NewNopLogger()
-	collector := NewBillingCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Collect metrics
 	ch := make(chan prometheus.Metric, 10)
@@ -244,7 +263,7 @@ func TestBillingCollector_CollectEmitsErrorMetric(t *testing.T) {
 
 	metrics := NewMetricDescriptors()
 	logger := promslog.NewNopLogger()
-	collector := NewBillingCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Test error emission
 	ch := make(chan prometheus.Metric, 1)
@@ -301,8 +320,8 @@ func TestBillingCollector_CollectContinuesOnPartialFailure(t *testing.T) {
 		WillReturnError(sql.ErrConnDone)
 
 	// Second query succeeds
-	costRows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "cost_estimate_usd"}).
-		AddRow("87654321", "STANDARD_ALL_PURPOSE_COMPUTE", 69.025)
+	costRows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "currency_code", "cost_estimate_usd"}).
+		AddRow("87654321", "STANDARD_ALL_PURPOSE_COMPUTE", "USD", 69.025)
 	mock.ExpectQuery("SELECT (.+) FROM system.billing.usage u").
 		WillReturnRows(costRows)
 
@@ -314,7 +333,7 @@ func TestBillingCollector_CollectContinuesOnPartialFailure(t *testing.T) {
 
 	metrics := NewMetricDescriptors()
 	logger := promslog.NewNopLogger()
-	collector := NewBillingCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Collect all metrics - should continue despite first failure
 	ch := make(chan prometheus.Metric, 20)
@@ -352,3 +371,567 @@ func TestBillingCollector_CollectContinuesOnPartialFailure(t *testing.T) {
 		t.Error("expected at least one error metric")
 	}
 }
+
+func TestBillingCollector_Collect_ByProductOptIn(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err, "failed to create mock db")
+
+		mock.MatchExpectationsInOrder(false)
+		// Unordered expectations match the first one that fits, so the specific one goes first
+		if enabled {
+			mock.ExpectQuery("GROUP BY u.workspace_id, u.billing_origin_product").WillReturnRows(sqlmock.NewRows([]string{"unused"}))
+		}
+		for range 3 {
+			mock.ExpectQuery("system.billing").WillReturnRows(sqlmock.NewRows([]string{"unused"}))
+		}
+
+		config := DefaultConfig()
+		config.CollectBillingByProduct = enabled
+		collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+
+		ch := make(chan prometheus.Metric, 20)
+		collector.Collect(ch)
+		close(ch)
+
+		// An unexpected query fails and reports a scrape error
+		for m := range ch {
+			assert.NotEqual(t, collector.metrics.BillingScrapeErrors, m.Desc(), "by-product query enabled=%v", enabled)
+		}
+		require.NoError(t, mock.ExpectationsWereMet(), "by-product query enabled=%v", enabled)
+		db.Close()
+	}
+}
+
+func TestBillingCollector_CollectBillingByProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "billing_origin_product", "usage_unit", "currency_code", "dbus_total", "cost_estimate_usd"}).
+		AddRow("87654321", "JOBS", "DBU", "USD", 450.25, 67.5).
+		AddRow("87654321", "SQL", "DBU", "USD", 120.0, 84.0).
+		AddRow("87654321", "SQL", "GIGABYTE", "USD", 40.0, 2.0).
+		AddRow("87654321", nil, "DBU", "USD", 5.0, 1.0)
+
+	mock.ExpectQuery("SELECT (.+) FROM system.billing.usage u").
+		WillReturnRows(rows)
+
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), DefaultConfig(), promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 10)
+	err = collector.collectBillingByProduct(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingByProduct failed")
+
+	series := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		key := labels[labelBillingOriginProduct] + "/" + labels[labelUsageUnit] + labels[labelCurrencyCode]
+		series[key] = pb.Gauge.GetValue()
+	}
+
+	// DBUs are split by usage unit and cost is summed across units; the NULL product row is skipped
+	assert.Equal(t, map[string]float64{
+		"JOBS/DBU":     450.25,
+		"JOBS/USD":     67.5,
+		"SQL/DBU":      120.0,
+		"SQL/GIGABYTE": 40.0,
+		"SQL/USD":      86.0,
+	}, series)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingByCompute(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "billing_origin_product", "compute_type", "photon", "usage_unit", "currency_code", "dbus_total", "cost_estimate_usd"}).
+		AddRow("87654321", "JOBS", "classic", "true", "DBU", "USD", 300.0, 45.0).
+		AddRow("87654321", "JOBS", "serverless", "true", "DBU", "USD", 150.0, 52.5).
+		AddRow("87654321", "SQL", "serverless", "true", "DBU", "USD", 120.0, 84.0).
+		AddRow("87654321", "SQL", "serverless", "true", "GIGABYTE", "USD", 40.0, 2.0).
+		AddRow(nil, "SQL", "classic", "false", "DBU", "USD", 5.0, 1.0)
+
+	mock.ExpectQuery("SELECT (.+) as compute_type(.+) FROM system.billing.usage u").
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.CollectBillingByCompute = true
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 20)
+	err = collector.collectBillingByCompute(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingByCompute failed")
+
+	dbus := make(map[string]float64)
+	cost := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		key := labels[labelBillingOriginProduct] + "/" + labels[labelComputeType] + "/" + labels[labelPhoton]
+		switch m.Desc() {
+		case collector.metrics.BillingDBUsByCompute:
+			dbus[key+"/"+labels[labelUsageUnit]] = pb.Gauge.GetValue()
+		case collector.metrics.BillingCostByCompute:
+			cost[key] = pb.Gauge.GetValue()
+		}
+	}
+
+	// Cost is summed across usage units; the NULL workspace row is skipped
+	assert.Equal(t, map[string]float64{
+		"JOBS/classic/true/DBU":        300.0,
+		"JOBS/serverless/true/DBU":     150.0,
+		"SQL/serverless/true/DBU":      120.0,
+		"SQL/serverless/true/GIGABYTE": 40.0,
+	}, dbus)
+	assert.Equal(t, map[string]float64{
+		"JOBS/classic/true":    45.0,
+		"JOBS/serverless/true": 52.5,
+		"SQL/serverless/true":  86.0,
+	}, cost)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingByIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "identity", "usage_unit", "currency_code", "dbus_total", "cost_estimate_usd"}).
+		AddRow("87654321", "etl@example.com", "DBU", "USD", 300.0, 45.0).
+		AddRow("87654321", "__other__", "DBU", "USD", 50.0, 7.5).
+		AddRow("87654321", "__other__", "GIGABYTE", "USD", 10.0, 0.5).
+		AddRow("12345678", "1b7a3c2e-sp", "DBU", "USD", 120.0, 84.0).
+		AddRow("12345678", nil, "DBU", "USD", 5.0, 1.0)
+
+	mock.ExpectQuery("SELECT (.+) FROM identity_usage").
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.CollectBillingByIdentity = true
+	config.BillingIdentityLimit = 1
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 20)
+	err = collector.collectBillingByIdentity(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingByIdentity failed")
+
+	dbus := make(map[string]float64)
+	cost := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		key := labels[labelWorkspaceID] + "/" + labels[labelIdentity]
+		switch m.Desc() {
+		case collector.metrics.BillingDBUsByIdentity:
+			dbus[key+"/"+labels[labelUsageUnit]] = pb.Gauge.GetValue()
+		case collector.metrics.BillingCostByIdentity:
+			cost[key] = pb.Gauge.GetValue()
+		}
+	}
+
+	// Cost is summed across usage units; the NULL identity row is skipped
+	assert.Equal(t, map[string]float64{
+		"87654321/etl@example.com/DBU": 300.0,
+		"87654321/__other__/DBU":       50.0,
+		"87654321/__other__/GIGABYTE":  10.0,
+		"12345678/1b7a3c2e-sp/DBU":     120.0,
+	}, dbus)
+	assert.Equal(t, map[string]float64{
+		"87654321/etl@example.com": 45.0,
+		"87654321/__other__":       8.0,
+		"12345678/1b7a3c2e-sp":     84.0,
+	}, cost)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingAttribution(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"attribution_key", "workspace_id", "attribution_value", "usage_unit", "currency_code", "dbus_total", "cost_estimate_usd"}).
+		AddRow("job_id", "87654321", "1001", "DBU", "USD", 300.0, 45.0).
+		AddRow("job_id", "87654321", "__other__", "DBU", "USD", 50.0, 7.5).
+		AddRow("warehouse_id", "87654321", "abc123", "DBU", "USD", 120.0, 84.0)
+
+	mock.ExpectQuery("attr_job_id (.+) UNION ALL (.+) FROM attr_warehouse_id").
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.BillingAttributionLimits = map[string]int{"job_id": 1, "warehouse_id": 10}
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 10)
+	err = collector.collectBillingAttribution(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingAttribution failed")
+
+	dbus := make(map[string]float64)
+	for m := range ch {
+		if m.Desc() != collector.metrics.BillingAttributedDBUs {
+			continue
+		}
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		dbus[labels[labelAttributionKey]+"/"+labels[labelAttributionValue]] = pb.Gauge.GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{
+		"job_id/1001":         300.0,
+		"job_id/__other__":    50.0,
+		"warehouse_id/abc123": 120.0,
+	}, dbus)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingByTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "tag_0", "tag_1", "usage_unit", "currency_code", "dbus_total", "cost_estimate_usd"}).
+		AddRow("87654321", "data-platform", "cc-100", "DBU", "USD", 300.0, 45.0).
+		AddRow("87654321", "untagged", "untagged", "DBU", "USD", 50.0, 7.5)
+
+	mock.ExpectQuery(`custom_tags\['team'\](.+)custom_tags\['cost-center'\]`).
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.BillingTagKeys = []string{"team", "cost-center"}
+	metrics := NewMetricDescriptors()
+	metrics.setBillingTagKeys(config.BillingTagKeys)
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), metrics, config, promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 10)
+	err = collector.collectBillingByTag(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingByTag failed")
+
+	costs := make(map[string]float64)
+	for m := range ch {
+		if m.Desc() != metrics.BillingCostByTag {
+			continue
+		}
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		costs[labels["tag_team"]+"/"+labels["tag_cost_center"]] = pb.Gauge.GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{
+		"data-platform/cc-100": 45.0,
+		"untagged/untagged":    7.5,
+	}, costs)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingCostEffective(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "billing_origin_product", "usage_date", "currency_code", "dbus_total", "cost_estimate_usd"}).
+		AddRow("87654321", "PREMIUM_JOBS_COMPUTE", "JOBS", date("2026-02-28"), "USD", 100.0, 30.0).
+		AddRow("87654321", "PREMIUM_JOBS_COMPUTE", "JOBS", date("2026-03-01"), "USD", 100.0, 30.0).
+		AddRow("87654321", "PREMIUM_SQL", "SQL", date("2026-03-01"), "USD", 10.0, 7.0)
+
+	mock.ExpectQuery("SELECT (.+) u.usage_date, (.+) FROM system.billing.usage u").
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.PricingOverrides = []PriceOverride{
+		{Product: "JOBS", DiscountPercent: float64Ptr(20), EffectiveFrom: "2026-03-01"},
+	}
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 10)
+	err = collector.collectBillingCostEffective(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingCostEffective failed")
+
+	costs := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		for _, lp := range pb.Label {
+			if lp.GetName() == labelSKUName {
+				costs[lp.GetValue()] = pb.Gauge.GetValue()
+			}
+		}
+	}
+
+	// Daily rows are summed per SKU: list price before the override, 20% off from 2026-03-01
+	assert.InDelta(t, 30.0+24.0, costs["PREMIUM_JOBS_COMPUTE"], 1e-9)
+	assert.InDelta(t, 7.0, costs["PREMIUM_SQL"], 1e-9)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingCostEffective_UnitPriceCurrency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	// GBP has no FX rate, so its list price stays in GBP
+	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "billing_origin_product", "usage_date", "currency_code", "dbus_total", "cost_estimate_usd"}).
+		AddRow("87654321", "PREMIUM_SQL", "SQL", date("2026-03-01"), "GBP", 10.0, 6.0).
+		AddRow("87654321", "PREMIUM_JOBS_COMPUTE", "JOBS", date("2026-03-01"), "GBP", 100.0, 30.0)
+
+	mock.ExpectQuery("SELECT (.+) u.usage_date, (.+) FROM system.billing.usage u").
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.FXRates = &FXRates{ReportingCurrency: "EUR", Rates: map[string]float64{"USD": 0.92}}
+	config.PricingOverrides = []PriceOverride{
+		{SKU: "PREMIUM_SQL", UnitPrice: float64Ptr(0.5), EffectiveFrom: "2026-01-01"},
+		{Product: "JOBS", DiscountPercent: float64Ptr(20), EffectiveFrom: "2026-01-01"},
+	}
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 10)
+	err = collector.collectBillingCostEffective(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingCostEffective failed")
+
+	costs := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		costs[labels[labelSKUName]+"/"+labels[labelCurrencyCode]] = pb.Gauge.GetValue()
+	}
+
+	// Unit prices are in the reporting currency; discounts keep the list price currency
+	assert.Equal(t, map[string]float64{"PREMIUM_SQL/EUR": 5.0, "PREMIUM_JOBS_COMPUTE/GBP": 24.0}, costs)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingAnomaly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "currency_code", "weeks_ago", "cost_usd"}).
+		AddRow("12345678", "PREMIUM_JOBS_COMPUTE", "USD", 0, 150.0).
+		AddRow("12345678", "PREMIUM_JOBS_COMPUTE", "USD", 1, 100.0).
+		AddRow("12345678", "PREMIUM_JOBS_COMPUTE", "USD", 2, 100.0).
+		AddRow("12345678", "PREMIUM_JOBS_COMPUTE", "USD", 3, 100.0).
+		// No usage on the scored day, and none two and three weeks ago: too little history to score
+		AddRow("12345678", "PREMIUM_SQL", "USD", 1, 40.0).
+		AddRow(nil, "PREMIUM_SQL", "USD", 0, 1.0).
+		AddRow("12345678", "PREMIUM_SQL", "USD", 9, 1.0)
+
+	mock.ExpectQuery("SELECT (.+) weeks_ago(.+) FROM system.billing.usage u").
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.CollectBillingAnomaly = true
+	config.BillingAnomalyWeeks = 3
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+	collector.now = func() time.Time { return time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC) }
+
+	ch := make(chan prometheus.Metric, 20)
+	err = collector.collectBillingAnomaly(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingAnomaly failed")
+
+	names := map[*prometheus.Desc]string{
+		collector.metrics.BillingCostObserved:     "observed",
+		collector.metrics.BillingCostExpected:     "expected",
+		collector.metrics.BillingCostAnomalyScore: "score",
+	}
+	values := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		values[labels[labelSKUName]+"/"+names[m.Desc()]] = pb.Gauge.GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{
+		"PREMIUM_JOBS_COMPUTE/observed": 150,
+		"PREMIUM_JOBS_COMPUTE/expected": 100,
+		"PREMIUM_JOBS_COMPUTE/score":    5,
+	}, values)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBillingCalendar(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "sku_name", "usage_unit", "currency_code",
+		"today_dbus", "yesterday_dbus", "month_to_date_dbus", "previous_month_dbus",
+		"today_cost_usd", "yesterday_cost_usd", "month_to_date_cost_usd", "previous_month_cost_usd"}).
+		AddRow("12345678", "PREMIUM_JOBS_COMPUTE", "DBU", "USD", 1.0, 2.0, 30.0, 40.0, 0.5, 1.0, 15.0, 20.0).
+		AddRow(nil, "PREMIUM_SQL", "DBU", "USD", 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0)
+
+	// Month boundaries are midnight in the configured time zone
+	mock.ExpectQuery(`TIMESTAMP '2026-09-01T00:00:00\+02:00'(.+)FROM system.billing.usage u`).
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.BillingTimezone = "Europe/Berlin"
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+	collector.now = func() time.Time { return time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC) }
+
+	ch := make(chan prometheus.Metric, 20)
+	err = collector.collectBillingCalendar(ch)
+	close(ch)
+	require.NoError(t, err, "collectBillingCalendar failed")
+
+	dbus := make(map[string]float64)
+	costs := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		var period string
+		for _, lp := range pb.Label {
+			if lp.GetName() == labelPeriod {
+				period = lp.GetValue()
+			}
+		}
+		if strings.Contains(m.Desc().String(), "cost_estimate_usd_calendar") {
+			costs[period] = pb.Gauge.GetValue()
+		} else {
+			dbus[period] = pb.Gauge.GetValue()
+		}
+	}
+
+	// The row with a NULL workspace_id is skipped
+	assert.Equal(t, map[string]float64{"today": 1, "yesterday": 2, "month_to_date": 30, "previous_month": 40}, dbus)
+	assert.Equal(t, map[string]float64{"today": 0.5, "yesterday": 1, "month_to_date": 15, "previous_month": 20}, costs)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectBudgets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	hour := func(s string) int64 {
+		ts, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return ts.Unix()
+	}
+	rows := sqlmock.NewRows([]string{"hour_start", "currency_code", "budget_0", "budget_1"}).
+		// Still August in UTC, but already September 1st in Berlin
+		AddRow(hour("2026-08-31T23:00:00Z"), "USD", 100.0, 0.0).
+		AddRow(hour("2026-09-01T10:00:00Z"), "USD", 5.0, 1.0).
+		// Not in the reporting currency, so left out of the spend
+		AddRow(hour("2026-09-01T10:00:00Z"), "EUR", 50.0, 7.0).
+		AddRow(hour("2026-09-02T01:00:00Z"), "USD", 3.0, 0.0)
+
+	mock.ExpectQuery("SELECT (.+) as hour_start, (.+) as currency_code, (.+) FROM system.billing.usage u").
+		WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.BillingTimezone = "Europe/Berlin"
+	config.Budgets = []Budget{
+		{Name: "account", Amount: 1000},
+		{Name: "team-data", Amount: 10, Tags: map[string]string{"team": "data"}},
+	}
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+	collector.now = func() time.Time { return time.Date(2026, 9, 2, 12, 0, 0, 0, time.UTC) }
+
+	ch := make(chan prometheus.Metric, 20)
+	err = collector.collectBudgets(ch)
+	close(ch)
+	require.NoError(t, err, "collectBudgets failed")
+
+	names := map[*prometheus.Desc]string{
+		collector.metrics.BudgetAmount:            "amount",
+		collector.metrics.BudgetSpendMonthToDate:  "mtd",
+		collector.metrics.BudgetBurnRatio:         "burn",
+		collector.metrics.BudgetProjectedMonthEnd: "projected",
+	}
+	values := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		key := names[m.Desc()]
+		for _, lp := range pb.Label {
+			key += "/" + lp.GetValue()
+		}
+		values[key] = pb.Gauge.GetValue()
+	}
+
+	assert.Equal(t, 1000.0, values["amount/account/USD"])
+	assert.Equal(t, 108.0, values["mtd/account/USD"])
+	assert.InDelta(t, 0.108, values["burn/account"], 1e-9)
+	assert.Equal(t, 1.0, values["mtd/team-data/USD"])
+	assert.InDelta(t, 0.1, values["burn/team-data"], 1e-9)
+	// One complete day of 105 in September, then 29 more days at that rate
+	assert.InDelta(t, 105.0*30, values["projected/account/USD/linear"], 1e-9)
+	assert.Contains(t, values, "projected/account/USD/weekday")
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
+
+func TestBillingCollector_CollectWarehouseIdle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "warehouse_id", "usage_unit", "currency_code", "idle_dbus", "idle_cost_usd"}).
+		AddRow("123456789", "wh1", "DBU", "USD", 12.0, 8.4).
+		AddRow("123456789", "wh2", "DBU", "USD", 0.0, 0.0)
+
+	mock.ExpectQuery("SELECT(.+)FROM warehouse_usage w").WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.CollectWarehouseIdle = true
+	collector := NewBillingCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, promslog.NewNopLogger())
+
+	ch := make(chan prometheus.Metric, 10)
+	err = collector.collectWarehouseIdle(ch)
+	close(ch)
+	require.NoError(t, err, "collectWarehouseIdle failed")
+
+	var idleDBUs, idleCost []float64
+	for m := range ch {
+		pb := &dto.Metric{}
+		require.NoError(t, m.Write(pb), "failed to write metric")
+		switch m.Desc() {
+		case collector.metrics.WarehouseIdleDBUs:
+			idleDBUs = append(idleDBUs, pb.Gauge.GetValue())
+		case collector.metrics.WarehouseIdleCost:
+			idleCost = append(idleCost, pb.Gauge.GetValue())
+		}
+	}
+
+	// Fully busy warehouses report zero rather than disappearing
+	assert.Equal(t, []float64{12.0, 0}, idleDBUs)
+	assert.Equal(t, []float64{8.4, 0}, idleCost)
+	require.NoError(t, mock.ExpectationsWereMet(), "unfulfilled expectations")
+}
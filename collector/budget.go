@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Forecast methods reported in the method label of the projected month-end metric.
+const (
+	forecastLinear  = "linear"
+	forecastWeekday = "weekday"
+)
+
+// forecastHistoryDays is how many completed days before today are used for weekday weights.
+// A multiple of seven, so every weekday is sampled equally.
+const forecastHistoryDays = 28
+
+// Budgets is the contents of the budgets file.
+type Budgets struct {
+	Budgets []Budget `yaml:"budgets"`
+}
+
+// Budget is a monthly spend limit for the usage matching all of its filters.
+// A budget without filters covers the whole account.
+type Budget struct {
+	Name        string            `yaml:"name"`         // Reported in the budget label
+	Amount      float64           `yaml:"amount"`       // Monthly budget in the reporting currency (USD unless --fx-rates-file sets one)
+	WorkspaceID string            `yaml:"workspace_id"` // Matches usage workspace_id
+	SKU         string            `yaml:"sku"`          // Matches usage sku_name
+	Tags        map[string]string `yaml:"tags"`         // Matches usage custom_tags
+}
+
+var (
+	errEmptyBudgetName = errors.New("budget name must not be empty")
+	errBudgetAmount    = errors.New("budget amount must be positive")
+)
+
+// LoadBudgets reads and validates a YAML budgets file.
+func LoadBudgets(path string) ([]Budget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budgets file: %w", err)
+	}
+
+	var file Budgets
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse budgets file %s: %w", path, err)
+	}
+
+	if err := validateBudgets(file.Budgets); err != nil {
+		return nil, fmt.Errorf("invalid budgets file %s: %w", path, err)
+	}
+	return file.Budgets, nil
+}
+
+// validateBudgets checks that budgets are named uniquely and have a positive amount.
+func validateBudgets(budgets []Budget) error {
+	seen := make(map[string]bool, len(budgets))
+	for i, b := range budgets {
+		if strings.TrimSpace(b.Name) == "" {
+			return fmt.Errorf("budget %d: %w", i+1, errEmptyBudgetName)
+		}
+		if seen[b.Name] {
+			return fmt.Errorf("duplicate budget name %q", b.Name)
+		}
+		seen[b.Name] = true
+
+		if b.Amount <= 0 {
+			return fmt.Errorf("budget %q: %w", b.Name, errBudgetAmount)
+		}
+		for key := range b.Tags {
+			if strings.TrimSpace(key) == "" {
+				return fmt.Errorf("budget %q: %w", b.Name, errEmptyBillingTagKey)
+			}
+		}
+	}
+	return nil
+}
+
+// budgetForecast is the month-to-date spend of a budget and its projected month-end spend.
+type budgetForecast struct {
+	monthToDate float64
+	linear      float64
+	weekday     float64
+}
+
+// forecastMonthEnd projects month-end spend from daily spend, keyed by date (YYYY-MM-DD) in loc.
+//
+// Days before today are complete; today's spend is partial and counts at least as much as it
+// is expected to. The linear forecast extends the average spend of the completed days this
+// month, or of the trailing history on the first of the month. The weekday forecast does the
+// same after weighting each day by the average spend on its weekday over the trailing
+// forecastHistoryDays, so quiet weekends early in the month don't skew the projection.
+func forecastMonthEnd(daily map[string]float64, now time.Time, loc *time.Location) budgetForecast {
+	today, _, month, _ := calendarBounds(now, loc)
+	nextMonth := month.AddDate(0, 1, 0)
+	spend := func(d time.Time) float64 { return daily[d.Format(dateLayout)] }
+
+	// Weekday weights relative to the trailing average day
+	var history float64
+	var weekdaySpend [7]float64
+	for d := today.AddDate(0, 0, -forecastHistoryDays); d.Before(today); d = d.AddDate(0, 0, 1) {
+		history += spend(d)
+		weekdaySpend[d.Weekday()] += spend(d)
+	}
+	historyRate := history / forecastHistoryDays
+	var weights [7]float64
+	for wd := range weights {
+		weights[wd] = 1
+		if history > 0 {
+			weights[wd] = weekdaySpend[wd] / (forecastHistoryDays / 7) / historyRate
+		}
+	}
+
+	// Daily rates from the completed days this month, falling back to the trailing history
+	var completed, completedWeight float64
+	completedDays := 0
+	for d := month; d.Before(today); d = d.AddDate(0, 0, 1) {
+		completed += spend(d)
+		completedWeight += weights[d.Weekday()]
+		completedDays++
+	}
+	linearRate, weekdayRate := historyRate, historyRate
+	if completedDays > 0 {
+		linearRate = completed / float64(completedDays)
+	}
+	if completedWeight > 0 {
+		weekdayRate = completed / completedWeight
+	}
+
+	forecast := budgetForecast{
+		monthToDate: completed + spend(today),
+		linear:      completed,
+		weekday:     completed,
+	}
+	for d := today; d.Before(nextMonth); d = d.AddDate(0, 0, 1) {
+		linear, weekday := linearRate, weekdayRate*weights[d.Weekday()]
+		if d.Equal(today) {
+			linear, weekday = max(linear, spend(d)), max(weekday, spend(d))
+		}
+		forecast.linear += linear
+		forecast.weekday += weekday
+	}
+	return forecast
+}
@@ -0,0 +1,150 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBudgets(t *testing.T) {
+	budgets, err := LoadBudgets("testdata/budgets.yaml")
+	require.NoError(t, err)
+	require.Len(t, budgets, 3)
+
+	assert.Equal(t, "account", budgets[0].Name)
+	assert.Equal(t, 50000.0, budgets[0].Amount)
+	assert.Equal(t, "1234567890", budgets[1].WorkspaceID)
+	assert.Equal(t, "PREMIUM_JOBS_COMPUTE", budgets[1].SKU)
+	assert.Equal(t, map[string]string{"team": "data"}, budgets[2].Tags)
+}
+
+func TestLoadBudgets_Errors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	_, err := LoadBudgets(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read")
+
+	_, err = LoadBudgets(write("unknown.yaml", "budgets:\n  - name: a\n    limit: 10\n"))
+	assert.ErrorContains(t, err, "failed to parse", "unknown fields should be rejected")
+
+	_, err = LoadBudgets(write("duplicate.yaml", "budgets:\n  - name: a\n    amount: 10\n  - name: a\n    amount: 20\n"))
+	assert.ErrorContains(t, err, "duplicate budget name")
+}
+
+func TestValidateBudgets(t *testing.T) {
+	assert.NoError(t, validateBudgets(nil))
+	assert.ErrorIs(t, validateBudgets([]Budget{{Amount: 10}}), errEmptyBudgetName)
+	assert.ErrorIs(t, validateBudgets([]Budget{{Name: "a"}}), errBudgetAmount)
+	assert.ErrorIs(t, validateBudgets([]Budget{{Name: "a", Amount: 10, Tags: map[string]string{"": "x"}}}), errEmptyBillingTagKey)
+}
+
+// spendSeries returns daily spend from first up to, but not including, last, using spend(day).
+func spendSeries(first, last string, spend func(day time.Time) float64) map[string]float64 {
+	series := make(map[string]float64)
+	from, to := date(first), date(last)
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		series[d.Format(dateLayout)] = spend(d)
+	}
+	return series
+}
+
+func TestForecastMonthEnd(t *testing.T) {
+	weekdaysOnly := func(d time.Time) float64 {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			return 0
+		}
+		return 10
+	}
+	flat := func(amount float64) func(time.Time) float64 {
+		return func(time.Time) float64 { return amount }
+	}
+
+	tests := []struct {
+		name     string
+		daily    map[string]float64
+		today    string  // Date of the current, partial day
+		partial  float64 // Spend recorded so far today
+		now      time.Time
+		expected budgetForecast
+	}{
+		{
+			name:  "flat spend",
+			daily: spendSeries("2026-08-01", "2026-09-11", flat(10)),
+			today: "2026-09-11", partial: 4,
+			now: time.Date(2026, 9, 11, 12, 0, 0, 0, time.UTC),
+			// 10 complete days, then today counts as a full day: 30 days of 10
+			expected: budgetForecast{monthToDate: 104, linear: 300, weekday: 300},
+		},
+		{
+			// September 2026 starts on a Tuesday and has 22 weekdays. The linear forecast
+			// spreads 8 weekdays of spend over 10 days; the weekday forecast spends only on
+			// the 14 weekdays left, including today.
+			name:  "weekdays only",
+			daily: spendSeries("2026-08-01", "2026-09-11", weekdaysOnly),
+			today: "2026-09-11", partial: 3,
+			now:      time.Date(2026, 9, 11, 12, 0, 0, 0, time.UTC),
+			expected: budgetForecast{monthToDate: 83, linear: 80 + 20*8, weekday: 220},
+		},
+		{
+			name:  "first of month falls back to trailing history",
+			daily: spendSeries("2026-09-01", "2026-10-01", flat(5)),
+			today: "2026-10-01", partial: 1,
+			now:      time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC),
+			expected: budgetForecast{monthToDate: 1, linear: 155, weekday: 155},
+		},
+		{
+			name:  "today above the daily rate",
+			daily: spendSeries("2026-08-01", "2026-09-11", flat(10)),
+			today: "2026-09-11", partial: 25,
+			now:      time.Date(2026, 9, 11, 12, 0, 0, 0, time.UTC),
+			expected: budgetForecast{monthToDate: 125, linear: 100 + 25 + 190, weekday: 100 + 25 + 190},
+		},
+		{
+			// Without completed days or history there is no rate to extend
+			name:  "first day of a new budget",
+			daily: map[string]float64{},
+			today: "2026-10-01", partial: 2,
+			now:      time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC),
+			expected: budgetForecast{monthToDate: 2, linear: 2, weekday: 2},
+		},
+		{
+			name:     "no spend",
+			daily:    map[string]float64{},
+			now:      time.Date(2026, 9, 11, 12, 0, 0, 0, time.UTC),
+			expected: budgetForecast{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.today != "" {
+				tt.daily[tt.today] = tt.partial
+			}
+			forecast := forecastMonthEnd(tt.daily, tt.now, time.UTC)
+			assert.InDelta(t, tt.expected.monthToDate, forecast.monthToDate, 1e-9, "month to date")
+			assert.InDelta(t, tt.expected.linear, forecast.linear, 1e-9, "linear")
+			assert.InDelta(t, tt.expected.weekday, forecast.weekday, 1e-9, "weekday")
+		})
+	}
+}
+
+func TestForecastMonthEnd_TimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// 2026-09-30 20:00 UTC is already October 1st in Tokyo, so September is the previous month
+	daily := spendSeries("2026-09-01", "2026-10-01", func(time.Time) float64 { return 10 })
+	forecast := forecastMonthEnd(daily, time.Date(2026, 9, 30, 20, 0, 0, 0, time.UTC), tokyo)
+
+	assert.Zero(t, forecast.monthToDate)
+	assert.InDelta(t, 310, forecast.linear, 1e-9)
+}
@@ -0,0 +1,214 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chargeback report output formats.
+const (
+	ChargebackFormatCSV  = "csv"
+	ChargebackFormatJSON = "json"
+)
+
+// Chargeback groupings other than tags and usage_metadata keys.
+const (
+	chargebackGroupProduct  = "product"
+	chargebackGroupIdentity = "identity"
+	chargebackTagPrefix     = "tag:"
+)
+
+// chargebackMonthLayout is the format of the month in a chargeback report.
+const chargebackMonthLayout = "2006-01"
+
+var (
+	errChargebackMonth   = errors.New("month must be in YYYY-MM format")
+	errChargebackFuture  = errors.New("month must not be in the future")
+	errChargebackGroupBy = errors.New("group by must be tag:<key>, product, identity or a billing attribution key")
+	errChargebackFormat  = errors.New("format must be csv or json")
+)
+
+// ChargebackReport is the usage and list-price cost of one calendar month, per group, workspace
+// and SKU.
+type ChargebackReport struct {
+	Month    string          `json:"month"`    // YYYY-MM
+	Timezone string          `json:"timezone"` // Time zone of the month boundaries
+	GroupBy  string          `json:"group_by"`
+	Rows     []ChargebackRow `json:"rows"`
+}
+
+// ChargebackRow is the total usage and cost of one group in one workspace and SKU.
+type ChargebackRow struct {
+	Group         string  `json:"group"`
+	WorkspaceID   string  `json:"workspace_id"`
+	SKUName       string  `json:"sku_name"`
+	UsageUnit     string  `json:"usage_unit"`
+	UsageQuantity float64 `json:"usage_quantity"`
+	CurrencyCode  string  `json:"currency_code"`
+	Cost          float64 `json:"cost"`
+}
+
+// chargebackGroupExpr returns the SQL expression for a chargeback grouping: tag:<key> groups by a
+// custom tag, product by billing_origin_product, identity by run_as or owner, and a billing
+// attribution key (job_id, warehouse_id, ...) by that usage_metadata field.
+func chargebackGroupExpr(groupBy, placeholder string) (string, bool) {
+	var expr string
+	switch {
+	case strings.HasPrefix(groupBy, chargebackTagPrefix):
+		key := strings.TrimPrefix(groupBy, chargebackTagPrefix)
+		if key == "" {
+			return "", false
+		}
+		expr = fmt.Sprintf("NULLIF(u.custom_tags[%s], '')", sqlStringLiteral(key))
+	case groupBy == chargebackGroupProduct:
+		expr = "u.billing_origin_product"
+	case groupBy == chargebackGroupIdentity:
+		expr = "COALESCE(u.identity_metadata.run_as, u.identity_metadata.owned_by)"
+	case slices.Contains(billingAttributionKeys, groupBy):
+		expr = "u.usage_metadata." + groupBy
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("COALESCE(%s, %s)", expr, sqlStringLiteral(placeholder)), true
+}
+
+// ParseChargebackMonth parses a YYYY-MM month into midnight on its first day in loc.
+func ParseChargebackMonth(month string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation(chargebackMonthLayout, month, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", errChargebackMonth, month)
+	}
+	return t, nil
+}
+
+// PreviousChargebackMonth returns the month before now in loc, in YYYY-MM format.
+func PreviousChargebackMonth(now time.Time, loc *time.Location) string {
+	_, _, _, previousMonth := calendarBounds(now, loc)
+	return previousMonth.Format(chargebackMonthLayout)
+}
+
+// GenerateChargebackReport connects to the configured SQL warehouse (with failover) and returns
+// the chargeback report for month, a YYYY-MM month in BillingTimezone. The current month is
+// reported up to now. Usage without a value for groupBy is reported as BillingTagPlaceholder.
+func GenerateChargebackReport(ctx context.Context, logger *slog.Logger, config *Config, month, groupBy string) (*ChargebackReport, error) {
+	pool := newWarehousePool(config.warehouseHTTPPaths(), config, logger, openDatabricksDatabase)
+	db, err := pool.get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SQL warehouse: %w", err)
+	}
+	defer db.Close()
+
+	queryTimeout := config.QueryTimeout
+	if queryTimeout == 0 {
+		queryTimeout = DefaultQueryTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return queryChargebackReport(ctx, db, config, time.Now(), month, groupBy)
+}
+
+// queryChargebackReport runs the chargeback query for month against db.
+func queryChargebackReport(ctx context.Context, db *sql.DB, config *Config, now time.Time, month, groupBy string) (*ChargebackReport, error) {
+	loc, err := config.billingLocation()
+	if err != nil {
+		return nil, err
+	}
+	start, err := ParseChargebackMonth(month, loc)
+	if err != nil {
+		return nil, err
+	}
+	if start.After(now) {
+		return nil, fmt.Errorf("%w: %s", errChargebackFuture, month)
+	}
+
+	placeholder := config.BillingTagPlaceholder
+	if placeholder == "" {
+		placeholder = DefaultBillingTagPlaceholder
+	}
+	query := BuildChargebackQuery(now, start, groupBy, placeholder, config.billingCostMode(), config.FXRates)
+	if query == "" {
+		return nil, fmt.Errorf("%w: %q", errChargebackGroupBy, groupBy)
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute chargeback query: %w", err)
+	}
+	defer rows.Close()
+
+	report := &ChargebackReport{
+		Month:    month,
+		Timezone: loc.String(),
+		GroupBy:  groupBy,
+		Rows:     []ChargebackRow{},
+	}
+	for rows.Next() {
+		var group, workspaceID, skuName, usageUnit, currencyCode sql.NullString
+		var quantity, cost sql.NullFloat64
+
+		if err := rows.Scan(&group, &workspaceID, &skuName, &usageUnit, &currencyCode, &quantity, &cost); err != nil {
+			return nil, fmt.Errorf("failed to scan chargeback row: %w", err)
+		}
+
+		report.Rows = append(report.Rows, ChargebackRow{
+			Group:         group.String,
+			WorkspaceID:   workspaceID.String,
+			SKUName:       skuName.String,
+			UsageUnit:     usageUnit.String,
+			UsageQuantity: quantity.Float64,
+			CurrencyCode:  currencyCode.String,
+			Cost:          cost.Float64,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Write writes the report to w in the given format. CSV has one line per row, with the month
+// and grouping repeated on every line so files from several months can be concatenated.
+func (r *ChargebackReport) Write(w io.Writer, format string) error {
+	switch format {
+	case ChargebackFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case ChargebackFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"month", "group_by", "group", "workspace_id", "sku_name", "usage_unit", "usage_quantity", "currency_code", "cost"}); err != nil {
+			return err
+		}
+		for _, row := range r.Rows {
+			if err := cw.Write([]string{
+				r.Month,
+				r.GroupBy,
+				row.Group,
+				row.WorkspaceID,
+				row.SKUName,
+				row.UsageUnit,
+				strconv.FormatFloat(row.UsageQuantity, 'f', -1, 64),
+				row.CurrencyCode,
+				strconv.FormatFloat(row.Cost, 'f', -1, 64),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("%w: %q", errChargebackFormat, format)
+	}
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var chargebackColumns = []string{"chargeback_group", "workspace_id", "sku_name", "usage_unit", "currency_code", "usage_quantity", "cost"}
+
+func TestQueryChargebackReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	rows := sqlmock.NewRows(chargebackColumns).
+		AddRow("data-eng", "123456789", "PREMIUM_JOBS_COMPUTE", "DBU", "USD", 100.0, 15.0).
+		AddRow("untagged", "123456789", "PREMIUM_SQL_PRO_COMPUTE", "DBU", "USD", 10.0, 5.5)
+	mock.ExpectQuery("SELECT(.+)FROM system.billing.usage").WillReturnRows(rows)
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	report, err := queryChargebackReport(context.Background(), db, DefaultConfig(), now, "2026-09", "tag:team")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2026-09", report.Month)
+	assert.Equal(t, "UTC", report.Timezone)
+	assert.Equal(t, "tag:team", report.GroupBy)
+	require.Len(t, report.Rows, 2)
+	assert.Equal(t, ChargebackRow{
+		Group:         "data-eng",
+		WorkspaceID:   "123456789",
+		SKUName:       "PREMIUM_JOBS_COMPUTE",
+		UsageUnit:     "DBU",
+		UsageQuantity: 100,
+		CurrencyCode:  "USD",
+		Cost:          15,
+	}, report.Rows[0])
+	assert.Equal(t, "untagged", report.Rows[1].Group)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryChargebackReport_InvalidArguments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		month   string
+		groupBy string
+		want    error
+	}{
+		{"malformed month", "2026-9", "product", errChargebackMonth},
+		{"future month", "2026-11", "product", errChargebackFuture},
+		{"unknown grouping", "2026-09", "team", errChargebackGroupBy},
+		{"empty tag key", "2026-09", "tag:", errChargebackGroupBy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queryChargebackReport(context.Background(), db, DefaultConfig(), now, tt.month, tt.groupBy)
+			assert.True(t, errors.Is(err, tt.want), "got %v, want %v", err, tt.want)
+		})
+	}
+
+	// Invalid arguments fail before querying
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryChargebackReport_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT(.+)FROM system.billing.usage").WillReturnError(errors.New("warehouse stopped"))
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	_, err = queryChargebackReport(context.Background(), db, DefaultConfig(), now, "2026-09", "product")
+	assert.ErrorContains(t, err, "warehouse stopped")
+}
+
+func TestChargebackReport_Write(t *testing.T) {
+	report := &ChargebackReport{
+		Month:    "2026-09",
+		Timezone: "UTC",
+		GroupBy:  "tag:team",
+		Rows: []ChargebackRow{
+			{Group: "data-eng", WorkspaceID: "123456789", SKUName: "PREMIUM_JOBS_COMPUTE", UsageUnit: "DBU", UsageQuantity: 100, CurrencyCode: "USD", Cost: 15.25},
+			{Group: "bi, reporting", WorkspaceID: "123456789", SKUName: "PREMIUM_SQL_PRO_COMPUTE", UsageUnit: "DBU", UsageQuantity: 10, CurrencyCode: "USD", Cost: 5.5},
+		},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.Write(&buf, ChargebackFormatCSV))
+		assert.Equal(t, "month,group_by,group,workspace_id,sku_name,usage_unit,usage_quantity,currency_code,cost\n"+
+			"2026-09,tag:team,data-eng,123456789,PREMIUM_JOBS_COMPUTE,DBU,100,USD,15.25\n"+
+			"2026-09,tag:team,\"bi, reporting\",123456789,PREMIUM_SQL_PRO_COMPUTE,DBU,10,USD,5.5\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, report.Write(&buf, ChargebackFormatJSON))
+
+		var decoded ChargebackReport
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, *report, decoded)
+		assert.Contains(t, buf.String(), `"group_by": "tag:team"`)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := report.Write(&bytes.Buffer{}, "xlsx")
+		assert.True(t, errors.Is(err, errChargebackFormat))
+	})
+}
+
+func TestPreviousChargebackMonth(t *testing.T) {
+	// 02:00 UTC on the first is still the previous month in New York
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 1, 2, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "2026-09", PreviousChargebackMonth(now, time.UTC))
+	assert.Equal(t, "2026-08", PreviousChargebackMonth(now, loc))
+}
@@ -5,11 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"sync"
 	"time"
 
 	dbsql "github.com/databricks/databricks-sql-go"
-	"github.com/databricks/databricks-sql-go/auth/oauth/m2m"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -22,6 +22,8 @@ const (
 	labelStatus      = "status"
 	labelStage       = "stage"
 	labelQuantile    = "quantile"
+	labelPeriod      = "period"
+	labelPhase       = "phase"
 
 	// Resource identification labels
 	labelJobID        = "job_id"
@@ -30,27 +32,73 @@ const (
 	labelPipelineName = "pipeline_name"
 	labelTaskKey      = "task_key"
 	labelWarehouseID  = "warehouse_id"
+	labelExecutedBy   = "executed_by"
+	labelQuerySource  = "query_source"
+
+	// Job run outcome labels
+	labelTerminationCode = "termination_code"
+
+	// Billing attribution labels
+	labelBillingOriginProduct = "billing_origin_product"
+	labelComputeType          = "compute_type"
+	labelPhoton               = "photon"
+	labelIdentity             = "identity"
+	labelAttributionKey       = "attribution_key"
+	labelAttributionValue     = "attribution_value"
+	labelUsageUnit            = "usage_unit"
+	labelCurrencyCode         = "currency_code"
+
+	// Budget labels
+	labelBudget         = "budget"
+	labelForecastMethod = "method"
+
+	// Exporter state labels
+	labelWarehouseHTTPPath = "warehouse_http_path"
+	labelWarehouseRole     = "role"
 
 	// Scrape status labels
 	labelQuery = "query"
 )
 
-// openDatabricksDatabase opens a connection to a Databricks SQL Warehouse using OAuth2 M2M authentication.
-func openDatabricksDatabase(config *Config) (*sql.DB, error) {
+// openDatabricksDatabase opens a connection to the Databricks SQL Warehouse at httpPath using OAuth2 M2M authentication.
+func openDatabricksDatabase(config *Config, httpPath string) (*sql.DB, error) {
+	port := config.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+
+	opts := []dbsql.ConnOption{
+		dbsql.WithServerHostname(config.ServerHostname),
+		dbsql.WithHTTPPath(httpPath),
+		dbsql.WithPort(port),
+	}
+
+	if config.Catalog != "" || config.Schema != "" {
+		opts = append(opts, dbsql.WithInitialNamespace(config.Catalog, config.Schema))
+	}
+
+	if params := config.sessionParams(); len(params) > 0 {
+		opts = append(opts, dbsql.WithSessionParams(params))
+	}
+
+	// Route both SQL and OAuth token traffic through the same transport when
+	// proxy or TLS options are configured; otherwise keep the driver defaults.
+	var transport *http.Transport
+	if config.hasCustomTransport() {
+		var err error
+		transport, err = newHTTPTransport(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP transport: %w", err)
+		}
+		opts = append(opts, dbsql.WithTransport(transport))
+	}
+
 	// Create OAuth M2M authenticator with Service Principal credentials
-	authenticator := m2m.NewAuthenticator(
-		config.ClientID,
-		config.ClientSecret,
-		config.ServerHostname,
-	)
+	authenticator := newConfiguredAuthenticator(config, transport)
+	opts = append(opts, dbsql.WithAuthenticator(authenticator))
 
 	// Create connector with OAuth authentication
-	connector, err := dbsql.NewConnector(
-		dbsql.WithServerHostname(config.ServerHostname),
-		dbsql.WithHTTPPath(config.WarehouseHTTPPath),
-		dbsql.WithPort(443),
-		dbsql.WithAuthenticator(authenticator),
-	)
+	connector, err := dbsql.NewConnector(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connector: %w", err)
 	}
@@ -58,82 +106,120 @@ func openDatabricksDatabase(config *Config) (*sql.DB, error) {
 	db := sql.OpenDB(connector)
 
 	// Configure connection pool for better resilience
-	db.SetMaxOpenConns(10)                 // Limit concurrent connections to avoid overwhelming Databricks
-	db.SetMaxIdleConns(5)                  // Keep some connections warm
-	db.SetConnMaxLifetime(5 * time.Minute) // Recycle connections every 5 minutes
-	db.SetConnMaxIdleTime(1 * time.Minute) // Close idle connections after 1 minute
+	applyPoolSettings(db, config)
 
 	return db, nil
 }
 
+// applyPoolSettings configures the connection pool, falling back to defaults for unset values.
+func applyPoolSettings(db *sql.DB, config *Config) {
+	maxOpen, maxIdle := config.poolSize()
+	lifetime := config.ConnMaxLifetime
+	if lifetime == 0 {
+		lifetime = DefaultConnMaxLifetime
+	}
+	idleTime := config.ConnMaxIdleTime
+	if idleTime == 0 {
+		idleTime = DefaultConnMaxIdleTime
+	}
+
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(lifetime)
+	db.SetConnMaxIdleTime(idleTime)
+}
+
 // Collector is a prometheus.Collector that retrieves all metrics for a Databricks account.
 // It orchestrates multiple specialized collectors for different metric categories.
 type Collector struct {
 	config       *Config
 	logger       *slog.Logger
-	openDatabase func(*Config) (*sql.DB, error) // For mocking
+	openDatabase func(*Config, string) (*sql.DB, error) // For mocking
 	metrics      *MetricDescriptors
 
-	// Persistent connection pool - reused across scrapes
-	db   *sql.DB
-	dbMu sync.RWMutex
+	// Persistent connection pools - reused across scrapes. The primary pool fails over
+	// between warehouses; routed warehouses each get their own pool keyed by HTTP path.
+	pool   *warehousePool
+	routed map[string]*warehousePool
+
+	// Jobs API client for active job runs, nil unless LiveJobRuns is set
+	jobsAPI *jobsAPIClient
+
+	// Readiness state, updated by RunHealthChecks
+	checkAuth func() error // For mocking
+	health    healthState
 }
 
 // NewCollector creates a new collector from a given config.
 // The config is assumed to be valid.
 func NewCollector(logger *slog.Logger, c *Config) *Collector {
 	metrics := NewMetricDescriptors()
+	metrics.setBillingTagKeys(c.BillingTagKeys)
 
-	return &Collector{
+	col := &Collector{
 		config:       c,
 		logger:       logger,
 		openDatabase: openDatabricksDatabase,
 		metrics:      metrics,
+		checkAuth:    newAuthCheck(c),
 	}
-}
+	// Resolve openDatabase at call time so tests can replace it after construction
+	open := func(cfg *Config, path string) (*sql.DB, error) {
+		return col.openDatabase(cfg, path)
+	}
+	col.pool = newWarehousePool(c.warehouseHTTPPaths(), c, logger, open)
 
-// getDB returns a healthy database connection, creating one if needed.
-// It tests the connection with Ping() and recreates if unhealthy.
-func (c *Collector) getDB() (*sql.DB, error) {
-	c.dbMu.RLock()
-	db := c.db
-	c.dbMu.RUnlock()
-
-	// Test existing connection
-	if db != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := db.PingContext(ctx); err == nil {
-			return db, nil
+	if c.LiveJobRuns {
+		col.jobsAPI = newJobsAPIClient(c, logger)
+	}
+
+	col.routed = make(map[string]*warehousePool)
+	for _, path := range c.WarehouseRoutes {
+		if path == c.WarehouseHTTPPath || col.routed[path] != nil {
+			continue
 		}
-		c.logger.Warn("Existing connection unhealthy, reconnecting", "err", "ping failed")
+		col.routed[path] = newWarehousePool([]string{path}, c, logger, open)
 	}
 
-	// Need to create or recreate connection
-	c.dbMu.Lock()
-	defer c.dbMu.Unlock()
+	return col
+}
 
-	// Double-check after acquiring write lock
-	if c.db != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := c.db.PingContext(ctx); err == nil {
-			return c.db, nil
+// getDB returns a healthy database connection from the active warehouse, creating one if needed.
+func (c *Collector) getDB() (*sql.DB, error) {
+	return c.pool.get()
+}
+
+// newRouter connects to every routed warehouse and returns a router for this scrape.
+// An unreachable routed warehouse fails only the queries routed to it.
+func (c *Collector) newRouter(db *sql.DB, metrics chan<- prometheus.Metric) *queryRouter {
+	primaryPath, _ := c.pool.activeWarehouse()
+	router := &queryRouter{
+		fallback: routedDB{db: db, path: primaryPath},
+		routes:   make(map[string]routedDB, len(c.config.WarehouseRoutes)),
+		metrics:  c.metrics,
+	}
+
+	connected := make(map[string]routedDB, len(c.routed))
+	for path, pool := range c.routed {
+		routedConn, err := pool.get()
+		if err != nil {
+			c.logger.Error("Failed to connect to routed SQL warehouse", "warehouse_http_path", path, "err", err)
+		} else {
+			metrics <- prometheus.MustNewConstMetric(c.metrics.ActiveWarehouse, prometheus.GaugeValue, 1, path, warehouseRoleRouted)
 		}
-		// Close unhealthy connection
-		c.db.Close()
-		c.db = nil
+		connected[path] = routedDB{db: routedConn, path: path, err: err}
 	}
 
-	// Create new connection
-	db, err := c.openDatabase(c.config)
-	if err != nil {
-		return nil, err
+	for key, path := range c.config.WarehouseRoutes {
+		if target, ok := connected[path]; ok {
+			router.routes[key] = target
+		} else {
+			// Routed to the primary warehouse, which follows failover
+			router.routes[key] = router.fallback
+		}
 	}
 
-	c.db = db
-	c.logger.Debug("Created new database connection pool")
-	return db, nil
+	return router
 }
 
 // Describe implements prometheus.Collector.
@@ -159,6 +245,9 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
 	metrics <- prometheus.MustNewConstMetric(c.metrics.ExporterUp, prometheus.GaugeValue, 1)
 	c.logger.Debug("Database connection healthy, emitted up=1")
 
+	warehousePath, warehouseRole := c.pool.activeWarehouse()
+	metrics <- prometheus.MustNewConstMetric(c.metrics.ActiveWarehouse, prometheus.GaugeValue, 1, warehousePath, warehouseRole)
+
 	// Emit exporter info metric with version and window configuration
 	metrics <- prometheus.MustNewConstMetric(
 		c.metrics.ExporterInfo,
@@ -169,6 +258,7 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
 		c.config.JobsLookback.String(),
 		c.config.PipelinesLookback.String(),
 		c.config.QueriesLookback.String(),
+		c.config.billingCostMode(),
 	)
 
 	queryTimeout := c.config.QueryTimeout
@@ -179,10 +269,13 @@ func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 
-	billingCollector := NewBillingCollector(ctx, db, c.metrics, c.config, c.logger)
-	jobsCollector := NewJobsCollector(ctx, db, c.metrics, c.config, c.logger)
-	pipelinesCollector := NewPipelinesCollector(ctx, db, c.metrics, c.config, c.logger)
-	sqlWarehouseCollector := NewSQLWarehouseCollector(ctx, db, c.metrics, c.config, c.logger)
+	router := c.newRouter(db, metrics)
+
+	billingCollector := NewBillingCollector(ctx, router, c.metrics, c.config, c.logger)
+	jobsCollector := NewJobsCollector(ctx, router, c.metrics, c.config, c.logger)
+	jobsCollector.jobsAPI = c.jobsAPI
+	pipelinesCollector := NewPipelinesCollector(ctx, router, c.metrics, c.config, c.logger)
+	sqlWarehouseCollector := NewSQLWarehouseCollector(ctx, router, c.metrics, c.config, c.logger)
 
 	start := time.Now()
 
@@ -3,8 +3,10 @@ package collector
 import (
 	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/promslog"
 )
@@ -64,12 +66,43 @@ func TestCollectorDescribe(t *testing.T) {
 	}
 
 	// Should have all metrics
-	expectedCount := 21
+	expectedCount := 58
 	if len(descriptions) != expectedCount {
 		t.Errorf("expected %d metric descriptions, got %d", expectedCount, len(descriptions))
 	}
 }
 
+func TestCollectorDescribe_BillingTagKeys(t *testing.T) {
+	config := &Config{
+		ServerHostname:    "test.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/test",
+		ClientID:          "test-id",
+		ClientSecret:      "test-secret",
+		BillingTagKeys:    []string{"team"},
+	}
+
+	collector := NewCollector(promslog.NewNopLogger(), config)
+
+	descCh := make(chan *prometheus.Desc, 100)
+	collector.Describe(descCh)
+	close(descCh)
+
+	found := map[string]bool{}
+	for desc := range descCh {
+		for _, name := range []string{"databricks_billing_dbus_by_tag_sliding", "databricks_billing_cost_by_tag_usd_sliding"} {
+			if strings.Contains(desc.String(), `fqName: "`+name+`"`) {
+				if !strings.Contains(desc.String(), "tag_team") {
+					t.Errorf("expected tag_team label on %s", desc)
+				}
+				found[name] = true
+			}
+		}
+	}
+	if len(found) != 2 {
+		t.Errorf("expected 2 by-tag metric descriptions, got %d", len(found))
+	}
+}
+
 func TestCollectorCollect_DatabaseConnectionFailure(t *testing.T) {
 	logger := promslog.NewNopLogger()
 	config := &Config{
@@ -82,7 +115,7 @@ func TestCollectorCollect_DatabaseConnectionFailure(t *testing.T) {
 	collector := NewCollector(logger, config)
 
 	// Mock the openDatabase function to return an error
-	collector.openDatabase = func(c *Config) (*sql.DB, error) {
+	collector.openDatabase = func(c *Config, httpPath string) (*sql.DB, error) {
 		return nil, errors.New("connection failed")
 	}
 
@@ -129,7 +162,7 @@ func TestCollectorMetricNames(t *testing.T) {
 	collector := NewCollector(logger, config)
 
 	// Mock the openDatabase function to prevent actual connection attempts
-	collector.openDatabase = func(c *Config) (*sql.DB, error) {
+	collector.openDatabase = func(c *Config, httpPath string) (*sql.DB, error) {
 		return nil, errors.New("mocked error - no actual connection")
 	}
 
@@ -174,7 +207,7 @@ func TestOpenDatabricksDatabase_ValidatesConnection(t *testing.T) {
 
 	// This will attempt to create a connector but should return without error
 	// The actual connection/authentication will fail when the DB is used
-	db, err := openDatabricksDatabase(config)
+	db, err := openDatabricksDatabase(config, config.WarehouseHTTPPath)
 
 	// We expect no error during connector creation (errors happen on use)
 	if err != nil {
@@ -185,3 +218,21 @@ func TestOpenDatabricksDatabase_ValidatesConnection(t *testing.T) {
 		db.Close()
 	}
 }
+
+func TestApplyPoolSettings(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	applyPoolSettings(db, &Config{MaxOpenConns: 3})
+	if got := db.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("expected max open connections 3, got %d", got)
+	}
+
+	applyPoolSettings(db, &Config{})
+	if got := db.Stats().MaxOpenConnections; got != DefaultMaxOpenConns {
+		t.Errorf("expected default max open connections %d, got %d", DefaultMaxOpenConns, got)
+	}
+}
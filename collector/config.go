@@ -2,6 +2,10 @@ package collector
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -15,15 +19,70 @@ const (
 // Lookback windows are sized to prevent data loss with scrape intervals up to 30 minutes.
 // Formula: lookback >= scrape_interval + max_data_lag + buffer
 const (
+	DefaultPort                = 443
 	DefaultQueryTimeout        = 5 * time.Minute
 	DefaultBillingLookback     = 24 * time.Hour // Daily aggregation, 24-48h data lag
 	DefaultJobsLookback        = 3 * time.Hour  // 1-5 min data lag, 30min scrape buffer
+	DefaultJobsActiveLookback  = 48 * time.Hour // Oldest start of a run in progress that is reported
 	DefaultPipelinesLookback   = 3 * time.Hour  // 1-5 min data lag, 30min scrape buffer
 	DefaultQueriesLookback     = 2 * time.Hour  // 5-15 min data lag, 30min scrape buffer
 	DefaultSLAThresholdSeconds = SecondsPerHour
 	DefaultTableCheckInterval  = 10 // Number of scrapes between table availability checks
+	DefaultFailoverThreshold   = 3  // Consecutive connection failures before switching warehouse
+	DefaultFailbackInterval    = 10 * time.Minute
+	DefaultHealthCheckInterval = 1 * time.Minute // Background readiness check interval
+
+	DefaultJobSLATag = "sla_seconds" // Job tag holding a per-job SLA threshold in seconds
+
+	DefaultBillingAttributionLimit = 100        // Series per usage_metadata key before rolling up into "__other__"
+	DefaultBillingIdentityLimit    = 10         // Identities per workspace before rolling up into "__other__"
+	DefaultBillingAnomalyWeeks     = 4          // Same-weekday days in the cost anomaly baseline
+	DefaultBillingAnomalyOffset    = 2          // Days before today of the scored day; billing lags 24-48h
+	DefaultQueryCostUserLimit      = 10         // Users per warehouse before rolling up into "__other__"
+	DefaultBillingTagPlaceholder   = "untagged" // Label value for usage without an allowlisted tag
+	DefaultBillingCostMode         = BillingCostModeCurrent
+	DefaultBillingTimezone         = "UTC" // Time zone for calendar-aligned billing periods
+)
+
+// Default connection pool settings.
+const (
+	DefaultMaxOpenConns    = 10              // Limit concurrent connections to avoid overwhelming Databricks
+	DefaultMaxIdleConns    = 5               // Keep some connections warm, up to the open connection limit (when unset)
+	DefaultConnMaxLifetime = 5 * time.Minute // Recycle connections every 5 minutes
+	DefaultConnMaxIdleTime = 1 * time.Minute // Close idle connections after 1 minute
+
+	// NoIdleConns as MaxIdleConns closes connections as soon as they are released. An unset
+	// MaxIdleConns keeps min(DefaultMaxIdleConns, max open connections) idle instead, so lowering
+	// MaxOpenConns alone never conflicts with the idle limit.
+	NoIdleConns = -1
+)
+
+// Session parameter keys understood by Databricks SQL.
+const (
+	sessionParamTimezone         = "timezone"
+	sessionParamStatementTimeout = "STATEMENT_TIMEOUT"
+)
+
+// Billing cost modes, selecting how usage is joined to list prices.
+const (
+	BillingCostModeCurrent    = "current"    // Current list prices only (cheap; wrong for usage before a price change)
+	BillingCostModeHistorical = "historical" // Price effective at usage_start_time (accurate; joins every price window)
 )
 
+// DefaultJobDurationBuckets are the upper bounds, in seconds, of the job run duration histogram
+// buckets: from one minute to a day.
+var DefaultJobDurationBuckets = []float64{60, 300, 600, 1800, 3600, 7200, 14400, 28800, 86400}
+
+// DefaultJobPhaseDurationBuckets are the upper bounds, in seconds, of the job and task run phase
+// duration histogram buckets: from ten seconds, for queueing and setup, to a day.
+var DefaultJobPhaseDurationBuckets = []float64{10, 30, 60, 120, 300, 600, 1800, 3600, 14400, 86400}
+
+// billingAttributionKeys are the usage_metadata fields that billing can be attributed by, in query order.
+var billingAttributionKeys = []string{"job_id", "warehouse_id", "cluster_id", "dlt_pipeline_id", "endpoint_name"}
+
+// attributionOther is the attribution value that usage beyond a key's series limit is rolled up into.
+const attributionOther = "__other__"
+
 // Config holds the configuration for the Databricks exporter.
 type Config struct {
 	// Exporter metadata
@@ -34,18 +93,124 @@ type Config struct {
 	WarehouseHTTPPath string
 	ClientID          string
 	ClientSecret      string
+	Port              int // Server port for the SQL connector (default: 443)
+
+	// Warehouse failover settings
+	FailoverWarehouseHTTPPaths []string      // Ordered fallback warehouses used when WarehouseHTTPPath is unreachable
+	FailoverThreshold          int           // Consecutive connection failures before moving to the next warehouse
+	FailbackInterval           time.Duration // How often to retry the primary warehouse while failed over
+
+	// Warehouse routing: collector name (billing, jobs, pipelines, queries) or query name
+	// (e.g. billing_cost, queries_running) to the HTTP path of a dedicated warehouse.
+	// Query routes take precedence over collector routes; unrouted queries use the primary.
+	WarehouseRoutes map[string]string
+
+	// Outbound transport settings (applied to the SQL connector and the OAuth token client)
+	ProxyURL    string // HTTP(S) proxy URL; empty falls back to HTTPS_PROXY/NO_PROXY environment variables
+	TLSCAFile   string // PEM bundle of extra CAs trusted in addition to the system roots
+	TLSCertFile string // PEM client certificate for mutual TLS
+	TLSKeyFile  string // PEM private key for TLSCertFile
+
+	// Connection pool settings
+	MaxOpenConns    int           // Maximum open connections to the warehouse
+	MaxIdleConns    int           // Maximum idle connections kept in the pool (0 derives it from MaxOpenConns, NoIdleConns keeps none)
+	ConnMaxLifetime time.Duration // Maximum lifetime of a pooled connection
+	ConnMaxIdleTime time.Duration // Maximum idle time of a pooled connection
+
+	// Session settings (applied when each connection opens its session)
+	Catalog          string            // Initial catalog; queries use fully qualified system.* names regardless
+	Schema           string            // Initial schema within Catalog
+	SessionTimezone  string            // Session time zone (IANA name, e.g. "UTC")
+	StatementTimeout time.Duration     // Server-side statement timeout (STATEMENT_TIMEOUT)
+	SessionParams    map[string]string // Additional Spark/SQL session confs; dedicated fields above take precedence
 
 	// Query settings
 	QueryTimeout time.Duration // Timeout for individual database queries
 
+	// Readiness settings
+	HealthCheckInterval time.Duration // How often the background readiness check fetches a token and rechecks the last scrape's warehouse connections
+
 	// Lookback windows for different metric domains
 	BillingLookback   time.Duration // How far back to look for billing data
 	JobsLookback      time.Duration // How far back to look for job runs
 	PipelinesLookback time.Duration // How far back to look for pipeline runs
 	QueriesLookback   time.Duration // How far back to look for SQL warehouse queries
 
-	// SLA settings
+	// Job runs in progress: runs that started within JobsActiveLookback and are still reported by
+	// the timeline, or with LiveJobRuns, the active runs of the connected workspace from the Jobs API.
+	// LiveJobRunsWorkspaceID labels live runs when the API response does not carry the workspace ID.
+	JobsActiveLookback     time.Duration
+	LiveJobRuns            bool
+	LiveJobRunsWorkspaceID string
+
+	// Billing cost settings
+	BillingCostMode  string          // How usage is joined to list prices: current (default) or historical
+	PricingOverrides []PriceOverride // Negotiated prices (see LoadPricingOverrides); enables effective cost metrics
+	FXRates          *FXRates        // Converts list prices into a reporting currency (see LoadFXRates)
+
+	// Calendar-aligned billing (today, yesterday, month-to-date, previous month)
+	CollectBillingCalendar bool   // Collect calendar-aligned billing metrics (scans up to two months of usage)
+	BillingTimezone        string // IANA time zone for period boundaries (default: UTC)
+
+	// Breakdown by billing origin product (jobs, DLT, SQL, model serving, ...)
+	CollectBillingByProduct bool
+
+	// Serverless and classic compute split, by billing origin product and Photon use
+	CollectBillingByCompute bool
+
+	// Spend by run_as or owner identity: the top BillingIdentityLimit identities per workspace
+	// keep their own series (0 uses the default), the rest are rolled up into "__other__".
+	CollectBillingByIdentity bool
+	BillingIdentityLimit     int
+
+	// Cost anomaly detection: the cost per workspace and SKU on the day BillingAnomalyOffset days
+	// before today against the median of the same weekday over the previous BillingAnomalyWeeks
+	// weeks (0 uses the default for either)
+	CollectBillingAnomaly bool
+	BillingAnomalyWeeks   int
+	BillingAnomalyOffset  int
+
+	// Idle SQL warehouse usage: billed warehouse usage with no query activity, over the billing window
+	CollectWarehouseIdle bool
+
+	// Per-query cost attribution: warehouse cost apportioned to queries by execution time, per
+	// user (top QueryCostUserLimit per warehouse, 0 uses the default) and per query source
+	CollectQueryCost   bool
+	QueryCostUserLimit int
+
+	// Job and pipeline cost: billing usage attributed through usage_metadata.job_id and
+	// dlt_pipeline_id over the billing window, with cost per successful run or update
+	CollectJobCost      bool
+	CollectPipelineCost bool
+
+	// Budgets (see LoadBudgets); month boundaries follow BillingTimezone
+	Budgets []Budget
+
+	// Billing attribution: enabled usage_metadata keys (job_id, warehouse_id, cluster_id,
+	// dlt_pipeline_id, endpoint_name) and the maximum series each may produce per workspace (0 uses the default).
+	BillingAttributionLimits map[string]int
+
+	// Cost allocation by custom_tags: each allowlisted tag key becomes a tag_<key> label on the
+	// by-tag billing metrics. Usage without the tag reports BillingTagPlaceholder.
+	BillingTagKeys        []string
+	BillingTagPlaceholder string
+
+	// SLA settings: a job's threshold is the first matching JobSLAs entry (see LoadJobSLAs), else
+	// its JobSLATag tag (empty uses the default), else SLAThresholdSeconds
 	SLAThresholdSeconds int // Duration threshold (in seconds) for SLA miss detection
+	JobSLAs             []JobSLA
+	JobSLATag           string
+
+	// Job run duration: histogram bucket upper bounds in seconds (nil uses DefaultJobDurationBuckets),
+	// and the p50/p95/p99 gauges kept for dashboards and alerts that still use them
+	JobDurationBuckets          []float64
+	CollectJobDurationQuantiles bool
+
+	// Queue, setup, execution and cleanup durations of job runs and of task runs (one histogram per
+	// phase and job or task), with bucket upper bounds in seconds (nil uses DefaultJobPhaseDurationBuckets)
+	CollectJobPhaseDurations  bool
+	CollectTaskPhaseDurations bool
+	JobPhaseDurationBuckets   []float64
 
 	// Cardinality controls
 	CollectTaskRetries bool // Collect task retry metrics (high cardinality due to task_key)
@@ -59,21 +224,55 @@ var (
 	errNoWarehouseHTTPPath = errors.New("warehouse_http_path must be specified")
 	errNoClientID          = errors.New("client_id must be specified")
 	errNoClientSecret      = errors.New("client_secret must be specified")
+	errInvalidPort         = errors.New("port must be between 1 and 65535")
+	errInvalidProxyURL     = errors.New("proxy_url must be an absolute http or https URL")
+	errIncompleteClientTLS = errors.New("tls_cert_file and tls_key_file must be specified together")
+	errInvalidPoolSize     = errors.New("max_open_conns must not be negative and max_idle_conns must be -1 (none) or more")
+	errIdleExceedsOpen     = errors.New("max_idle_conns must not exceed max_open_conns")
+	errInvalidConnLifetime = errors.New("conn_max_lifetime and conn_max_idle_time must not be negative")
+	errInvalidStmtTimeout  = errors.New("statement_timeout must be a non-negative whole number of seconds")
+	errEmptySessionParam   = errors.New("session parameter names must not be empty")
+	errInvalidFailoverPath = errors.New("failover warehouse http paths must be non-empty and distinct from each other and the primary")
+	errInvalidFailover     = errors.New("failover_threshold and failback_interval must not be negative")
+	errEmptyWarehouseRoute = errors.New("warehouse routes must specify an http path")
+	errInvalidHealthCheck  = errors.New("health_check_interval must not be negative")
+	errInvalidWorkspaceID  = errors.New("jobs_live_workspace_id must be a numeric workspace ID")
+	errInvalidAttribution  = errors.New("billing attribution limits must not be negative")
+	errIdentityLimit       = errors.New("billing_identity_limit must not be negative")
+	errAnomalyWeeks        = errors.New("billing_anomaly_weeks must not be negative")
+	errAnomalyOffset       = errors.New("billing_anomaly_offset_days must not be negative")
+	errQueryCostUserLimit  = errors.New("query_cost_user_limit must not be negative")
+	errJobDurationBuckets  = errors.New("job duration buckets must be positive and increasing")
+	errEmptyBillingTagKey  = errors.New("billing tag keys must not be empty")
+	errInvalidCostMode     = errors.New("billing_cost_mode must be current or historical")
 )
 
 // DefaultConfig returns a Config with all default values set.
 // Useful for tests that don't need specific config values.
 func DefaultConfig() *Config {
 	return &Config{
-		Version:             "unknown", // Set by main.go from build info
-		QueryTimeout:        DefaultQueryTimeout,
-		BillingLookback:     DefaultBillingLookback,
-		JobsLookback:        DefaultJobsLookback,
-		PipelinesLookback:   DefaultPipelinesLookback,
-		QueriesLookback:     DefaultQueriesLookback,
-		SLAThresholdSeconds: DefaultSLAThresholdSeconds,
-		CollectTaskRetries:  false,
-		TableCheckInterval:  DefaultTableCheckInterval,
+		Version:               "unknown", // Set by main.go from build info
+		Port:                  DefaultPort,
+		MaxOpenConns:          DefaultMaxOpenConns,
+		MaxIdleConns:          0, // Derived from MaxOpenConns
+		ConnMaxLifetime:       DefaultConnMaxLifetime,
+		ConnMaxIdleTime:       DefaultConnMaxIdleTime,
+		QueryTimeout:          DefaultQueryTimeout,
+		BillingLookback:       DefaultBillingLookback,
+		JobsLookback:          DefaultJobsLookback,
+		JobsActiveLookback:    DefaultJobsActiveLookback,
+		PipelinesLookback:     DefaultPipelinesLookback,
+		QueriesLookback:       DefaultQueriesLookback,
+		SLAThresholdSeconds:   DefaultSLAThresholdSeconds,
+		JobSLATag:             DefaultJobSLATag,
+		CollectTaskRetries:    false,
+		TableCheckInterval:    DefaultTableCheckInterval,
+		FailoverThreshold:     DefaultFailoverThreshold,
+		FailbackInterval:      DefaultFailbackInterval,
+		HealthCheckInterval:   DefaultHealthCheckInterval,
+		BillingCostMode:       DefaultBillingCostMode,
+		BillingTimezone:       DefaultBillingTimezone,
+		BillingTagPlaceholder: DefaultBillingTagPlaceholder,
 	}
 }
 
@@ -94,5 +293,248 @@ func (c Config) Validate() error {
 		return errNoClientSecret
 	}
 
+	// Zero means "use the default port"
+	if c.Port < 0 || c.Port > 65535 {
+		return errInvalidPort
+	}
+
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errInvalidProxyURL
+		}
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errIncompleteClientTLS
+	}
+
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < NoIdleConns {
+		return errInvalidPoolSize
+	}
+
+	if maxOpen, maxIdle := c.poolSize(); maxIdle > maxOpen {
+		return errIdleExceedsOpen
+	}
+
+	if c.ConnMaxLifetime < 0 || c.ConnMaxIdleTime < 0 {
+		return errInvalidConnLifetime
+	}
+
+	if c.StatementTimeout < 0 || c.StatementTimeout%time.Second != 0 {
+		return errInvalidStmtTimeout
+	}
+
+	if c.SessionTimezone != "" {
+		if _, err := time.LoadLocation(c.SessionTimezone); err != nil {
+			return fmt.Errorf("invalid session_timezone %q: %w", c.SessionTimezone, err)
+		}
+	}
+
+	for name := range c.SessionParams {
+		if strings.TrimSpace(name) == "" {
+			return errEmptySessionParam
+		}
+	}
+
+	seen := map[string]bool{c.WarehouseHTTPPath: true}
+	for _, path := range c.FailoverWarehouseHTTPPaths {
+		if path == "" || seen[path] {
+			return errInvalidFailoverPath
+		}
+		seen[path] = true
+	}
+
+	if c.FailoverThreshold < 0 || c.FailbackInterval < 0 {
+		return errInvalidFailover
+	}
+
+	for key, path := range c.WarehouseRoutes {
+		if !isRouteKey(key) {
+			return fmt.Errorf("unknown warehouse route %q: must be a collector or query name", key)
+		}
+		if path == "" {
+			return errEmptyWarehouseRoute
+		}
+	}
+
+	if c.HealthCheckInterval < 0 {
+		return errInvalidHealthCheck
+	}
+
+	if c.LiveJobRunsWorkspaceID != "" && strings.Trim(c.LiveJobRunsWorkspaceID, "0123456789") != "" {
+		return errInvalidWorkspaceID
+	}
+
+	if c.BillingTimezone != "" {
+		if _, err := time.LoadLocation(c.BillingTimezone); err != nil {
+			return fmt.Errorf("invalid billing_timezone %q: %w", c.BillingTimezone, err)
+		}
+	}
+
+	switch c.BillingCostMode {
+	case "", BillingCostModeCurrent, BillingCostModeHistorical:
+	default:
+		return errInvalidCostMode
+	}
+
+	if c.FXRates != nil {
+		if err := c.FXRates.validate(); err != nil {
+			return fmt.Errorf("invalid FX rates: %w", err)
+		}
+	}
+
+	if err := validateJobSLAs(c.JobSLAs); err != nil {
+		return fmt.Errorf("invalid job SLAs: %w", err)
+	}
+
+	if err := validateBudgets(c.Budgets); err != nil {
+		return fmt.Errorf("invalid budgets: %w", err)
+	}
+
+	if _, err := newPriceBook(c.PricingOverrides); err != nil {
+		return fmt.Errorf("invalid pricing overrides: %w", err)
+	}
+	if err := checkOverrideCurrencies(c.PricingOverrides, c.reportingCurrency()); err != nil {
+		return fmt.Errorf("invalid pricing overrides: %w", err)
+	}
+
+	for key, limit := range c.BillingAttributionLimits {
+		if !slices.Contains(billingAttributionKeys, key) {
+			return fmt.Errorf("unknown billing attribution key %q: must be one of %s", key, strings.Join(billingAttributionKeys, ", "))
+		}
+		if limit < 0 {
+			return errInvalidAttribution
+		}
+	}
+
+	if c.BillingIdentityLimit < 0 {
+		return errIdentityLimit
+	}
+
+	if c.BillingAnomalyWeeks < 0 {
+		return errAnomalyWeeks
+	}
+
+	if c.BillingAnomalyOffset < 0 {
+		return errAnomalyOffset
+	}
+
+	if c.QueryCostUserLimit < 0 {
+		return errQueryCostUserLimit
+	}
+
+	for _, buckets := range [][]float64{c.JobDurationBuckets, c.JobPhaseDurationBuckets} {
+		for i, bound := range buckets {
+			if bound <= 0 || (i > 0 && bound <= buckets[i-1]) {
+				return errJobDurationBuckets
+			}
+		}
+	}
+
+	tagLabels := make(map[string]string, len(c.BillingTagKeys))
+	for _, key := range c.BillingTagKeys {
+		if strings.TrimSpace(key) == "" {
+			return errEmptyBillingTagKey
+		}
+		label := tagLabelName(key)
+		if other, ok := tagLabels[label]; ok {
+			return fmt.Errorf("billing tag keys %q and %q both map to label %s", other, key, label)
+		}
+		tagLabels[label] = key
+	}
+
 	return nil
 }
+
+// warehouseHTTPPaths returns the primary warehouse followed by the failover warehouses, in order.
+func (c Config) warehouseHTTPPaths() []string {
+	return append([]string{c.WarehouseHTTPPath}, c.FailoverWarehouseHTTPPaths...)
+}
+
+// poolSize returns the maximum open and idle connections, applying defaults for unset values.
+func (c Config) poolSize() (maxOpen, maxIdle int) {
+	maxOpen = c.MaxOpenConns
+	if maxOpen == 0 {
+		maxOpen = DefaultMaxOpenConns
+	}
+	switch c.MaxIdleConns {
+	case 0:
+		maxIdle = min(DefaultMaxIdleConns, maxOpen)
+	case NoIdleConns:
+		maxIdle = 0
+	default:
+		maxIdle = c.MaxIdleConns
+	}
+	return maxOpen, maxIdle
+}
+
+// billingCostMode returns the configured billing cost mode, or the default when unset.
+func (c Config) billingCostMode() string {
+	if c.BillingCostMode == "" {
+		return DefaultBillingCostMode
+	}
+	return c.BillingCostMode
+}
+
+// reportingCurrency returns the currency that costs are converted into: the FX rates
+// reporting currency, or USD without rates.
+func (c Config) reportingCurrency() string {
+	if c.FXRates == nil {
+		return defaultCurrencyCode
+	}
+	return c.FXRates.ReportingCurrency
+}
+
+// billingLocation returns the time zone for calendar-aligned billing periods.
+func (c Config) billingLocation() (*time.Location, error) {
+	if c.BillingTimezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.BillingTimezone)
+}
+
+// tagLabelName returns the Prometheus label for a custom tag key: "tag_" followed by the
+// key with every character that is not valid in a label name replaced by an underscore.
+func tagLabelName(key string) string {
+	var b strings.Builder
+	b.WriteString("tag_")
+	for _, r := range key {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// sessionParams returns the session configuration passed to Databricks at connect time.
+// Dedicated settings override entries with the same key in SessionParams.
+func (c Config) sessionParams() map[string]string {
+	params := make(map[string]string, len(c.SessionParams)+2)
+	for name, value := range c.SessionParams {
+		params[name] = value
+	}
+
+	if c.SessionTimezone != "" {
+		setSessionParam(params, sessionParamTimezone, c.SessionTimezone)
+	}
+
+	if c.StatementTimeout > 0 {
+		setSessionParam(params, sessionParamStatementTimeout, fmt.Sprintf("%d", int(c.StatementTimeout.Seconds())))
+	}
+
+	return params
+}
+
+// setSessionParam sets name to value, replacing any existing key that differs only in case.
+// The driver only recognizes the lowercase "timezone" key for result time conversion.
+func setSessionParam(params map[string]string, name, value string) {
+	for existing := range params {
+		if strings.EqualFold(existing, name) {
+			delete(params, existing)
+		}
+	}
+	params[name] = value
+}
@@ -2,6 +2,10 @@ package collector
 
 import (
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfigValidate(t *testing.T) {
@@ -105,6 +109,398 @@ func TestConfigValidate(t *testing.T) {
 			expectError: true,
 			expectedErr: errNoClientSecret,
 		},
+		{
+			name: "valid transport options",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				Port:              8443,
+				ProxyURL:          "http://proxy.internal:3128",
+				TLSCertFile:       "/etc/exporter/client.pem",
+				TLSKeyFile:        "/etc/exporter/client-key.pem",
+			},
+			expectError: false,
+		},
+		{
+			name: "port out of range",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				Port:              70000,
+			},
+			expectError: true,
+			expectedErr: errInvalidPort,
+		},
+		{
+			name: "proxy url without scheme",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				ProxyURL:          "proxy.internal:3128",
+			},
+			expectError: true,
+			expectedErr: errInvalidProxyURL,
+		},
+		{
+			name: "client cert without key",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				TLSCertFile:       "/etc/exporter/client.pem",
+			},
+			expectError: true,
+			expectedErr: errIncompleteClientTLS,
+		},
+		{
+			name: "valid pool and session settings",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				MaxOpenConns:      20,
+				MaxIdleConns:      10,
+				ConnMaxLifetime:   10 * time.Minute,
+				SessionTimezone:   "Europe/Berlin",
+				StatementTimeout:  10 * time.Minute,
+				SessionParams:     map[string]string{"ansi_mode": "true"},
+			},
+			expectError: false,
+		},
+		{
+			name: "negative max open conns",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				MaxOpenConns:      -1,
+			},
+			expectError: true,
+			expectedErr: errInvalidPoolSize,
+		},
+		{
+			name: "idle conns exceed open conns",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				MaxOpenConns:      2,
+				MaxIdleConns:      5,
+			},
+			expectError: true,
+			expectedErr: errIdleExceedsOpen,
+		},
+		{
+			name: "derived idle conns follow a low open limit",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				MaxOpenConns:      3,
+			},
+			expectError: false,
+		},
+		{
+			name: "idle conns exceed the default open conns",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				MaxIdleConns:      DefaultMaxOpenConns + 1,
+			},
+			expectError: true,
+			expectedErr: errIdleExceedsOpen,
+		},
+		{
+			name: "idle conns below -1",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				MaxIdleConns:      -2,
+			},
+			expectError: true,
+			expectedErr: errInvalidPoolSize,
+		},
+		{
+			name: "negative conn lifetime",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				ConnMaxIdleTime:   -time.Second,
+			},
+			expectError: true,
+			expectedErr: errInvalidConnLifetime,
+		},
+		{
+			name: "fractional statement timeout",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				StatementTimeout:  1500 * time.Millisecond,
+			},
+			expectError: true,
+			expectedErr: errInvalidStmtTimeout,
+		},
+		{
+			name: "empty session param name",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				SessionParams:     map[string]string{" ": "x"},
+			},
+			expectError: true,
+			expectedErr: errEmptySessionParam,
+		},
+		{
+			name: "valid failover warehouses",
+			config: Config{
+				ServerHostname:             "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath:          "/sql/1.0/warehouses/abc123",
+				ClientID:                   "test-client-id",
+				ClientSecret:               "test-client-secret",
+				FailoverWarehouseHTTPPaths: []string{"/sql/1.0/warehouses/def456"},
+				FailoverThreshold:          5,
+				FailbackInterval:           time.Hour,
+			},
+			expectError: false,
+		},
+		{
+			name: "failover warehouse duplicates primary",
+			config: Config{
+				ServerHostname:             "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath:          "/sql/1.0/warehouses/abc123",
+				ClientID:                   "test-client-id",
+				ClientSecret:               "test-client-secret",
+				FailoverWarehouseHTTPPaths: []string{"/sql/1.0/warehouses/abc123"},
+			},
+			expectError: true,
+			expectedErr: errInvalidFailoverPath,
+		},
+		{
+			name: "negative failover threshold",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				FailoverThreshold: -1,
+			},
+			expectError: true,
+			expectedErr: errInvalidFailover,
+		},
+		{
+			name: "valid warehouse routes",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				WarehouseRoutes: map[string]string{
+					"billing":         "/sql/1.0/warehouses/serverless",
+					"queries_running": "/sql/1.0/warehouses/serverless",
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "warehouse route without path",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				WarehouseRoutes:   map[string]string{"jobs": ""},
+			},
+			expectError: true,
+			expectedErr: errEmptyWarehouseRoute,
+		},
+		{
+			name: "negative health check interval",
+			config: Config{
+				ServerHostname:      "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath:   "/sql/1.0/warehouses/abc123",
+				ClientID:            "test-client-id",
+				ClientSecret:        "test-client-secret",
+				HealthCheckInterval: -time.Second,
+			},
+			expectError: true,
+			expectedErr: errInvalidHealthCheck,
+		},
+		{
+			name: "valid live job runs workspace ID",
+			config: Config{
+				ServerHostname:         "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath:      "/sql/1.0/warehouses/abc123",
+				ClientID:               "test-client-id",
+				ClientSecret:           "test-client-secret",
+				LiveJobRuns:            true,
+				LiveJobRunsWorkspaceID: "1234567890123456",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid live job runs workspace ID",
+			config: Config{
+				ServerHostname:         "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath:      "/sql/1.0/warehouses/abc123",
+				ClientID:               "test-client-id",
+				ClientSecret:           "test-client-secret",
+				LiveJobRuns:            true,
+				LiveJobRunsWorkspaceID: "dbc-abc123",
+			},
+			expectError: true,
+			expectedErr: errInvalidWorkspaceID,
+		},
+		{
+			name: "historical billing cost mode",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				BillingCostMode:   BillingCostModeHistorical,
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid billing cost mode",
+			config: Config{
+				ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+				ClientID:          "test-client-id",
+				ClientSecret:      "test-client-secret",
+				BillingCostMode:   "accurate",
+			},
+			expectError: true,
+			expectedErr: errInvalidCostMode,
+		},
+		{
+			name: "valid billing attribution limits",
+			config: Config{
+				ServerHostname:           "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath:        "/sql/1.0/warehouses/abc123",
+				ClientID:                 "test-client-id",
+				ClientSecret:             "test-client-secret",
+				BillingAttributionLimits: map[string]int{"job_id": 50, "endpoint_name": 0},
+			},
+			expectError: false,
+		},
+		{
+			name: "negative billing attribution limit",
+			config: Config{
+				ServerHostname:           "dbc-abc123-def456.cloud.databricks.com",
+				WarehouseHTTPPath:        "/sql/1.0/warehouses/abc123",
+				ClientID:                 "test-client-id",
+				ClientSecret:             "test-client-secret",
+				BillingAttributionLimits: map[string]int{"cluster_id": -1},
+			},
+			expectError: true,
+			expectedErr: errInvalidAttribution,
+		},
+		{
+			name: "negative query cost user limit",
+			config: Config{
+				ServerHostname:     "test.cloud.databricks.com",
+				WarehouseHTTPPath:  "/sql/1.0/warehouses/abc123",
+				ClientID:           "test-client-id",
+				ClientSecret:       "test-client-secret",
+				QueryCostUserLimit: -1,
+			},
+			expectError: true,
+			expectedErr: errQueryCostUserLimit,
+		},
+		{
+			name: "non-increasing job duration buckets",
+			config: Config{
+				ServerHostname:     "test.cloud.databricks.com",
+				WarehouseHTTPPath:  "/sql/1.0/warehouses/abc123",
+				ClientID:           "test-client-id",
+				ClientSecret:       "test-client-secret",
+				JobDurationBuckets: []float64{60, 600, 600},
+			},
+			expectError: true,
+			expectedErr: errJobDurationBuckets,
+		},
+		{
+			name: "non-positive job duration bucket",
+			config: Config{
+				ServerHostname:     "test.cloud.databricks.com",
+				WarehouseHTTPPath:  "/sql/1.0/warehouses/abc123",
+				ClientID:           "test-client-id",
+				ClientSecret:       "test-client-secret",
+				JobDurationBuckets: []float64{0, 60},
+			},
+			expectError: true,
+			expectedErr: errJobDurationBuckets,
+		},
+		{
+			name: "non-increasing job phase duration buckets",
+			config: Config{
+				ServerHostname:          "test.cloud.databricks.com",
+				WarehouseHTTPPath:       "/sql/1.0/warehouses/abc123",
+				ClientID:                "test-client-id",
+				ClientSecret:            "test-client-secret",
+				JobPhaseDurationBuckets: []float64{60, 30},
+			},
+			expectError: true,
+			expectedErr: errJobDurationBuckets,
+		},
+		{
+			name: "negative billing anomaly weeks",
+			config: Config{
+				ServerHostname:      "test.cloud.databricks.com",
+				WarehouseHTTPPath:   "/sql/1.0/warehouses/abc123",
+				ClientID:            "test-client-id",
+				ClientSecret:        "test-client-secret",
+				BillingAnomalyWeeks: -1,
+			},
+			expectError: true,
+			expectedErr: errAnomalyWeeks,
+		},
+		{
+			name: "negative billing anomaly offset",
+			config: Config{
+				ServerHostname:       "test.cloud.databricks.com",
+				WarehouseHTTPPath:    "/sql/1.0/warehouses/abc123",
+				ClientID:             "test-client-id",
+				ClientSecret:         "test-client-secret",
+				BillingAnomalyOffset: -1,
+			},
+			expectError: true,
+			expectedErr: errAnomalyOffset,
+		},
+		{
+			name: "negative billing identity limit",
+			config: Config{
+				ServerHostname:       "test.cloud.databricks.com",
+				WarehouseHTTPPath:    "/sql/1.0/warehouses/abc123",
+				ClientID:             "test-client-id",
+				ClientSecret:         "test-client-secret",
+				BillingIdentityLimit: -1,
+			},
+			expectError: true,
+			expectedErr: errIdentityLimit,
+		},
 		{
 			name: "all fields empty",
 			config: Config{
@@ -172,3 +568,218 @@ func TestConfigValidationOrder(t *testing.T) {
 		t.Errorf("expected no error after all fields set, got %v", err)
 	}
 }
+
+func TestConfigValidate_InvalidSessionTimezone(t *testing.T) {
+	config := Config{
+		ServerHostname:    "test.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/test",
+		ClientID:          "test-id",
+		ClientSecret:      "test-secret",
+		SessionTimezone:   "Mars/Olympus_Mons",
+	}
+
+	err := config.Validate()
+	require.Error(t, err, "expected error for unknown time zone")
+	assert.Contains(t, err.Error(), "session_timezone")
+}
+
+func TestConfigValidate_InvalidBillingTimezone(t *testing.T) {
+	config := Config{
+		ServerHostname:    "test.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/test",
+		ClientID:          "test-id",
+		ClientSecret:      "test-secret",
+		BillingTimezone:   "Mars/Olympus_Mons",
+	}
+
+	err := config.Validate()
+	require.Error(t, err, "expected error for unknown time zone")
+	assert.Contains(t, err.Error(), "billing_timezone")
+}
+
+func TestConfigPoolSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		maxOpen int
+		maxIdle int
+	}{
+		{"zero value", Config{}, DefaultMaxOpenConns, DefaultMaxIdleConns},
+		{"default config", *DefaultConfig(), DefaultMaxOpenConns, DefaultMaxIdleConns},
+		{"derived idle is capped by open", Config{MaxOpenConns: 3}, 3, 3},
+		{"no idle connections", Config{MaxOpenConns: 3, MaxIdleConns: NoIdleConns}, 3, 0},
+		{"explicit idle", Config{MaxOpenConns: 20, MaxIdleConns: 8}, 20, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxOpen, maxIdle := tt.config.poolSize()
+			assert.Equal(t, tt.maxOpen, maxOpen)
+			assert.Equal(t, tt.maxIdle, maxIdle)
+		})
+	}
+}
+
+func TestConfigSessionParams(t *testing.T) {
+	config := Config{
+		SessionTimezone:  "UTC",
+		StatementTimeout: 2 * time.Minute,
+		SessionParams: map[string]string{
+			"TimeZone":          "America/New_York",
+			"statement_timeout": "10",
+			"ansi_mode":         "false",
+		},
+	}
+
+	params := config.sessionParams()
+
+	assert.Equal(t, map[string]string{
+		"timezone":          "UTC",
+		"STATEMENT_TIMEOUT": "120",
+		"ansi_mode":         "false",
+	}, params, "dedicated settings should override session params case-insensitively")
+	assert.Equal(t, "America/New_York", config.SessionParams["TimeZone"], "config map must not be mutated")
+
+	assert.Empty(t, Config{}.sessionParams(), "expected no session params by default")
+}
+
+func TestConfigValidate_UnknownWarehouseRoute(t *testing.T) {
+	config := Config{
+		ServerHostname:    "test.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/test",
+		ClientID:          "test-id",
+		ClientSecret:      "test-secret",
+		WarehouseRoutes:   map[string]string{"billing_everything": "/sql/1.0/warehouses/other"},
+	}
+
+	err := config.Validate()
+	require.Error(t, err, "expected error for unknown route key")
+	assert.Contains(t, err.Error(), "billing_everything")
+}
+
+func TestConfigValidate_UnknownBillingAttributionKey(t *testing.T) {
+	config := Config{
+		ServerHostname:           "dbc-abc123-def456.cloud.databricks.com",
+		WarehouseHTTPPath:        "/sql/1.0/warehouses/abc123",
+		ClientID:                 "test-client-id",
+		ClientSecret:             "test-client-secret",
+		BillingAttributionLimits: map[string]int{"notebook_id": 10},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notebook_id")
+}
+
+func TestConfigValidate_BillingTagKeys(t *testing.T) {
+	base := Config{
+		ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+		ClientID:          "test-client-id",
+		ClientSecret:      "test-client-secret",
+	}
+
+	valid := base
+	valid.BillingTagKeys = []string{"team", "cost-center", "project"}
+	assert.NoError(t, valid.Validate())
+
+	empty := base
+	empty.BillingTagKeys = []string{"team", " "}
+	assert.ErrorIs(t, empty.Validate(), errEmptyBillingTagKey)
+
+	// "cost-center" and "cost.center" both become tag_cost_center
+	colliding := base
+	colliding.BillingTagKeys = []string{"cost-center", "cost.center"}
+	err := colliding.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tag_cost_center")
+}
+
+func TestTagLabelName(t *testing.T) {
+	assert.Equal(t, "tag_team", tagLabelName("team"))
+	assert.Equal(t, "tag_cost_center", tagLabelName("cost-center"))
+	assert.Equal(t, "tag_Project_Name", tagLabelName("Project Name"))
+}
+
+func TestConfigValidate_JobSLAs(t *testing.T) {
+	config := Config{
+		ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+		ClientID:          "test-client-id",
+		ClientSecret:      "test-client-secret",
+		JobSLAs:           []JobSLA{{JobID: "42", ThresholdSeconds: -1}},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid job SLAs")
+	assert.ErrorIs(t, err, errJobSLAThreshold)
+}
+
+func TestConfigValidate_Budgets(t *testing.T) {
+	config := Config{
+		ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+		ClientID:          "test-client-id",
+		ClientSecret:      "test-client-secret",
+		Budgets:           []Budget{{Name: "account", Amount: 0}},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid budgets")
+	assert.ErrorIs(t, err, errBudgetAmount)
+}
+
+func TestConfigValidate_FXRates(t *testing.T) {
+	config := Config{
+		ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+		ClientID:          "test-client-id",
+		ClientSecret:      "test-client-secret",
+		FXRates:           &FXRates{Rates: map[string]float64{"USD": 0.92}},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid FX rates")
+	assert.ErrorIs(t, err, errFXReportingCurrency)
+}
+
+func TestConfigValidate_PricingOverrides(t *testing.T) {
+	config := Config{
+		ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+		ClientID:          "test-client-id",
+		ClientSecret:      "test-client-secret",
+		PricingOverrides: []PriceOverride{
+			{SKU: "PREMIUM_JOBS_COMPUTE", DiscountPercent: float64Ptr(10), EffectiveFrom: "2026-01-01"},
+			{SKU: "PREMIUM_JOBS_COMPUTE", DiscountPercent: float64Ptr(15), EffectiveFrom: "2026-02-01"},
+		},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid pricing overrides")
+	assert.Contains(t, err.Error(), "overlap")
+}
+
+func TestConfigValidate_PricingOverrideCurrency(t *testing.T) {
+	config := Config{
+		ServerHostname:    "dbc-abc123-def456.cloud.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/abc123",
+		ClientID:          "test-client-id",
+		ClientSecret:      "test-client-secret",
+		PricingOverrides: []PriceOverride{
+			{SKU: "PREMIUM_SERVERLESS_SQL", UnitPrice: float64Ptr(0.5), Currency: "EUR", EffectiveFrom: "2026-01-01"},
+		},
+	}
+
+	// Unit prices are in USD without FX rates
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unit_price currency EUR must be the reporting currency USD")
+
+	config.FXRates = &FXRates{ReportingCurrency: "EUR", Rates: map[string]float64{"USD": 0.92}}
+	require.NoError(t, config.Validate())
+}
@@ -0,0 +1,190 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthStaleIntervals is how many check intervals may pass without a completed check
+// before /-/ready reports the last result as stale.
+const healthStaleIntervals = 2
+
+// CheckResult is the outcome of a single readiness check.
+type CheckResult struct {
+	OK       bool       `json:"ok"`
+	Error    string     `json:"error,omitempty"`
+	LastUsed *time.Time `json:"last_used,omitempty"` // Warehouses only: when a scrape last connected, nil before the first
+}
+
+// HealthStatus is the result of the most recent background readiness check.
+type HealthStatus struct {
+	Ready             bool        `json:"ready"`
+	CheckedAt         *time.Time  `json:"checked_at,omitempty"` // nil until the first check completes
+	Stale             bool        `json:"stale,omitempty"`      // No check completed within healthStaleIntervals
+	Auth              CheckResult `json:"auth"`
+	Warehouse         CheckResult `json:"warehouse"`
+	WarehouseHTTPPath string      `json:"warehouse_http_path,omitempty"`
+
+	// Warehouses from --warehouse-route, keyed by HTTP path
+	RoutedWarehouses map[string]CheckResult `json:"routed_warehouses,omitempty"`
+}
+
+// healthState holds the last readiness check result, shared between the
+// background checker and the HTTP handlers.
+type healthState struct {
+	mu       sync.RWMutex
+	status   HealthStatus
+	interval time.Duration // Background check interval, 0 until checks start
+}
+
+// get returns the last check result, marked not ready once it is older than
+// healthStaleIntervals check intervals (for example when a check hangs).
+func (h *healthState) get() HealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	status := h.status
+	if status.CheckedAt == nil || h.interval == 0 {
+		return status
+	}
+	if time.Since(*status.CheckedAt) > healthStaleIntervals*h.interval {
+		status.Ready = false
+		status.Stale = true
+	}
+	return status
+}
+
+func (h *healthState) setInterval(interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.interval = interval
+}
+
+func (h *healthState) set(status HealthStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = status
+}
+
+// newAuthCheck returns a function that fetches (or refreshes) an OAuth token using
+// the same transport settings as the SQL connector. Tokens are cached between calls.
+func newAuthCheck(config *Config) func() error {
+	var transport *http.Transport
+	var transportErr error
+	if config.hasCustomTransport() {
+		transport, transportErr = newHTTPTransport(config)
+	}
+	authenticator := newConfiguredAuthenticator(config, transport)
+
+	return func() error {
+		if transportErr != nil {
+			return transportErr
+		}
+		// Authenticate only sets the Authorization header, so no URL is needed
+		return authenticator.Authenticate(&http.Request{Header: http.Header{}})
+	}
+}
+
+// RunHealthChecks checks authentication every interval until ctx is cancelled, together with
+// the outcome of the last scrape's warehouse connections. Readiness handlers report the result
+// of the last check.
+func (c *Collector) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	if interval == 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	c.health.setInterval(interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.checkHealth()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkHealth runs one readiness check and stores the result.
+func (c *Collector) checkHealth() {
+	status := HealthStatus{Auth: CheckResult{OK: true}, Warehouse: CheckResult{OK: true}}
+
+	if err := c.checkAuth(); err != nil {
+		c.logger.Warn("Readiness check: OAuth token fetch failed", "err", err)
+		status.Auth = CheckResult{Error: err.Error()}
+	}
+
+	// Warehouses are not pinged, since a ping every interval would keep an idle warehouse from
+	// auto-stopping. Readiness follows the connection made by the last scrape instead.
+	path, result := warehouseResult(c.pool)
+	status.Warehouse = result
+	status.WarehouseHTTPPath = path
+	status.Ready = status.Auth.OK && status.Warehouse.OK
+
+	// Queries routed to another warehouse fail when it is down, so it must be reachable too
+	for routedPath, pool := range c.routed {
+		if status.RoutedWarehouses == nil {
+			status.RoutedWarehouses = make(map[string]CheckResult, len(c.routed))
+		}
+		_, result := warehouseResult(pool)
+		if !result.OK {
+			status.Ready = false
+		}
+		status.RoutedWarehouses[routedPath] = result
+	}
+
+	now := time.Now()
+	status.CheckedAt = &now
+	c.health.set(status)
+}
+
+// warehouseResult reports the last scrape's connection to the active warehouse of pool. A
+// warehouse no scrape has used yet is reported as OK.
+func warehouseResult(pool *warehousePool) (string, CheckResult) {
+	path, lastUsed, err := pool.lastResult()
+	if lastUsed.IsZero() {
+		return path, CheckResult{OK: true}
+	}
+	if err != nil {
+		return path, CheckResult{Error: err.Error(), LastUsed: &lastUsed}
+	}
+	return path, CheckResult{OK: true, LastUsed: &lastUsed}
+}
+
+// Health returns the result of the last background readiness check.
+func (c *Collector) Health() HealthStatus {
+	return c.health.get()
+}
+
+// HealthyHandler reports that the exporter process is alive. It never queries Databricks.
+func HealthyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
+	})
+}
+
+// ReadyHandler reports readiness from the last background check, returning
+// 503 with the check details while authentication or a warehouse is failing,
+// or when the last check is stale.
+func (c *Collector) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := c.Health()
+		code := http.StatusOK
+		if !status.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, status)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,247 @@
+package collector
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/common/promslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHealthTestCollector(t *testing.T, authErr error, db *sql.DB, dbErr error) *Collector {
+	t.Helper()
+	config := &Config{
+		ServerHostname:    "test.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/test",
+		ClientID:          "test-id",
+		ClientSecret:      "test-secret",
+	}
+	collector := NewCollector(promslog.NewNopLogger(), config)
+	collector.checkAuth = func() error { return authErr }
+	collector.openDatabase = func(*Config, string) (*sql.DB, error) { return db, dbErr }
+	return collector
+}
+
+func getReady(t *testing.T, collector *Collector) (int, HealthStatus) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	collector.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/-/ready", nil))
+
+	var status HealthStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	return rec.Code, status
+}
+
+func TestHealthyHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HealthyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/-/healthy", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"healthy"}`, rec.Body.String())
+}
+
+func TestReadyHandler_NotReadyBeforeFirstCheck(t *testing.T) {
+	collector := newHealthTestCollector(t, nil, nil, errors.New("unused"))
+
+	code, status := getReady(t, collector)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, status.Ready)
+	assert.Nil(t, status.CheckedAt)
+}
+
+func TestReadyHandler_Ready(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectPing()
+
+	collector := newHealthTestCollector(t, nil, db, nil)
+	_, err = collector.getDB() // A scrape
+	require.NoError(t, err)
+	_, err = collector.getDB() // Pings the pool opened by the first scrape
+	require.NoError(t, err)
+	collector.checkHealth()
+
+	code, status := getReady(t, collector)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, status.Ready)
+	assert.True(t, status.Auth.OK)
+	assert.True(t, status.Warehouse.OK)
+	assert.NotNil(t, status.Warehouse.LastUsed)
+	assert.Equal(t, "/sql/1.0/warehouses/test", status.WarehouseHTTPPath)
+	assert.NotNil(t, status.CheckedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReadyHandler_AuthFailure(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	collector := newHealthTestCollector(t, errors.New("failed to fetch OAuth token: invalid_client"), db, nil)
+	collector.checkHealth()
+
+	code, status := getReady(t, collector)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, status.Ready)
+	assert.False(t, status.Auth.OK)
+	assert.Contains(t, status.Auth.Error, "invalid_client")
+	assert.True(t, status.Warehouse.OK)
+}
+
+func TestReadyHandler_WarehouseFailure(t *testing.T) {
+	collector := newHealthTestCollector(t, nil, nil, errors.New("warehouse stopped"))
+	_, err := collector.getDB()
+	require.Error(t, err)
+	collector.checkHealth()
+
+	code, status := getReady(t, collector)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, status.Auth.OK)
+	assert.False(t, status.Warehouse.OK)
+	assert.Contains(t, status.Warehouse.Error, "warehouse stopped")
+	assert.NotNil(t, status.Warehouse.LastUsed)
+}
+
+func TestReadyHandler_RecoversOnNextCheck(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	authErr := errors.New("token endpoint unreachable")
+	collector := newHealthTestCollector(t, nil, db, nil)
+	collector.checkAuth = func() error { return authErr }
+
+	collector.checkHealth()
+	code, _ := getReady(t, collector)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	authErr = nil
+	collector.checkHealth()
+	code, status := getReady(t, collector)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, status.Ready)
+}
+
+func TestReadyHandler_StaleCheck(t *testing.T) {
+	collector := newHealthTestCollector(t, nil, nil, nil)
+	collector.health.setInterval(time.Minute)
+
+	recent := time.Now().Add(-90 * time.Second)
+	collector.health.set(HealthStatus{Ready: true, CheckedAt: &recent})
+	code, status := getReady(t, collector)
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, status.Stale)
+
+	// The background check has not completed for more than two intervals
+	old := time.Now().Add(-3 * time.Minute)
+	collector.health.set(HealthStatus{Ready: true, CheckedAt: &old})
+	code, status = getReady(t, collector)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, status.Ready)
+	assert.True(t, status.Stale)
+}
+
+func TestCheckHealth_DoesNotWakeIdleWarehouse(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	opens := 0
+	collector := newHealthTestCollector(t, nil, db, nil)
+	collector.openDatabase = func(*Config, string) (*sql.DB, error) {
+		opens++
+		return db, nil
+	}
+
+	// Before the first scrape, the check neither opens a connection nor pings
+	collector.checkHealth()
+	assert.Zero(t, opens)
+	status := collector.Health()
+	assert.True(t, status.Ready)
+	assert.True(t, status.Warehouse.OK)
+	assert.Nil(t, status.Warehouse.LastUsed)
+
+	// After a scrape opened the pool, repeated checks leave the idle connection alone
+	_, err = collector.getDB()
+	require.NoError(t, err)
+	for range 3 {
+		collector.checkHealth()
+	}
+	assert.Equal(t, 1, opens)
+	require.NoError(t, mock.ExpectationsWereMet(), "readiness checks must not ping the warehouse")
+}
+
+func TestCheckHealth_DoesNotFailOver(t *testing.T) {
+	collector := newHealthTestCollector(t, nil, nil, errors.New("warehouse unreachable"))
+	collector.config.FailoverWarehouseHTTPPaths = []string{"/sql/1.0/warehouses/fallback"}
+	collector.config.FailoverThreshold = 2
+	collector.pool = newWarehousePool(collector.config.warehouseHTTPPaths(), collector.config, collector.logger, collector.openDatabase)
+
+	_, err := collector.getDB()
+	require.Error(t, err)
+	collector.checkHealth()
+	collector.checkHealth()
+
+	status := collector.Health()
+	assert.False(t, status.Warehouse.OK)
+	assert.Equal(t, "/sql/1.0/warehouses/test", status.WarehouseHTTPPath)
+	path, role := collector.pool.activeWarehouse()
+	assert.Equal(t, "/sql/1.0/warehouses/test", path, "readiness checks must not trigger failover")
+	assert.Equal(t, warehouseRolePrimary, role)
+}
+
+func TestCheckHealth_RoutedWarehouses(t *testing.T) {
+	primary, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primary.Close()
+
+	config := &Config{
+		ServerHostname:    "test.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/test",
+		ClientID:          "test-id",
+		ClientSecret:      "test-secret",
+		WarehouseRoutes:   map[string]string{"billing": "/sql/1.0/warehouses/billing"},
+	}
+	collector := NewCollector(promslog.NewNopLogger(), config)
+	collector.checkAuth = func() error { return nil }
+	collector.openDatabase = func(_ *Config, path string) (*sql.DB, error) {
+		if path == "/sql/1.0/warehouses/billing" {
+			return nil, errors.New("warehouse stopped")
+		}
+		return primary, nil
+	}
+	_, err = collector.getDB()
+	require.NoError(t, err)
+	_, err = collector.routed["/sql/1.0/warehouses/billing"].get()
+	require.Error(t, err)
+	collector.checkHealth()
+
+	code, status := getReady(t, collector)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, status.Warehouse.OK)
+	require.Contains(t, status.RoutedWarehouses, "/sql/1.0/warehouses/billing")
+	assert.False(t, status.RoutedWarehouses["/sql/1.0/warehouses/billing"].OK)
+	assert.Contains(t, status.RoutedWarehouses["/sql/1.0/warehouses/billing"].Error, "warehouse stopped")
+}
+
+func TestNewAuthCheck_InvalidTransport(t *testing.T) {
+	check := newAuthCheck(&Config{
+		ServerHostname: "test.databricks.com",
+		ClientID:       "test-id",
+		ClientSecret:   "test-secret",
+		TLSCAFile:      "/nonexistent/ca.pem",
+	})
+
+	err := check()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CA file")
+}
@@ -13,18 +13,20 @@ import (
 // JobsCollector collects job-related metrics from Databricks.
 type JobsCollector struct {
 	logger *slog.Logger
-	db     *sql.DB
+	router *queryRouter // Resolves the warehouse for each query
 	ctx    context.Context
 	config *Config
 
+	jobsAPI *jobsAPIClient // Lists active runs instead of the run timeline when set
+
 	metrics *MetricDescriptors
 }
 
 // NewJobsCollector creates a new JobsCollector.
-func NewJobsCollector(ctx context.Context, db *sql.DB, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *JobsCollector {
+func NewJobsCollector(ctx context.Context, router *queryRouter, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *JobsCollector {
 	return &JobsCollector{
 		logger:  logger,
-		db:      db,
+		router:  router,
 		metrics: metrics,
 		ctx:     ctx,
 		config:  config,
@@ -35,10 +37,21 @@ func NewJobsCollector(ctx context.Context, db *sql.DB, metrics *MetricDescriptor
 func (c *JobsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.metrics.JobRuns
 	ch <- c.metrics.JobRunStatus
+	ch <- c.metrics.JobRunTerminations
+	ch <- c.metrics.JobRunTerminationsByWorkspace
 	ch <- c.metrics.JobRunDurationSeconds
+	ch <- c.metrics.JobRunDurationHistogram
 	ch <- c.metrics.TaskRetries
 	ch <- c.metrics.JobSLAMiss
+	ch <- c.metrics.JobSLAThreshold
+	ch <- c.metrics.JobRunPhaseDurationHistogram
+	ch <- c.metrics.TaskRunPhaseDurationHistogram
+	ch <- c.metrics.JobRunsActive
+	ch <- c.metrics.JobRunOldestActiveAge
+	ch <- c.metrics.JobCostEstimate
+	ch <- c.metrics.JobCostPerSuccessfulRun
 	ch <- c.metrics.ScrapeStatus
+	ch <- c.metrics.QueryScrapeDuration
 }
 
 // Collect fetches metrics from Databricks and sends them to Prometheus.
@@ -58,11 +71,39 @@ func (c *JobsCollector) Collect(ch chan<- prometheus.Metric) {
 		hasError = true
 	}
 
-	if err := c.collectJobRunDuration(ch); err != nil {
-		c.logger.Error("Failed to collect job run duration", "err", err)
+	if err := c.collectJobRunTerminations(ch); err != nil {
+		c.logger.Error("Failed to collect job run terminations", "err", err)
+		hasError = true
+	}
+
+	if err := c.collectJobRunDurationHistogram(ch); err != nil {
+		c.logger.Error("Failed to collect job run duration histogram", "err", err)
 		hasError = true
 	}
 
+	// Quantile gauges cannot be aggregated across jobs; kept for compatibility with older dashboards
+	if c.config.CollectJobDurationQuantiles {
+		if err := c.collectJobRunDuration(ch); err != nil {
+			c.logger.Error("Failed to collect job run duration", "err", err)
+			hasError = true
+		}
+	}
+
+	// Phase durations add one histogram per phase and job (or task), so they are opt-in
+	if c.config.CollectJobPhaseDurations {
+		if err := c.collectJobRunPhaseDurations(ch); err != nil {
+			c.logger.Error("Failed to collect job run phase durations", "err", err)
+			hasError = true
+		}
+	}
+
+	if c.config.CollectTaskPhaseDurations {
+		if err := c.collectTaskRunPhaseDurations(ch); err != nil {
+			c.logger.Error("Failed to collect task run phase durations", "err", err)
+			hasError = true
+		}
+	}
+
 	if err := c.collectTaskRetries(ch); err != nil {
 		c.logger.Error("Failed to collect task retries", "err", err)
 		hasError = true
@@ -73,12 +114,25 @@ func (c *JobsCollector) Collect(ch chan<- prometheus.Metric) {
 		hasError = true
 	}
 
+	if err := c.collectJobRunsActive(ch); err != nil {
+		c.logger.Error("Failed to collect active job runs", "err", err)
+		hasError = true
+	}
+
+	// Job cost scans billing usage over the billing window, so it is opt-in
+	if c.config.CollectJobCost {
+		if err := c.collectJobCost(ch); err != nil {
+			c.logger.Error("Failed to collect job cost", "err", err)
+			hasError = true
+		}
+	}
+
 	// Emit scrape status
 	status := 1.0
 	if hasError {
 		status = 0.0
 	}
-	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, "jobs")
+	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, collectorJobs)
 
 	c.logger.Debug("Finished collecting job metrics", "duration_seconds", time.Since(start).Seconds())
 }
@@ -90,7 +144,7 @@ func (c *JobsCollector) collectJobRuns(ch chan<- prometheus.Metric) error {
 		lookback = DefaultJobsLookback
 	}
 	query := BuildJobRunsQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryJobRuns, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute job runs query: %w", err)
 	}
@@ -126,7 +180,7 @@ func (c *JobsCollector) collectJobRunStatus(ch chan<- prometheus.Metric) error {
 		lookback = DefaultJobsLookback
 	}
 	query := BuildJobRunStatusQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryJobRunStatus, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute job run status query: %w", err)
 	}
@@ -156,6 +210,61 @@ func (c *JobsCollector) collectJobRunStatus(ch chan<- prometheus.Metric) error {
 	return rows.Err()
 }
 
+// collectJobRunTerminations collects the number of job runs per job and termination code, and
+// their sum per workspace and termination code.
+func (c *JobsCollector) collectJobRunTerminations(ch chan<- prometheus.Metric) error {
+	lookback := c.config.JobsLookback
+	if lookback == 0 {
+		lookback = DefaultJobsLookback
+	}
+	query := BuildJobRunTerminationsQuery(lookback)
+	rows, err := c.router.query(c.ctx, ch, queryJobTerminations, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute job run terminations query: %w", err)
+	}
+	defer rows.Close()
+
+	type workspaceCode struct{ workspaceID, code string }
+	rollup := make(map[workspaceCode]float64)
+
+	for rows.Next() {
+		var workspaceID, jobID, jobName, code sql.NullString
+		var count sql.NullFloat64
+
+		if err := rows.Scan(&workspaceID, &jobID, &jobName, &code, &count); err != nil {
+			return fmt.Errorf("failed to scan job run terminations row: %w", err)
+		}
+
+		if count.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				c.metrics.JobRunTerminations,
+				prometheus.GaugeValue,
+				count.Float64,
+				workspaceID.String,
+				jobID.String,
+				jobName.String,
+				code.String,
+			)
+			rollup[workspaceCode{workspaceID.String, code.String}] += count.Float64
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for key, count := range rollup {
+		ch <- prometheus.MustNewConstMetric(
+			c.metrics.JobRunTerminationsByWorkspace,
+			prometheus.GaugeValue,
+			count,
+			key.workspaceID,
+			key.code,
+		)
+	}
+
+	return nil
+}
+
 // collectJobRunDuration collects job run duration quantiles per job.
 func (c *JobsCollector) collectJobRunDuration(ch chan<- prometheus.Metric) error {
 	lookback := c.config.JobsLookback
@@ -163,7 +272,7 @@ func (c *JobsCollector) collectJobRunDuration(ch chan<- prometheus.Metric) error
 		lookback = DefaultJobsLookback
 	}
 	query := BuildJobRunDurationQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryJobRunDuration, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute job run duration query: %w", err)
 	}
@@ -215,6 +324,143 @@ func (c *JobsCollector) collectJobRunDuration(ch chan<- prometheus.Metric) error
 	return rows.Err()
 }
 
+// collectJobRunDurationHistogram collects the distribution of job run durations per job.
+func (c *JobsCollector) collectJobRunDurationHistogram(ch chan<- prometheus.Metric) error {
+	lookback := c.config.JobsLookback
+	if lookback == 0 {
+		lookback = DefaultJobsLookback
+	}
+	bounds := c.config.JobDurationBuckets
+	if len(bounds) == 0 {
+		bounds = DefaultJobDurationBuckets
+	}
+	query := BuildJobRunDurationHistogramQuery(lookback, bounds)
+	rows, err := c.router.query(c.ctx, ch, queryJobRunHistogram, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute job run duration histogram query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// workspace_id, job_id, job_name
+		row, err := scanHistogramRow(rows, 3, bounds)
+		if err != nil {
+			return fmt.Errorf("failed to scan job run duration histogram row: %w", err)
+		}
+		ch <- row.metric(c.metrics.JobRunDurationHistogram)
+	}
+
+	return rows.Err()
+}
+
+// collectJobRunPhaseDurations collects the distribution of job run queue, setup, execution and
+// cleanup durations per job and phase.
+func (c *JobsCollector) collectJobRunPhaseDurations(ch chan<- prometheus.Metric) error {
+	lookback := c.config.JobsLookback
+	if lookback == 0 {
+		lookback = DefaultJobsLookback
+	}
+	bounds := c.jobPhaseDurationBuckets()
+	query := BuildJobRunPhaseDurationHistogramQuery(lookback, bounds)
+	rows, err := c.router.query(c.ctx, ch, queryJobRunPhases, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute job run phase duration query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// workspace_id, job_id, job_name, phase
+		row, err := scanHistogramRow(rows, 4, bounds)
+		if err != nil {
+			return fmt.Errorf("failed to scan job run phase duration row: %w", err)
+		}
+		ch <- row.metric(c.metrics.JobRunPhaseDurationHistogram)
+	}
+
+	return rows.Err()
+}
+
+// collectTaskRunPhaseDurations collects the distribution of task run setup, execution and cleanup
+// durations per job, task key and phase.
+func (c *JobsCollector) collectTaskRunPhaseDurations(ch chan<- prometheus.Metric) error {
+	lookback := c.config.JobsLookback
+	if lookback == 0 {
+		lookback = DefaultJobsLookback
+	}
+	bounds := c.jobPhaseDurationBuckets()
+	query := BuildTaskRunPhaseDurationHistogramQuery(lookback, bounds)
+	rows, err := c.router.query(c.ctx, ch, queryTaskRunPhases, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute task run phase duration query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		// workspace_id, job_id, job_name, task_key, phase
+		row, err := scanHistogramRow(rows, 5, bounds)
+		if err != nil {
+			return fmt.Errorf("failed to scan task run phase duration row: %w", err)
+		}
+		ch <- row.metric(c.metrics.TaskRunPhaseDurationHistogram)
+	}
+
+	return rows.Err()
+}
+
+// jobPhaseDurationBuckets returns the configured phase duration bucket upper bounds.
+func (c *JobsCollector) jobPhaseDurationBuckets() []float64 {
+	if len(c.config.JobPhaseDurationBuckets) == 0 {
+		return DefaultJobPhaseDurationBuckets
+	}
+	return c.config.JobPhaseDurationBuckets
+}
+
+// histogramRow is one row of a histogram query (see histogramColumns).
+type histogramRow struct {
+	labels  []string
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64 // Cumulative counts by upper bound
+}
+
+// scanHistogramRow scans a histogram query row with numLabels label columns before the bucket columns.
+func scanHistogramRow(rows *sql.Rows, numLabels int, bounds []float64) (histogramRow, error) {
+	labels := make([]sql.NullString, numLabels)
+	cumulative := make([]sql.NullFloat64, len(bounds))
+	var count, sum sql.NullFloat64
+
+	dest := make([]any, 0, numLabels+len(bounds)+2)
+	for i := range labels {
+		dest = append(dest, &labels[i])
+	}
+	for i := range cumulative {
+		dest = append(dest, &cumulative[i])
+	}
+	dest = append(dest, &count, &sum)
+	if err := rows.Scan(dest...); err != nil {
+		return histogramRow{}, err
+	}
+
+	row := histogramRow{
+		labels:  make([]string, numLabels),
+		count:   uint64(count.Float64),
+		sum:     sum.Float64,
+		buckets: make(map[float64]uint64, len(bounds)),
+	}
+	for i, label := range labels {
+		row.labels[i] = label.String
+	}
+	for i, bound := range bounds {
+		row.buckets[bound] = uint64(cumulative[i].Float64)
+	}
+	return row, nil
+}
+
+// metric returns the row as a histogram of desc.
+func (r histogramRow) metric(desc *prometheus.Desc) prometheus.Metric {
+	return prometheus.MustNewConstHistogram(desc, r.count, r.sum, r.buckets, r.labels...)
+}
+
 // collectTaskRetries collects the total number of task retries per job and task.
 func (c *JobsCollector) collectTaskRetries(ch chan<- prometheus.Metric) error {
 	// Skip task retries if disabled (high cardinality due to task_key label)
@@ -227,7 +473,7 @@ func (c *JobsCollector) collectTaskRetries(ch chan<- prometheus.Metric) error {
 		lookback = DefaultJobsLookback
 	}
 	query := BuildTaskRetriesQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryTaskRetries, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute task retries query: %w", err)
 	}
@@ -257,7 +503,7 @@ func (c *JobsCollector) collectTaskRetries(ch chan<- prometheus.Metric) error {
 	return rows.Err()
 }
 
-// collectJobSLAMiss collects the number of jobs that missed their SLA per job.
+// collectJobSLAMiss collects the SLA threshold of each job and the number of its runs that missed it.
 func (c *JobsCollector) collectJobSLAMiss(ch chan<- prometheus.Metric) error {
 	lookback := c.config.JobsLookback
 	if lookback == 0 {
@@ -267,8 +513,12 @@ func (c *JobsCollector) collectJobSLAMiss(ch chan<- prometheus.Metric) error {
 	if slaThreshold == 0 {
 		slaThreshold = DefaultSLAThresholdSeconds
 	}
-	query := BuildJobSLAMissQuery(lookback, slaThreshold)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	tagKey := c.config.JobSLATag
+	if tagKey == "" {
+		tagKey = DefaultJobSLATag
+	}
+	query := BuildJobSLAMissQuery(lookback, slaThreshold, c.config.JobSLAs, tagKey)
+	rows, err := c.router.query(c.ctx, ch, queryJobSLAMiss, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute job SLA miss query: %w", err)
 	}
@@ -276,13 +526,25 @@ func (c *JobsCollector) collectJobSLAMiss(ch chan<- prometheus.Metric) error {
 
 	for rows.Next() {
 		var workspaceID, jobID, jobName sql.NullString
-		var count sql.NullFloat64
+		var threshold, count sql.NullFloat64
 
-		if err := rows.Scan(&workspaceID, &jobID, &jobName, &count); err != nil {
+		if err := rows.Scan(&workspaceID, &jobID, &jobName, &threshold, &count); err != nil {
 			return fmt.Errorf("failed to scan job SLA miss row: %w", err)
 		}
 
-		if count.Valid {
+		if threshold.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				c.metrics.JobSLAThreshold,
+				prometheus.GaugeValue,
+				threshold.Float64,
+				workspaceID.String,
+				jobID.String,
+				jobName.String,
+			)
+		}
+
+		// Jobs without a miss in the window have no miss series
+		if count.Valid && count.Float64 > 0 {
 			ch <- prometheus.MustNewConstMetric(
 				c.metrics.JobSLAMiss,
 				prometheus.GaugeValue,
@@ -296,3 +558,140 @@ func (c *JobsCollector) collectJobSLAMiss(ch chan<- prometheus.Metric) error {
 
 	return rows.Err()
 }
+
+// collectJobRunsActive collects the number of runs in progress and the age of the oldest one per
+// job, from the Jobs API when live runs are enabled and from the run timeline otherwise.
+func (c *JobsCollector) collectJobRunsActive(ch chan<- prometheus.Metric) error {
+	if c.jobsAPI != nil {
+		return c.collectLiveJobRunsActive(ch)
+	}
+
+	lookback := c.config.JobsLookback
+	if lookback == 0 {
+		lookback = DefaultJobsLookback
+	}
+	activeLookback := c.config.JobsActiveLookback
+	if activeLookback == 0 {
+		activeLookback = DefaultJobsActiveLookback
+	}
+	query := BuildJobRunsActiveQuery(lookback, activeLookback)
+	rows, err := c.router.query(c.ctx, ch, queryJobRunsActive, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute active job runs query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var workspaceID, jobID, jobName sql.NullString
+		var count, oldestAge sql.NullFloat64
+
+		if err := rows.Scan(&workspaceID, &jobID, &jobName, &count, &oldestAge); err != nil {
+			return fmt.Errorf("failed to scan active job runs row: %w", err)
+		}
+
+		if count.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				c.metrics.JobRunsActive,
+				prometheus.GaugeValue,
+				count.Float64,
+				workspaceID.String,
+				jobID.String,
+				jobName.String,
+			)
+		}
+		if oldestAge.Valid {
+			ch <- prometheus.MustNewConstMetric(
+				c.metrics.JobRunOldestActiveAge,
+				prometheus.GaugeValue,
+				oldestAge.Float64,
+				workspaceID.String,
+				jobID.String,
+				jobName.String,
+			)
+		}
+	}
+
+	return rows.Err()
+}
+
+// collectLiveJobRunsActive collects active run metrics from the Jobs API of the connected workspace.
+func (c *JobsCollector) collectLiveJobRunsActive(ch chan<- prometheus.Metric) error {
+	workspaceID, runs, err := c.jobsAPI.listActiveRuns(c.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list active job runs: %w", err)
+	}
+
+	type jobKey struct{ id, name string }
+	counts := make(map[jobKey]int)
+	oldest := make(map[jobKey]time.Time)
+	for _, r := range runs {
+		key := jobKey{r.jobID, r.jobName}
+		counts[key]++
+		if start, ok := oldest[key]; !ok || r.startTime.Before(start) {
+			oldest[key] = r.startTime
+		}
+	}
+
+	now := time.Now()
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.metrics.JobRunsActive,
+			prometheus.GaugeValue,
+			float64(count),
+			workspaceID,
+			key.id,
+			key.name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.metrics.JobRunOldestActiveAge,
+			prometheus.GaugeValue,
+			max(now.Sub(oldest[key]).Seconds(), 0),
+			workspaceID,
+			key.id,
+			key.name,
+		)
+	}
+
+	return nil
+}
+
+// collectJobCost collects estimated cost per job and its cost per successful run over the billing window.
+func (c *JobsCollector) collectJobCost(ch chan<- prometheus.Metric) error {
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildJobCostQuery(lookback, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryJobCost, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute job cost query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var workspaceID, jobID, jobName, currencyCode sql.NullString
+		var cost, successfulRuns sql.NullFloat64
+
+		if err := rows.Scan(&workspaceID, &jobID, &jobName, &currencyCode, &cost, &successfulRuns); err != nil {
+			return fmt.Errorf("failed to scan job cost row: %w", err)
+		}
+		if !cost.Valid {
+			continue
+		}
+
+		labels := []string{workspaceID.String, jobID.String, jobName.String, currencyCode.String}
+		ch <- prometheus.MustNewConstMetric(c.metrics.JobCostEstimate, prometheus.GaugeValue, cost.Float64, labels...)
+
+		// Without a successful run in the window there is nothing to divide by
+		if successfulRuns.Float64 > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.metrics.JobCostPerSuccessfulRun,
+				prometheus.GaugeValue,
+				cost.Float64/successfulRuns.Float64,
+				labels...,
+			)
+		}
+	}
+
+	return rows.Err()
+}
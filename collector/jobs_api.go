@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/databricks/databricks-sql-go/auth"
+)
+
+const (
+	jobsAPIRunsListPath = "/api/2.1/jobs/runs/list"
+	jobsAPIJobsGetPath  = "/api/2.1/jobs/get"
+	jobsAPIPageLimit    = 25  // Maximum runs per runs/list page
+	jobsAPIMaxPages     = 100 // Stops paging through runs after this many pages
+	jobsAPIMaxErrorBody = 4096
+	jobsAPITimeout      = 30 * time.Second // Bounds each request, including reading the response
+	jobsAPINameTTL      = time.Hour        // How long a job name from jobs/get is reused
+
+	// workspaceIDHeader carries the workspace ID on Databricks REST API responses.
+	workspaceIDHeader = "X-Databricks-Org-Id"
+)
+
+// activeJobRun is a job run in progress, as listed by the Jobs API. jobID is empty for one-time
+// runs submitted without a job.
+type activeJobRun struct {
+	jobID     string
+	jobName   string
+	startTime time.Time
+}
+
+// runsListResponse is the part of a Jobs API runs/list response the exporter reads.
+type runsListResponse struct {
+	Runs []struct {
+		JobID     int64  `json:"job_id"`     // Zero for one-time runs submitted without a job
+		RunName   string `json:"run_name"`   // The job name when the run was triggered, unless submitted with another
+		StartTime int64  `json:"start_time"` // Epoch milliseconds
+	} `json:"runs"`
+	HasMore       bool   `json:"has_more"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+// jobsGetResponse is the part of a Jobs API jobs/get response the exporter reads.
+type jobsGetResponse struct {
+	Settings struct {
+		Name string `json:"name"`
+	} `json:"settings"`
+}
+
+// cachedJobName is a job name from jobs/get and when it was fetched.
+type cachedJobName struct {
+	name      string
+	fetchedAt time.Time
+}
+
+// jobsAPIClient lists job runs through the Jobs REST API of the workspace the exporter connects
+// to, with the same OAuth credentials and transport settings as the SQL connector.
+type jobsAPIClient struct {
+	baseURL       string
+	workspaceID   string // Reported when responses do not carry the workspace ID header
+	httpClient    *http.Client
+	authenticator auth.Authenticator
+	logger        *slog.Logger
+	err           error // Transport configuration error, returned on every call
+
+	mu       sync.Mutex
+	jobNames map[string]cachedJobName // By job ID, refreshed after jobsAPINameTTL
+}
+
+// newJobsAPIClient creates a Jobs API client for config's server hostname. Tokens and job names
+// are cached between calls, so the client should be reused across scrapes.
+func newJobsAPIClient(config *Config, logger *slog.Logger) *jobsAPIClient {
+	var transport *http.Transport
+	var err error
+	if config.hasCustomTransport() {
+		transport, err = newHTTPTransport(config)
+	}
+
+	httpClient := &http.Client{Timeout: jobsAPITimeout}
+	if transport != nil {
+		httpClient.Transport = transport
+	}
+
+	return &jobsAPIClient{
+		baseURL:       "https://" + config.ServerHostname,
+		workspaceID:   config.LiveJobRunsWorkspaceID,
+		httpClient:    httpClient,
+		authenticator: newConfiguredAuthenticator(config, transport),
+		logger:        logger,
+		err:           err,
+		jobNames:      make(map[string]cachedJobName),
+	}
+}
+
+// listActiveRuns returns the workspace ID and the runs in progress (pending, queued, running or
+// terminating) of every job in the workspace. Runs of a job are named after the job's current
+// name, so renamed jobs and runs triggered with another name report a single series per job.
+func (c *jobsAPIClient) listActiveRuns(ctx context.Context) (string, []activeJobRun, error) {
+	if c.err != nil {
+		return "", nil, c.err
+	}
+
+	workspaceID := c.workspaceID
+	var runs []activeJobRun
+	pageToken := ""
+	for range jobsAPIMaxPages {
+		params := url.Values{
+			"active_only": {"true"},
+			"limit":       {strconv.Itoa(jobsAPIPageLimit)},
+		}
+		if pageToken != "" {
+			params.Set("page_token", pageToken)
+		}
+
+		var page runsListResponse
+		header, err := c.get(ctx, jobsAPIRunsListPath, params, &page)
+		if err != nil {
+			return "", nil, err
+		}
+		if id := header.Get(workspaceIDHeader); id != "" {
+			workspaceID = id
+		}
+
+		for _, r := range page.Runs {
+			run := activeJobRun{jobName: r.RunName, startTime: time.UnixMilli(r.StartTime)}
+			if r.JobID != 0 {
+				run.jobID = strconv.FormatInt(r.JobID, 10)
+				run.jobName = c.jobName(ctx, run.jobID, r.RunName)
+			}
+			if run.jobName == "" {
+				run.jobName = "job-" + run.jobID
+			}
+			runs = append(runs, run)
+		}
+
+		if !page.HasMore || page.NextPageToken == "" {
+			return workspaceID, runs, nil
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return "", nil, fmt.Errorf("jobs API %s: more than %d pages of active runs", jobsAPIRunsListPath, jobsAPIMaxPages)
+}
+
+// jobName returns the current name of a job from jobs/get, cached for jobsAPINameTTL. When the
+// lookup fails, the run name is returned and the lookup is retried on the next call.
+func (c *jobsAPIClient) jobName(ctx context.Context, jobID, runName string) string {
+	c.mu.Lock()
+	cached, ok := c.jobNames[jobID]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < jobsAPINameTTL {
+		return cached.name
+	}
+
+	var job jobsGetResponse
+	if _, err := c.get(ctx, jobsAPIJobsGetPath, url.Values{"job_id": {jobID}}, &job); err != nil {
+		c.logger.Warn("Failed to look up job name, using the run name", "job_id", jobID, "err", err)
+		if ok {
+			return cached.name
+		}
+		return runName
+	}
+
+	c.mu.Lock()
+	c.jobNames[jobID] = cachedJobName{name: job.Settings.Name, fetchedAt: time.Now()}
+	c.mu.Unlock()
+	return job.Settings.Name
+}
+
+// get sends an authenticated GET request and decodes the JSON response into v.
+func (c *jobsAPIClient) get(ctx context.Context, path string, params url.Values, v any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jobs API request: %w", err)
+	}
+	if err := c.authenticator.Authenticate(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("jobs API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, jobsAPIMaxErrorBody))
+		return nil, fmt.Errorf("jobs API %s returned %s: %s", path, resp.Status, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("failed to decode jobs API %s response: %w", path, err)
+	}
+	return resp.Header, nil
+}
@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/promslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAuthenticator sets a fixed bearer token.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) Authenticate(r *http.Request) error {
+	r.Header.Set("Authorization", "Bearer test-token")
+	return nil
+}
+
+// newJobsAPIStub serves pages of runs/list responses in order, checking the query parameters
+// and page tokens, with workspaceID in the workspace ID header unless it is empty. jobs/get
+// returns the name in jobNames for a job ID, or 404 for other jobs.
+func newJobsAPIStub(t *testing.T, workspaceID string, pages []string, jobNames map[string]string) *httptest.Server {
+	t.Helper()
+	page := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		if r.URL.Path == jobsAPIJobsGetPath {
+			name, ok := jobNames[r.URL.Query().Get("job_id")]
+			if !ok {
+				http.Error(w, `{"error_code": "RESOURCE_DOES_NOT_EXIST"}`, http.StatusNotFound)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"job_id": %s, "settings": {"name": %q}}`, r.URL.Query().Get("job_id"), name)
+			return
+		}
+
+		assert.Equal(t, jobsAPIRunsListPath, r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("active_only"))
+		if page > 0 {
+			assert.Equal(t, "page2", r.URL.Query().Get("page_token"))
+		}
+		if page >= len(pages) {
+			http.Error(w, "no more pages", http.StatusNotFound)
+			return
+		}
+
+		if workspaceID != "" {
+			w.Header().Set(workspaceIDHeader, workspaceID)
+		}
+		_, _ = w.Write([]byte(pages[page]))
+		page++
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newStubJobsAPIClient returns a Jobs API client for a stub server.
+func newStubJobsAPIClient(server *httptest.Server) *jobsAPIClient {
+	return &jobsAPIClient{
+		baseURL:       server.URL,
+		httpClient:    server.Client(),
+		authenticator: stubAuthenticator{},
+		logger:        promslog.NewNopLogger(),
+		jobNames:      make(map[string]cachedJobName),
+	}
+}
+
+func TestJobsAPIClient_ListActiveRuns(t *testing.T) {
+	server := newJobsAPIStub(t, "123456789", []string{
+		`{"runs": [{"job_id": 11, "run_id": 1, "run_name": "Nightly ETL", "start_time": 1760000000000}], "has_more": true, "next_page_token": "page2"}`,
+		`{"runs": [{"job_id": 22, "run_id": 2, "start_time": 1760000060000}], "has_more": false}`,
+	}, map[string]string{"11": "Nightly ETL"})
+
+	workspaceID, runs, err := newStubJobsAPIClient(server).listActiveRuns(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "123456789", workspaceID)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "11", runs[0].jobID)
+	assert.Equal(t, "Nightly ETL", runs[0].jobName)
+	assert.Equal(t, int64(1760000000000), runs[0].startTime.UnixMilli())
+	assert.Equal(t, "job-22", runs[1].jobName, "runs without a job or run name fall back to the job ID")
+}
+
+func TestJobsAPIClient_ListActiveRuns_JobNames(t *testing.T) {
+	server := newJobsAPIStub(t, "123456789", []string{
+		`{"runs": [
+			{"job_id": 11, "run_id": 1, "run_name": "Nightly ETL (old name)", "start_time": 1760000000000},
+			{"job_id": 11, "run_id": 2, "run_name": "manual backfill", "start_time": 1760000060000},
+			{"job_id": 33, "run_id": 3, "run_name": "Deleted job", "start_time": 1760000120000},
+			{"run_id": 4, "run_name": "one-time notebook run", "start_time": 1760000180000}
+		]}`,
+	}, map[string]string{"11": "Nightly ETL"})
+
+	client := newStubJobsAPIClient(server)
+	_, runs, err := client.listActiveRuns(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, runs, 4)
+	assert.Equal(t, "Nightly ETL", runs[0].jobName, "runs are named after the job, not the run")
+	assert.Equal(t, "Nightly ETL", runs[1].jobName)
+	assert.Equal(t, "Deleted job", runs[2].jobName, "failed lookups fall back to the run name")
+	assert.Empty(t, runs[3].jobID, "one-time runs have no job ID")
+	assert.Equal(t, "one-time notebook run", runs[3].jobName)
+
+	// Names are cached by job ID; failed lookups are not
+	assert.Equal(t, map[string]cachedJobName{"11": client.jobNames["11"]}, client.jobNames)
+	client.jobNames["11"] = cachedJobName{name: "Cached", fetchedAt: time.Now()}
+	assert.Equal(t, "Cached", client.jobName(context.Background(), "11", ""))
+	client.jobNames["11"] = cachedJobName{name: "Cached", fetchedAt: time.Now().Add(-jobsAPINameTTL)}
+	assert.Equal(t, "Nightly ETL", client.jobName(context.Background(), "11", ""), "expired names are looked up again")
+}
+
+func TestJobsAPIClient_ListActiveRuns_WorkspaceIDFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		configured string
+		expected   string
+	}{
+		{name: "header", header: "123456789", configured: "987654321", expected: "123456789"},
+		{name: "configured", configured: "987654321", expected: "987654321"},
+		{name: "neither", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newJobsAPIStub(t, tt.header, []string{`{"has_more": false}`}, nil)
+			client := newStubJobsAPIClient(server)
+			client.workspaceID = tt.configured
+
+			workspaceID, _, err := client.listActiveRuns(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, workspaceID)
+		})
+	}
+}
+
+func TestJobsAPIClient_ListActiveRuns_NoRuns(t *testing.T) {
+	server := newJobsAPIStub(t, "123456789", []string{`{"has_more": false}`}, nil)
+
+	_, runs, err := newStubJobsAPIClient(server).listActiveRuns(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestJobsAPIClient_ListActiveRuns_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error_code": "PERMISSION_DENIED"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, _, err := newStubJobsAPIClient(server).listActiveRuns(context.Background())
+	assert.ErrorContains(t, err, "403 Forbidden")
+	assert.ErrorContains(t, err, "PERMISSION_DENIED")
+
+	// Transport configuration errors are reported on every call
+	client := newJobsAPIClient(&Config{ServerHostname: "example.cloud.databricks.com", TLSCAFile: "testdata/missing-ca.pem"}, promslog.NewNopLogger())
+	_, _, err = client.listActiveRuns(context.Background())
+	assert.Error(t, err)
+}
+
+func TestNewJobsAPIClient(t *testing.T) {
+	client := newJobsAPIClient(&Config{ServerHostname: "example.cloud.databricks.com", LiveJobRunsWorkspaceID: "987654321"}, promslog.NewNopLogger())
+
+	assert.Equal(t, "https://example.cloud.databricks.com", client.baseURL)
+	assert.Equal(t, "987654321", client.workspaceID)
+	assert.Equal(t, jobsAPITimeout, client.httpClient.Timeout)
+	assert.NotSame(t, http.DefaultClient, client.httpClient)
+}
@@ -3,11 +3,15 @@ package collector
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/promslog"
 )
 
@@ -20,7 +24,7 @@ func TestNewJobsCollector(t *testing.T) {
 	defer db.Close()
 
 	metrics := NewMetricDescriptors()
-	collector := NewJobsCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	if collector == nil {
 		t.Fatal("expected collector to be created, got nil")
@@ -30,8 +34,8 @@ func TestNewJobsCollector(t *testing.T) {
 		t.Error("collector logger should not be nil")
 	}
 
-	if collector.db == nil {
-		t.Error("collector db should not be nil")
+	if collector.router == nil {
+		t.Error("collector router should not be nil")
 	}
 
 	if collector.metrics == nil {
@@ -48,9 +52,9 @@ func TestJobsCollector_Describe(t *testing.T) {
 	defer db.Close()
 
 	metrics := NewMetricDescriptors()
-	collector := NewJobsCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
-	descCh := make(chan *prometheus.Desc, 10)
+	descCh := make(chan *prometheus.Desc, 20)
 	go func() {
 		collector.Describe(descCh)
 		close(descCh)
@@ -61,7 +65,7 @@ func TestJobsCollector_Describe(t *testing.T) {
 		descriptions = append(descriptions, desc)
 	}
 
-	expectedCount := 6 // JobRuns, JobRunStatus, JobRunDuration, TaskRetries, JobSLAMiss, ScrapeStatus
+	expectedCount := 17 // JobRuns, JobRunStatus, JobRunTerminations, JobRunTerminationsByWorkspace, JobRunDuration, JobRunDurationHistogram, TaskRetries, JobSLAMiss, JobSLAThreshold, JobRunPhaseDurationHistogram, TaskRunPhaseDurationHistogram, JobRunsActive, JobRunOldestActiveAge, JobCostEstimate, JobCostPerSuccessfulRun, ScrapeStatus, QueryScrapeDuration
 	if len(descriptions) != expectedCount {
 		t.Errorf("expected %d metric descriptions, got %d", expectedCount, len(descriptions))
 	}
@@ -83,7 +87,7 @@ func TestJobsCollector_CollectJobRuns(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").WillReturnRows(rows)
 
 	metrics := NewMetricDescriptors()
-	collector := NewJobsCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -135,14 +139,18 @@ func TestJobsCollector_CollectJobRunStatus(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").WillReturnRows(rows)
 
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "p50", "p95", "p99"}))
+		WillReturnRows(sqlmock.NewRows(jobRunTerminationsColumns))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunHistogramColumns(DefaultJobDurationBuckets)))
 
 	// Note: task_run_timeline query is skipped when CollectTaskRetries=false (default)
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "sla_miss_count"}))
+		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "threshold_seconds", "sla_miss_count"}))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunsActiveColumns))
 
 	metrics := NewMetricDescriptors()
-	collector := NewJobsCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -187,6 +195,10 @@ func TestJobsCollector_CollectJobRunDuration(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "run_count"}))
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "status", "run_count"}))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunTerminationsColumns))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunHistogramColumns(DefaultJobDurationBuckets)))
 
 	rows := sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "p50", "p95", "p99"}).
 		AddRow("123456789", "job1", "Test Job 1", 300.5, 850.2, 1200.8)
@@ -195,10 +207,15 @@ func TestJobsCollector_CollectJobRunDuration(t *testing.T) {
 
 	// Note: task_run_timeline query is skipped when CollectTaskRetries=false (default)
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "sla_miss_count"}))
+		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "threshold_seconds", "sla_miss_count"}))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunsActiveColumns))
 
+	// Quantile gauges are only collected for compatibility
+	config := DefaultConfig()
+	config.CollectJobDurationQuantiles = true
 	metrics := NewMetricDescriptors()
-	collector := NewJobsCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, config, logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -230,6 +247,62 @@ func TestJobsCollector_CollectJobRunDuration(t *testing.T) {
 	}
 }
 
+func TestJobsCollector_CollectJobRunDurationHistogram(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(jobRunHistogramColumns([]float64{60, 600})).
+		AddRow("123456789", "job1", "Test Job 1", 2, 3, 4, 5000.0)
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.JobDurationBuckets = []float64{60, 600}
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, logger)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectJobRunDurationHistogram(ch); err != nil {
+		t.Fatalf("collectJobRunDurationHistogram failed: %v", err)
+	}
+	close(ch)
+
+	var histograms []*dto.Histogram
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		if !strings.Contains(m.Desc().String(), `fqName: "databricks_job_run_duration_histogram_seconds_sliding"`) {
+			t.Errorf("unexpected histogram name: %s", m.Desc())
+		}
+		histograms = append(histograms, pb.GetHistogram())
+	}
+
+	if len(histograms) != 1 {
+		t.Fatalf("expected 1 histogram, got %d", len(histograms))
+	}
+	h := histograms[0]
+	if h.GetSampleCount() != 4 || h.GetSampleSum() != 5000 {
+		t.Errorf("unexpected count %d and sum %v", h.GetSampleCount(), h.GetSampleSum())
+	}
+	want := map[float64]uint64{60: 2, 600: 3}
+	for _, b := range h.GetBucket() {
+		if want[b.GetUpperBound()] != b.GetCumulativeCount() {
+			t.Errorf("bucket le=%v: expected %d, got %d", b.GetUpperBound(), want[b.GetUpperBound()], b.GetCumulativeCount())
+		}
+	}
+	if len(h.GetBucket()) != len(want) {
+		t.Errorf("expected %d buckets, got %d", len(want), len(h.GetBucket()))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
 func TestJobsCollector_CollectWithError(t *testing.T) {
 	logger := promslog.NewNopLogger()
 	db, mock, err := sqlmock.New()
@@ -246,13 +319,17 @@ func TestJobsCollector_CollectWithError(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "status", "run_count"}))
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "p50", "p95", "p99"}))
+		WillReturnRows(sqlmock.NewRows(jobRunTerminationsColumns))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunHistogramColumns(DefaultJobDurationBuckets)))
 	// Note: task_run_timeline query is skipped when CollectTaskRetries=false (default)
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "sla_miss_count"}))
+		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "threshold_seconds", "sla_miss_count"}))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunsActiveColumns))
 
 	metrics := NewMetricDescriptors()
-	collector := NewJobsCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	ch := make(chan prometheus.Metric, 10)
 	go func() {
@@ -286,7 +363,9 @@ func TestJobsCollector_CollectTaskRetries(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "status", "run_count"}))
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "p50", "p95", "p99"}))
+		WillReturnRows(sqlmock.NewRows(jobRunTerminationsColumns))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunHistogramColumns(DefaultJobDurationBuckets)))
 
 	rows := sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "task_key", "retry_count"}).
 		AddRow("123456789", "job1", "Test Job 1", "task1", 25.0).
@@ -295,13 +374,15 @@ func TestJobsCollector_CollectTaskRetries(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_task_run_timeline").WillReturnRows(rows)
 
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "sla_miss_count"}))
+		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "threshold_seconds", "sla_miss_count"}))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunsActiveColumns))
 
 	metrics := NewMetricDescriptors()
 	// Enable task retries collection for this test
 	cfg := DefaultConfig()
 	cfg.CollectTaskRetries = true
-	collector := NewJobsCollector(context.Background(), db, metrics, cfg, logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, cfg, logger)
 
 	ch := make(chan prometheus.Metric, 10)
 	go func() {
@@ -332,24 +413,320 @@ func TestJobsCollector_CollectJobSLAMiss(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "status", "run_count"}))
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
-		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "p50", "p95", "p99"}))
+		WillReturnRows(sqlmock.NewRows(jobRunTerminationsColumns))
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunHistogramColumns(DefaultJobDurationBuckets)))
 	// Note: task_run_timeline query is skipped when CollectTaskRetries=false (default)
 
-	rows := sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "sla_miss_count"}).
-		AddRow("123456789", "job1", "Test Job 1", 5.0).
-		AddRow("987654321", "job2", "Test Job 2", 2.0)
+	rows := sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "threshold_seconds", "sla_miss_count"}).
+		AddRow("123456789", "job1", "Test Job 1", 3600.0, 5.0).
+		AddRow("987654321", "job2", "Test Job 2", 600.0, 2.0).
+		AddRow("987654321", "job3", "Test Job 3", 7200.0, 0.0)
 
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobRunsActiveColumns))
 
 	metrics := NewMetricDescriptors()
-	collector := NewJobsCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
+
+	// Every job with runs has a threshold; only jobs with misses have a miss count
+	count := testutil.CollectAndCount(collector, "databricks_job_sla_threshold_seconds", "databricks_job_sla_miss_sliding")
+	if count != 5 {
+		t.Errorf("expected 3 SLA threshold and 2 SLA miss metrics, got %d", count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestJobsCollector_CollectJobCost(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "job_id", "job_name", "currency_code", "cost_usd", "successful_runs"}).
+		AddRow("123456789", "job1", "Nightly ETL", "USD", 30.0, 3).
+		AddRow("123456789", "job2", "job-job2", "USD", 4.5, 0)
+	mock.ExpectQuery("SELECT(.+)FROM system.billing.usage").WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.CollectJobCost = true
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, logger)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectJobCost(ch); err != nil {
+		t.Fatalf("collectJobCost failed: %v", err)
+	}
+	close(ch)
+
+	var cost, perRun []float64
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		switch m.Desc() {
+		case collector.metrics.JobCostEstimate:
+			cost = append(cost, pb.Gauge.GetValue())
+		case collector.metrics.JobCostPerSuccessfulRun:
+			perRun = append(perRun, pb.Gauge.GetValue())
+		}
+	}
+
+	if len(cost) != 2 || cost[0] != 30.0 || cost[1] != 4.5 {
+		t.Errorf("unexpected job cost: %v", cost)
+	}
+	// Without a successful run there is no cost per run
+	if len(perRun) != 1 || perRun[0] != 10.0 {
+		t.Errorf("unexpected cost per successful run: %v", perRun)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// jobRunTerminationsColumns are the columns of the job run terminations query.
+var jobRunTerminationsColumns = []string{"workspace_id", "job_id", "job_name", "termination_code", "run_count"}
+
+// jobRunsActiveColumns are the columns of the active job runs query.
+var jobRunsActiveColumns = []string{"workspace_id", "job_id", "job_name", "active_runs", "oldest_age_seconds"}
+
+// jobRunHistogramColumns returns the columns of the job run duration histogram query for buckets.
+func jobRunHistogramColumns(buckets []float64) []string {
+	columns := []string{"workspace_id", "job_id", "job_name"}
+	for i := range buckets {
+		columns = append(columns, fmt.Sprintf("bucket_%d", i))
+	}
+	return append(columns, "run_count", "duration_sum")
+}
+
+func TestJobsCollector_CollectJobRunsActive(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(jobRunsActiveColumns).
+		AddRow("123456789", "job1", "Test Job 1", 2.0, 5400.0).
+		AddRow("987654321", "job2", "Test Job 2", 1.0, 60.0)
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").WillReturnRows(rows)
+
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), DefaultConfig(), logger)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectJobRunsActive(ch); err != nil {
+		t.Fatalf("collectJobRunsActive failed: %v", err)
+	}
+	close(ch)
+
+	values := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		values[m.Desc().String()+"/"+pb.GetLabel()[0].GetValue()] = pb.GetGauge().GetValue() // Keyed by job_id
+	}
+
+	metrics := collector.metrics
+	if got := values[metrics.JobRunsActive.String()+"/job1"]; got != 2 {
+		t.Errorf("expected 2 active runs for job1, got %v", got)
+	}
+	if got := values[metrics.JobRunOldestActiveAge.String()+"/job1"]; got != 5400 {
+		t.Errorf("expected oldest active age 5400 for job1, got %v", got)
+	}
+	if len(values) != 4 {
+		t.Errorf("expected 4 metrics, got %d", len(values))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestJobsCollector_CollectLiveJobRunsActive(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now().Add(-2 * time.Hour).UnixMilli()
+	server := newJobsAPIStub(t, "123456789", []string{
+		fmt.Sprintf(`{"runs": [{"job_id": 11, "run_name": "Nightly ETL", "start_time": %d}, {"job_id": 11, "run_name": "Nightly ETL (manual)", "start_time": %d}], "has_more": true, "next_page_token": "page2"}`,
+			start, time.Now().UnixMilli()),
+		`{"runs": [{"job_id": 22, "run_name": "", "start_time": 0}]}`,
+	}, map[string]string{"11": "Nightly ETL"})
+
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), DefaultConfig(), logger)
+	collector.jobsAPI = newStubJobsAPIClient(server)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectJobRunsActive(ch); err != nil {
+		t.Fatalf("collectJobRunsActive failed: %v", err)
+	}
+	close(ch)
+
+	active := make(map[string]float64)
+	ages := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		labels := pb.GetLabel() // Sorted by name: job_id, job_name, workspace_id
+		if labels[2].GetValue() != "123456789" {
+			t.Errorf("expected workspace_id from the response header, got %q", labels[2].GetValue())
+		}
+		key := labels[0].GetValue() + "/" + labels[1].GetValue()
+		if m.Desc() == collector.metrics.JobRunsActive {
+			active[key] = pb.GetGauge().GetValue()
+		} else {
+			ages[key] = pb.GetGauge().GetValue()
+		}
+	}
+
+	if active["11/Nightly ETL"] != 2 || active["22/job-22"] != 1 || len(active) != 2 {
+		t.Errorf("unexpected active runs: %v", active)
+	}
+	if age := ages["11/Nightly ETL"]; age < 7200 || age > 7260 {
+		t.Errorf("expected the oldest run of job 11 to be about 2h old, got %vs", age)
+	}
+
+	// Live runs replace the timeline query
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+func TestJobsCollector_CollectRunPhaseDurations(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	bounds := []float64{10, 60}
+	jobColumns := []string{"workspace_id", "job_id", "job_name", "phase", "bucket_0", "bucket_1", "run_count", "duration_sum"}
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow("123456789", "job1", "Test Job 1", "setup", 1, 3, 4, 400.0).
+			AddRow("123456789", "job1", "Test Job 1", "execution", 0, 0, 4, 9000.0))
+	taskColumns := []string{"workspace_id", "job_id", "job_name", "task_key", "phase", "bucket_0", "bucket_1", "run_count", "duration_sum"}
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_task_run_timeline").
+		WillReturnRows(sqlmock.NewRows(taskColumns).
+			AddRow("123456789", "job1", "Test Job 1", "ingest", "cleanup", 2, 2, 2, 8.0))
+
+	config := DefaultConfig()
+	config.JobPhaseDurationBuckets = bounds
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, logger)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectJobRunPhaseDurations(ch); err != nil {
+		t.Fatalf("collectJobRunPhaseDurations failed: %v", err)
+	}
+	if err := collector.collectTaskRunPhaseDurations(ch); err != nil {
+		t.Fatalf("collectTaskRunPhaseDurations failed: %v", err)
+	}
+	close(ch)
+
+	histograms := make(map[string]*dto.Histogram)
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		var phase, taskKey string
+		for _, l := range pb.GetLabel() {
+			switch l.GetName() {
+			case labelPhase:
+				phase = l.GetValue()
+			case labelTaskKey:
+				taskKey = l.GetValue()
+			}
+		}
+		histograms[taskKey+"/"+phase] = pb.GetHistogram()
+	}
 
-	// Use testutil to count metrics
-	count := testutil.CollectAndCount(collector)
+	if len(histograms) != 3 {
+		t.Fatalf("expected 3 histograms, got %d", len(histograms))
+	}
+	setup := histograms["/setup"]
+	if setup.GetSampleCount() != 4 || setup.GetSampleSum() != 400 || setup.GetBucket()[1].GetCumulativeCount() != 3 {
+		t.Errorf("unexpected setup histogram: %v", setup)
+	}
+	if cleanup := histograms["ingest/cleanup"]; cleanup.GetSampleCount() != 2 {
+		t.Errorf("unexpected task cleanup histogram: %v", cleanup)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
 
-	// Should have metrics from all collectors
-	if count < 2 {
-		t.Errorf("expected at least 2 SLA miss metrics, got %d", count)
+func TestJobsCollector_CollectJobRunTerminations(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(jobRunTerminationsColumns).
+		AddRow("123456789", "job1", "Test Job 1", "SUCCESS", 10.0).
+		AddRow("123456789", "job1", "Test Job 1", "CLUSTER_ERROR", 2.0).
+		AddRow("123456789", "job2", "Test Job 2", "CLUSTER_ERROR", 3.0).
+		AddRow("987654321", "job3", "Test Job 3", "USER_CANCELED", 1.0)
+	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.job_run_timeline").WillReturnRows(rows)
+
+	collector := NewJobsCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), DefaultConfig(), logger)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectJobRunTerminations(ch); err != nil {
+		t.Fatalf("collectJobRunTerminations failed: %v", err)
+	}
+	close(ch)
+
+	perJob := 0
+	rollup := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		if m.Desc() == collector.metrics.JobRunTerminations {
+			perJob++
+			continue
+		}
+		labels := pb.GetLabel() // Sorted by name: termination_code, workspace_id
+		rollup[labels[1].GetValue()+"/"+labels[0].GetValue()] = pb.GetGauge().GetValue()
+	}
+
+	if perJob != 4 {
+		t.Errorf("expected 4 per-job termination metrics, got %d", perJob)
+	}
+	want := map[string]float64{
+		"123456789/SUCCESS":       10,
+		"123456789/CLUSTER_ERROR": 5,
+		"987654321/USER_CANCELED": 1,
+	}
+	if len(rollup) != len(want) {
+		t.Errorf("expected %d workspace rollup metrics, got %d", len(want), len(rollup))
+	}
+	for key, count := range want {
+		if rollup[key] != count {
+			t.Errorf("workspace rollup %s: expected %v, got %v", key, count, rollup[key])
+		}
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -1,21 +1,74 @@
 package collector
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // MetricDescriptors holds all Prometheus metric descriptors for the Databricks exporter.
 type MetricDescriptors struct {
 	// Billing & Cost Metrics (FinOps)
 	BillingDBUs            *prometheus.Desc
 	BillingCostEstimateUSD *prometheus.Desc
+	BillingCorrectionsDBUs *prometheus.Desc
+	BillingCostEffective   *prometheus.Desc
 	PriceChangeEvents      *prometheus.Desc
 	BillingScrapeErrors    *prometheus.Desc
 
+	// Calendar-aligned billing
+	BillingDBUsCalendar *prometheus.Desc
+	BillingCostCalendar *prometheus.Desc
+
+	// Billing attribution
+	BillingDBUsByProduct  *prometheus.Desc
+	BillingCostByProduct  *prometheus.Desc
+	BillingDBUsByCompute  *prometheus.Desc
+	BillingCostByCompute  *prometheus.Desc
+	BillingDBUsByIdentity *prometheus.Desc
+	BillingCostByIdentity *prometheus.Desc
+	BillingAttributedDBUs *prometheus.Desc
+	BillingAttributedCost *prometheus.Desc
+
+	// Budgets
+	BudgetAmount            *prometheus.Desc
+	BudgetSpendMonthToDate  *prometheus.Desc
+	BudgetBurnRatio         *prometheus.Desc
+	BudgetProjectedMonthEnd *prometheus.Desc
+
+	// Cost anomaly detection
+	BillingCostObserved     *prometheus.Desc
+	BillingCostExpected     *prometheus.Desc
+	BillingCostAnomalyScore *prometheus.Desc
+
+	// Cost allocation by custom tags (nil unless tag keys are configured, see setBillingTagKeys)
+	BillingDBUsByTag *prometheus.Desc
+	BillingCostByTag *prometheus.Desc
+
 	// Jobs Metrics (SRE/Platform)
-	JobRuns               *prometheus.Desc
-	JobRunStatus          *prometheus.Desc
-	JobRunDurationSeconds *prometheus.Desc
-	TaskRetries           *prometheus.Desc
-	JobSLAMiss            *prometheus.Desc
+	JobRuns                 *prometheus.Desc
+	JobRunStatus            *prometheus.Desc
+	JobRunDurationSeconds   *prometheus.Desc
+	JobRunDurationHistogram *prometheus.Desc
+	TaskRetries             *prometheus.Desc
+	JobSLAMiss              *prometheus.Desc
+	JobSLAThreshold         *prometheus.Desc
+
+	// Job run terminations per job, and rolled up per workspace
+	JobRunTerminations            *prometheus.Desc
+	JobRunTerminationsByWorkspace *prometheus.Desc
+
+	// Job and task run phase durations (opt-in)
+	JobRunPhaseDurationHistogram  *prometheus.Desc
+	TaskRunPhaseDurationHistogram *prometheus.Desc
+
+	// Job runs in progress (timeline, or Jobs API with --jobs-live-runs)
+	JobRunsActive         *prometheus.Desc
+	JobRunOldestActiveAge *prometheus.Desc
+
+	// Job cost (opt-in, billing window)
+	JobCostEstimate         *prometheus.Desc
+	JobCostPerSuccessfulRun *prometheus.Desc
 
 	// Pipelines Metrics (SRE/Platform)
 	PipelineRuns                *prometheus.Desc
@@ -24,20 +77,32 @@ type MetricDescriptors struct {
 	PipelineRetryEvents         *prometheus.Desc
 	PipelineFreshnessLagSeconds *prometheus.Desc
 
+	// Pipeline cost (opt-in, billing window)
+	PipelineCostEstimate         *prometheus.Desc
+	PipelineCostPerSuccessfulRun *prometheus.Desc
+
 	// SQL Warehouse Metrics (Analytics/BI)
 	Queries              *prometheus.Desc
 	QueryDurationSeconds *prometheus.Desc
 	QueryErrors          *prometheus.Desc
 	QueriesRunning       *prometheus.Desc
+	WarehouseIdleDBUs    *prometheus.Desc
+	WarehouseIdleCost    *prometheus.Desc
+	QueryCostByUser      *prometheus.Desc
+	QueryCostBySource    *prometheus.Desc
 
 	// Exporter health
 	ExporterUp *prometheus.Desc
 
 	// Scrape status (per-query health)
-	ScrapeStatus *prometheus.Desc
+	ScrapeStatus        *prometheus.Desc
+	QueryScrapeDuration *prometheus.Desc
 
 	// Exporter info (version and configuration)
 	ExporterInfo *prometheus.Desc
+
+	// Active SQL warehouse (changes on failover)
+	ActiveWarehouse *prometheus.Desc
 }
 
 // NewMetricDescriptors creates and returns all metric descriptors for the Databricks exporter.
@@ -47,19 +112,37 @@ func NewMetricDescriptors() *MetricDescriptors {
 
 		BillingDBUs: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "billing", "dbus_sliding"),
-			"Databricks Units (DBUs) consumed per workspace and SKU. "+
+			"Usage quantity (DBUs for compute, usage_unit otherwise) per workspace and SKU. "+
 				"Note: Databricks billing data has 24-48h lag from actual usage. "+
 				"Sliding window configurable via --billing-lookback (default: 24h).",
-			[]string{labelWorkspaceID, labelSKUName},
+			[]string{labelWorkspaceID, labelSKUName, labelUsageUnit},
 			nil,
 		),
 
 		BillingCostEstimateUSD: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "billing", "cost_estimate_usd_sliding"),
-			"List-price cost estimate (DBUs × list price) per workspace and SKU. "+
+			"List-price cost estimate (usage quantity × list price) per workspace and SKU, in currency_code. "+
 				"Note: Databricks billing data has 24-48h lag from actual usage. "+
 				"Sliding window configurable via --billing-lookback (default: 24h).",
-			[]string{labelWorkspaceID, labelSKUName},
+			[]string{labelWorkspaceID, labelSKUName, labelCurrencyCode},
+			nil,
+		),
+
+		BillingCorrectionsDBUs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "corrections_dbus_sliding"),
+			"Net DBUs from RETRACTION and RESTATEMENT billing records per workspace and SKU, already included in "+
+				"databricks_billing_dbus_sliding. Negative when corrections reduced usage. "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelSKUName, labelUsageUnit},
+			nil,
+		),
+
+		BillingCostEffective: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_effective_usd_sliding"),
+			"Cost after negotiated pricing overrides (configurable via --pricing-overrides-file) per workspace and SKU. "+
+				"Usage without an override in effect is priced at list price; unit price overrides are in the reporting currency. "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelSKUName, labelCurrencyCode},
 			nil,
 		),
 
@@ -79,6 +162,147 @@ func NewMetricDescriptors() *MetricDescriptors {
 			nil,
 		),
 
+		BillingDBUsCalendar: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "dbus_calendar"),
+			"Databricks Units (DBUs) consumed per workspace and SKU in a calendar period "+
+				"(today, yesterday, month_to_date, previous_month), with boundaries in --billing-timezone (default: UTC).",
+			[]string{labelWorkspaceID, labelSKUName, labelPeriod, labelUsageUnit},
+			nil,
+		),
+
+		BillingCostCalendar: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_estimate_usd_calendar"),
+			"List-price cost estimate per workspace and SKU in a calendar period "+
+				"(today, yesterday, month_to_date, previous_month), with boundaries in --billing-timezone (default: UTC).",
+			[]string{labelWorkspaceID, labelSKUName, labelPeriod, labelCurrencyCode},
+			nil,
+		),
+
+		BillingDBUsByProduct: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "dbus_by_product_sliding"),
+			"Databricks Units (DBUs) consumed per workspace and billing origin product (JOBS, DLT, SQL, MODEL_SERVING, INTERACTIVE, ...). "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelBillingOriginProduct, labelUsageUnit},
+			nil,
+		),
+
+		BillingCostByProduct: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_estimate_usd_by_product_sliding"),
+			"List-price cost estimate per workspace and billing origin product. "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelBillingOriginProduct, labelCurrencyCode},
+			nil,
+		),
+
+		BillingDBUsByCompute: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "dbus_by_compute_sliding"),
+			"Databricks Units (DBUs) consumed per workspace and billing origin product, split into serverless and classic compute "+
+				"and by Photon use (opt-in). Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelBillingOriginProduct, labelComputeType, labelPhoton, labelUsageUnit},
+			nil,
+		),
+
+		BillingCostByCompute: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_estimate_usd_by_compute_sliding"),
+			"List-price cost estimate per workspace and billing origin product, split into serverless and classic compute "+
+				"and by Photon use (opt-in). Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelBillingOriginProduct, labelComputeType, labelPhoton, labelCurrencyCode},
+			nil,
+		),
+
+		BillingDBUsByIdentity: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "dbus_by_identity_sliding"),
+			"Databricks Units (DBUs) per workspace and run_as or owner identity (opt-in). "+
+				"Identities beyond the top --billing-identity-limit by cost per workspace are rolled up into identity=\"__other__\". "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelIdentity, labelUsageUnit},
+			nil,
+		),
+
+		BillingCostByIdentity: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_estimate_usd_by_identity_sliding"),
+			"List-price cost estimate per workspace and run_as or owner identity (opt-in). "+
+				"Identities beyond the top --billing-identity-limit by cost per workspace are rolled up into identity=\"__other__\". "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelIdentity, labelCurrencyCode},
+			nil,
+		),
+
+		BillingAttributedDBUs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "attributed_dbus_sliding"),
+			"Databricks Units (DBUs) per workspace and usage_metadata attribution key and value (opt-in per key). "+
+				"Values beyond the key's series limit are rolled up into attribution_value=\"__other__\". "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelAttributionKey, labelAttributionValue, labelUsageUnit},
+			nil,
+		),
+
+		BillingAttributedCost: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "attributed_cost_estimate_usd_sliding"),
+			"List-price cost estimate per workspace and usage_metadata attribution key and value (opt-in per key). "+
+				"Values beyond the key's series limit are rolled up into attribution_value=\"__other__\". "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelAttributionKey, labelAttributionValue, labelCurrencyCode},
+			nil,
+		),
+
+		BudgetAmount: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "budget", "amount_usd"),
+			"Monthly budget amount from --budgets-file, in the reporting currency (USD unless --fx-rates-file sets one).",
+			[]string{labelBudget, labelCurrencyCode},
+			nil,
+		),
+
+		BudgetSpendMonthToDate: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "budget", "spend_month_to_date_usd"),
+			"List-price cost of the usage covered by the budget since the start of the month in --billing-timezone (default: UTC). "+
+				"Only cost in the reporting currency counts; prices in a currency without an FX rate are left out.",
+			[]string{labelBudget, labelCurrencyCode},
+			nil,
+		),
+
+		BudgetBurnRatio: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "budget", "burn_ratio"),
+			"Month-to-date spend divided by the budget amount (1 means the budget is used up).",
+			[]string{labelBudget},
+			nil,
+		),
+
+		BudgetProjectedMonthEnd: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "budget", "projected_month_end_usd"),
+			"Projected spend at the end of the month, by forecast method "+
+				"(linear: average daily spend; weekday: average daily spend weighted by weekday).",
+			[]string{labelBudget, labelForecastMethod, labelCurrencyCode},
+			nil,
+		),
+
+		// ===== Cost anomaly detection (opt-in) =====
+
+		BillingCostObserved: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_observed_usd"),
+			"List-price cost per workspace and SKU on the calendar day --billing-anomaly-offset-days days before today "+
+				"(default: 2) in --billing-timezone (default: UTC).",
+			[]string{labelWorkspaceID, labelSKUName, labelCurrencyCode},
+			nil,
+		),
+
+		BillingCostExpected: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_expected_usd"),
+			"Expected list-price cost per workspace and SKU on the scored day: "+
+				"the median cost on the same weekday over the previous --billing-anomaly-weeks weeks (default: 4).",
+			[]string{labelWorkspaceID, labelSKUName, labelCurrencyCode},
+			nil,
+		),
+
+		BillingCostAnomalyScore: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "billing", "cost_anomaly_score"),
+			"Deviation of the scored day's cost from the expected cost, in robust standard deviations "+
+				"of the same-weekday baseline. Positive values are spend above the baseline. "+
+				"Not reported until at least two baseline days have usage.",
+			[]string{labelWorkspaceID, labelSKUName, labelCurrencyCode},
+			nil,
+		),
+
 		// ===== Jobs Metrics (SRE/Platform) =====
 
 		JobRuns: prometheus.NewDesc(
@@ -95,13 +319,36 @@ func NewMetricDescriptors() *MetricDescriptors {
 			nil,
 		),
 
+		JobRunTerminations: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_run_terminations_sliding"),
+			"Job runs per workspace, job and termination code, such as SUCCESS, USER_CANCELED, CLUSTER_ERROR or RUN_EXECUTION_ERROR (sliding window, configurable via --jobs-lookback, default: 3h).",
+			[]string{labelWorkspaceID, labelJobID, labelJobName, labelTerminationCode},
+			nil,
+		),
+
+		JobRunTerminationsByWorkspace: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_run_terminations_by_workspace_sliding"),
+			"Job runs per workspace and termination code across all jobs (sliding window, configurable via --jobs-lookback, default: 3h).",
+			[]string{labelWorkspaceID, labelTerminationCode},
+			nil,
+		),
+
 		JobRunDurationSeconds: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "job_run_duration_seconds_sliding"),
-			"Job run duration quantiles (p50/p95/p99) per workspace and job (sliding window, configurable via --jobs-lookback, default: 3h).",
+			"Job run duration quantiles (p50/p95/p99) per workspace and job (sliding window, configurable via --jobs-lookback, default: 3h; requires --collect-job-duration-quantiles).",
 			[]string{labelWorkspaceID, labelJobID, labelJobName, labelQuantile},
 			nil,
 		),
 
+		JobRunDurationHistogram: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_run_duration_histogram_seconds_sliding"),
+			"Distribution of job run durations per workspace and job, with buckets configurable via --job-duration-bucket (sliding window, configurable via --jobs-lookback, default: 3h). "+
+				"Buckets, sum and count describe the runs in the current window and are recomputed on every scrape, so they can decrease: "+
+				"use histogram_quantile() on the current values and do not apply rate() or increase().",
+			[]string{labelWorkspaceID, labelJobID, labelJobName},
+			nil,
+		),
+
 		TaskRetries: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "task_retries_sliding"),
 			"Retries across job tasks per workspace, job, and task key (sliding window, configurable via --jobs-lookback, default: 3h).",
@@ -111,11 +358,60 @@ func NewMetricDescriptors() *MetricDescriptors {
 
 		JobSLAMiss: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "job_sla_miss_sliding"),
-			"Job runs exceeding SLA threshold (configurable via --sla-threshold) per workspace and job (sliding window, configurable via --jobs-lookback, default: 3h).",
+			"Job runs exceeding their SLA threshold (see databricks_job_sla_threshold_seconds) per workspace and job (sliding window, configurable via --jobs-lookback, default: 3h).",
 			[]string{labelWorkspaceID, labelJobID, labelJobName},
 			nil,
 		),
 
+		JobSLAThreshold: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_sla_threshold_seconds"),
+			"Effective SLA threshold per workspace and job with runs in the jobs lookback window: the first matching --job-sla-file entry, else the job's SLA tag (--job-sla-tag), else --sla-threshold.",
+			[]string{labelWorkspaceID, labelJobID, labelJobName},
+			nil,
+		),
+
+		JobRunPhaseDurationHistogram: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_run_phase_duration_histogram_seconds_sliding"),
+			"Distribution of the queue, setup, execution and cleanup durations of job runs per workspace, job and phase (sliding window, configurable via --jobs-lookback, default: 3h; requires --collect-job-phase-durations).",
+			[]string{labelWorkspaceID, labelJobID, labelJobName, labelPhase},
+			nil,
+		),
+
+		TaskRunPhaseDurationHistogram: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "task_run_phase_duration_histogram_seconds_sliding"),
+			"Distribution of the setup, execution and cleanup durations of task runs per workspace, job, task key and phase (sliding window, configurable via --jobs-lookback, default: 3h; requires --collect-task-phase-durations).",
+			[]string{labelWorkspaceID, labelJobID, labelJobName, labelTaskKey, labelPhase},
+			nil,
+		),
+
+		JobRunsActive: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_runs_active"),
+			"Job runs currently in progress per workspace and job (from the run timeline, or the Jobs API with --jobs-live-runs).",
+			[]string{labelWorkspaceID, labelJobID, labelJobName},
+			nil,
+		),
+
+		JobRunOldestActiveAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_run_oldest_active_age_seconds"),
+			"Time since the start of the oldest job run in progress per workspace and job (capped at --jobs-active-lookback, default: 48h, unless --jobs-live-runs is set).",
+			[]string{labelWorkspaceID, labelJobID, labelJobName},
+			nil,
+		),
+
+		JobCostEstimate: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_cost_estimate_usd_sliding"),
+			"Estimated cost of job usage (usage_metadata.job_id) per workspace and job (sliding window, configurable via --billing-lookback, default: 24h; requires --collect-job-cost).",
+			[]string{labelWorkspaceID, labelJobID, labelJobName, labelCurrencyCode},
+			nil,
+		),
+
+		JobCostPerSuccessfulRun: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "job_cost_per_successful_run_usd_sliding"),
+			"Estimated job cost divided by successful runs per workspace and job, over the billing window (configurable via --billing-lookback, default: 24h; requires --collect-job-cost).",
+			[]string{labelWorkspaceID, labelJobID, labelJobName, labelCurrencyCode},
+			nil,
+		),
+
 		// ===== Pipelines Metrics (SRE/Platform) =====
 
 		PipelineRuns: prometheus.NewDesc(
@@ -152,6 +448,20 @@ func NewMetricDescriptors() *MetricDescriptors {
 			[]string{labelWorkspaceID, labelPipelineID, labelPipelineName},
 			nil,
 		),
+
+		PipelineCostEstimate: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "pipeline_cost_estimate_usd_sliding"),
+			"Estimated cost of pipeline usage (usage_metadata.dlt_pipeline_id) per workspace and pipeline (sliding window, configurable via --billing-lookback, default: 24h; requires --collect-pipeline-cost).",
+			[]string{labelWorkspaceID, labelPipelineID, labelPipelineName, labelCurrencyCode},
+			nil,
+		),
+
+		PipelineCostPerSuccessfulRun: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "pipeline_cost_per_successful_run_usd_sliding"),
+			"Estimated pipeline cost divided by successful updates per workspace and pipeline, over the billing window (configurable via --billing-lookback, default: 24h; requires --collect-pipeline-cost).",
+			[]string{labelWorkspaceID, labelPipelineID, labelPipelineName, labelCurrencyCode},
+			nil,
+		),
 		// ===== SQL Warehouse Metrics (Analytics/BI) =====
 
 		Queries: prometheus.NewDesc(
@@ -182,6 +492,40 @@ func NewMetricDescriptors() *MetricDescriptors {
 			nil,
 		),
 
+		WarehouseIdleDBUs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "warehouse", "idle_dbus_sliding"),
+			"DBUs billed for SQL warehouse usage records with no query activity (opt-in). "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelWarehouseID, labelUsageUnit},
+			nil,
+		),
+
+		WarehouseIdleCost: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "warehouse", "idle_cost_estimate_usd_sliding"),
+			"List-price cost of SQL warehouse usage records with no query activity (opt-in). "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelWarehouseID, labelCurrencyCode},
+			nil,
+		),
+
+		QueryCostByUser: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "query", "cost_estimate_usd_by_user_sliding"),
+			"List-price SQL warehouse cost apportioned to queries by execution time, per warehouse and executed_by user (opt-in). "+
+				"Users beyond the top --query-cost-user-limit by cost per warehouse are rolled up into executed_by=\"__other__\". "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelWarehouseID, labelExecutedBy, labelCurrencyCode},
+			nil,
+		),
+
+		QueryCostBySource: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "query", "cost_estimate_usd_by_source_sliding"),
+			"List-price SQL warehouse cost apportioned to queries by execution time, per warehouse and query source "+
+				"(dashboard, genie, notebook, job, alert, sql_query, unknown) (opt-in). "+
+				"Sliding window configurable via --billing-lookback (default: 24h).",
+			[]string{labelWorkspaceID, labelWarehouseID, labelQuerySource, labelCurrencyCode},
+			nil,
+		),
+
 		// ===== Exporter Health =====
 
 		ExporterUp: prometheus.NewDesc(
@@ -201,13 +545,62 @@ func NewMetricDescriptors() *MetricDescriptors {
 			nil,
 		),
 
+		QueryScrapeDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "scrape_query_duration_seconds"),
+			"Time taken by each system table query during the last scrape, "+
+				"labeled with the SQL warehouse it was routed to (configurable via --warehouse-route).",
+			[]string{labelQuery, labelWarehouseHTTPPath},
+			nil,
+		),
+
 		ExporterInfo: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "exporter_info"),
 			"Build and configuration information for the exporter.",
-			[]string{"version", "billing_window", "jobs_window", "pipelines_window", "queries_window"},
+			[]string{"version", "billing_window", "jobs_window", "pipelines_window", "queries_window", "billing_cost_mode"},
 			nil,
 		),
+
+		ActiveWarehouse: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "exporter_active_warehouse_info"),
+			"SQL warehouse currently used by the exporter (always 1). "+
+				"role is primary, or failover after repeated connection failures (configurable via --warehouse-failover-http-path).",
+			[]string{labelWarehouseHTTPPath, labelWarehouseRole},
+			nil,
+		),
+	}
+}
+
+// setBillingTagKeys creates the by-tag billing descriptors with one tag_<key> label per
+// allowlisted custom tag key. The label set depends on configuration, so these are not
+// created by NewMetricDescriptors.
+func (m *MetricDescriptors) setBillingTagKeys(keys []string) {
+	if len(keys) == 0 {
+		m.BillingDBUsByTag = nil
+		m.BillingCostByTag = nil
+		return
+	}
+
+	labels := []string{labelWorkspaceID}
+	for _, key := range keys {
+		labels = append(labels, tagLabelName(key))
 	}
+	dbuLabels := append(slices.Clone(labels), labelUsageUnit)
+	costLabels := append(slices.Clone(labels), labelCurrencyCode)
+
+	m.BillingDBUsByTag = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "billing", "dbus_by_tag_sliding"),
+		"Databricks Units (DBUs) consumed per workspace and allowlisted custom tag values (configurable via --billing-tag-key). "+
+			"Sliding window configurable via --billing-lookback (default: 24h).",
+		dbuLabels,
+		nil,
+	)
+	m.BillingCostByTag = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "billing", "cost_by_tag_usd_sliding"),
+		"List-price cost estimate per workspace and allowlisted custom tag values (configurable via --billing-tag-key). "+
+			"Sliding window configurable via --billing-lookback (default: 24h).",
+		costLabels,
+		nil,
+	)
 }
 
 // Describe sends all metric descriptors to the provided channel.
@@ -216,15 +609,48 @@ func (m *MetricDescriptors) Describe(ch chan<- *prometheus.Desc) {
 	// Billing & Cost
 	ch <- m.BillingDBUs
 	ch <- m.BillingCostEstimateUSD
+	ch <- m.BillingCorrectionsDBUs
+	ch <- m.BillingCostEffective
 	ch <- m.PriceChangeEvents
 	ch <- m.BillingScrapeErrors
+	ch <- m.BillingDBUsCalendar
+	ch <- m.BillingCostCalendar
+	ch <- m.BillingDBUsByProduct
+	ch <- m.BillingCostByProduct
+	ch <- m.BillingDBUsByCompute
+	ch <- m.BillingCostByCompute
+	ch <- m.BillingDBUsByIdentity
+	ch <- m.BillingCostByIdentity
+	ch <- m.BillingAttributedDBUs
+	ch <- m.BillingAttributedCost
+	ch <- m.BudgetAmount
+	ch <- m.BudgetSpendMonthToDate
+	ch <- m.BudgetBurnRatio
+	ch <- m.BudgetProjectedMonthEnd
+	ch <- m.BillingCostObserved
+	ch <- m.BillingCostExpected
+	ch <- m.BillingCostAnomalyScore
+	if m.BillingDBUsByTag != nil {
+		ch <- m.BillingDBUsByTag
+		ch <- m.BillingCostByTag
+	}
 
 	// Jobs
 	ch <- m.JobRuns
 	ch <- m.JobRunStatus
+	ch <- m.JobRunTerminations
+	ch <- m.JobRunTerminationsByWorkspace
 	ch <- m.JobRunDurationSeconds
+	ch <- m.JobRunDurationHistogram
 	ch <- m.TaskRetries
 	ch <- m.JobSLAMiss
+	ch <- m.JobSLAThreshold
+	ch <- m.JobRunPhaseDurationHistogram
+	ch <- m.TaskRunPhaseDurationHistogram
+	ch <- m.JobRunsActive
+	ch <- m.JobRunOldestActiveAge
+	ch <- m.JobCostEstimate
+	ch <- m.JobCostPerSuccessfulRun
 
 	// Pipelines
 	ch <- m.PipelineRuns
@@ -232,15 +658,23 @@ func (m *MetricDescriptors) Describe(ch chan<- *prometheus.Desc) {
 	ch <- m.PipelineRunDurationSeconds
 	ch <- m.PipelineRetryEvents
 	ch <- m.PipelineFreshnessLagSeconds
+	ch <- m.PipelineCostEstimate
+	ch <- m.PipelineCostPerSuccessfulRun
 
 	// SQL Warehouse
 	ch <- m.Queries
 	ch <- m.QueryDurationSeconds
 	ch <- m.QueryErrors
 	ch <- m.QueriesRunning
+	ch <- m.WarehouseIdleDBUs
+	ch <- m.WarehouseIdleCost
+	ch <- m.QueryCostByUser
+	ch <- m.QueryCostBySource
 
 	// Health
 	ch <- m.ExporterUp
 	ch <- m.ScrapeStatus
+	ch <- m.QueryScrapeDuration
 	ch <- m.ExporterInfo
+	ch <- m.ActiveWarehouse
 }
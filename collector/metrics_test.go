@@ -31,6 +31,11 @@ func TestNewMetricDescriptors(t *testing.T) {
 			desc:   metrics.BillingCostEstimateUSD,
 			labels: []string{labelWorkspaceID, labelSKUName},
 		},
+		{
+			name:   "BillingCostEffective",
+			desc:   metrics.BillingCostEffective,
+			labels: []string{labelWorkspaceID, labelSKUName},
+		},
 		{
 			name:   "PriceChangeEvents",
 			desc:   metrics.PriceChangeEvents,
@@ -41,6 +46,46 @@ func TestNewMetricDescriptors(t *testing.T) {
 			desc:   metrics.BillingScrapeErrors,
 			labels: []string{labelStage},
 		},
+		{
+			name:   "BillingDBUsByProduct",
+			desc:   metrics.BillingDBUsByProduct,
+			labels: []string{labelWorkspaceID, labelBillingOriginProduct},
+		},
+		{
+			name:   "BillingCostByProduct",
+			desc:   metrics.BillingCostByProduct,
+			labels: []string{labelWorkspaceID, labelBillingOriginProduct},
+		},
+		{
+			name:   "BillingDBUsByCompute",
+			desc:   metrics.BillingDBUsByCompute,
+			labels: []string{labelWorkspaceID, labelBillingOriginProduct, labelComputeType, labelPhoton, labelUsageUnit},
+		},
+		{
+			name:   "BillingCostByCompute",
+			desc:   metrics.BillingCostByCompute,
+			labels: []string{labelWorkspaceID, labelBillingOriginProduct, labelComputeType, labelPhoton, labelCurrencyCode},
+		},
+		{
+			name:   "BillingDBUsByIdentity",
+			desc:   metrics.BillingDBUsByIdentity,
+			labels: []string{labelWorkspaceID, labelIdentity, labelUsageUnit},
+		},
+		{
+			name:   "BillingCostByIdentity",
+			desc:   metrics.BillingCostByIdentity,
+			labels: []string{labelWorkspaceID, labelIdentity, labelCurrencyCode},
+		},
+		{
+			name:   "BillingAttributedDBUs",
+			desc:   metrics.BillingAttributedDBUs,
+			labels: []string{labelWorkspaceID, labelAttributionKey, labelAttributionValue},
+		},
+		{
+			name:   "BillingAttributedCost",
+			desc:   metrics.BillingAttributedCost,
+			labels: []string{labelWorkspaceID, labelAttributionKey, labelAttributionValue},
+		},
 		// Jobs metrics
 		{
 			name:   "JobRuns",
@@ -57,6 +102,11 @@ func TestNewMetricDescriptors(t *testing.T) {
 			desc:   metrics.JobRunDurationSeconds,
 			labels: []string{labelWorkspaceID, labelJobID, labelJobName, labelQuantile},
 		},
+		{
+			name:   "JobRunDurationHistogram",
+			desc:   metrics.JobRunDurationHistogram,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName},
+		},
 		{
 			name:   "TaskRetries",
 			desc:   metrics.TaskRetries,
@@ -67,6 +117,51 @@ func TestNewMetricDescriptors(t *testing.T) {
 			desc:   metrics.JobSLAMiss,
 			labels: []string{labelWorkspaceID, labelJobID, labelJobName},
 		},
+		{
+			name:   "JobSLAThreshold",
+			desc:   metrics.JobSLAThreshold,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName},
+		},
+		{
+			name:   "JobRunTerminations",
+			desc:   metrics.JobRunTerminations,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName, labelTerminationCode},
+		},
+		{
+			name:   "JobRunTerminationsByWorkspace",
+			desc:   metrics.JobRunTerminationsByWorkspace,
+			labels: []string{labelWorkspaceID, labelTerminationCode},
+		},
+		{
+			name:   "JobRunPhaseDurationHistogram",
+			desc:   metrics.JobRunPhaseDurationHistogram,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName, labelPhase},
+		},
+		{
+			name:   "TaskRunPhaseDurationHistogram",
+			desc:   metrics.TaskRunPhaseDurationHistogram,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName, labelTaskKey, labelPhase},
+		},
+		{
+			name:   "JobRunsActive",
+			desc:   metrics.JobRunsActive,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName},
+		},
+		{
+			name:   "JobRunOldestActiveAge",
+			desc:   metrics.JobRunOldestActiveAge,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName},
+		},
+		{
+			name:   "JobCostEstimate",
+			desc:   metrics.JobCostEstimate,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName, labelCurrencyCode},
+		},
+		{
+			name:   "JobCostPerSuccessfulRun",
+			desc:   metrics.JobCostPerSuccessfulRun,
+			labels: []string{labelWorkspaceID, labelJobID, labelJobName, labelCurrencyCode},
+		},
 		// Pipelines metrics
 		{
 			name:   "PipelineRuns",
@@ -93,6 +188,16 @@ func TestNewMetricDescriptors(t *testing.T) {
 			desc:   metrics.PipelineFreshnessLagSeconds,
 			labels: []string{labelWorkspaceID, labelPipelineID, labelPipelineName},
 		},
+		{
+			name:   "PipelineCostEstimate",
+			desc:   metrics.PipelineCostEstimate,
+			labels: []string{labelWorkspaceID, labelPipelineID, labelPipelineName, labelCurrencyCode},
+		},
+		{
+			name:   "PipelineCostPerSuccessfulRun",
+			desc:   metrics.PipelineCostPerSuccessfulRun,
+			labels: []string{labelWorkspaceID, labelPipelineID, labelPipelineName, labelCurrencyCode},
+		},
 		// SQL Warehouse metrics
 		{
 			name:   "Queries",
@@ -125,6 +230,16 @@ func TestNewMetricDescriptors(t *testing.T) {
 			desc:   metrics.ScrapeStatus,
 			labels: []string{labelQuery},
 		},
+		{
+			name:   "QueryScrapeDuration",
+			desc:   metrics.QueryScrapeDuration,
+			labels: []string{labelQuery, labelWarehouseHTTPPath},
+		},
+		{
+			name:   "ActiveWarehouse",
+			desc:   metrics.ActiveWarehouse,
+			labels: []string{labelWarehouseHTTPPath, labelWarehouseRole},
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,7 +268,7 @@ func TestNewMetricDescriptors(t *testing.T) {
 
 func TestMetricDescriptors_Describe(t *testing.T) {
 	metrics := NewMetricDescriptors()
-	ch := make(chan *prometheus.Desc, 30) // Buffer for all metrics
+	ch := make(chan *prometheus.Desc, 100) // Buffer for all metrics
 
 	// Call Describe
 	metrics.Describe(ch)
@@ -165,18 +280,40 @@ func TestMetricDescriptors_Describe(t *testing.T) {
 		count++
 	}
 
-	// We expect 21 metrics:
-	// - 4 billing metrics
-	// - 5 jobs metrics
-	// - 5 pipelines metrics
-	// - 4 SQL warehouse metrics
-	// - 3 health metrics (exporter_up, scrape_status, exporter_info)
-	expectedCount := 21
+	// We expect 58 metrics:
+	// - 19 billing metrics
+	// - 4 budget metrics
+	// - 15 jobs metrics
+	// - 7 pipelines metrics
+	// - 8 SQL warehouse metrics
+	// - 5 health metrics (exporter_up, scrape_status, scrape_query_duration_seconds, exporter_info, exporter_active_warehouse_info)
+	expectedCount := 58
 	if count != expectedCount {
 		t.Errorf("Expected %d metric descriptors, got %d", expectedCount, count)
 	}
 }
 
+func TestMetricDescriptors_WindowedMetricsAreSliding(t *testing.T) {
+	metrics := NewMetricDescriptors()
+	metrics.setBillingTagKeys([]string{"team"})
+	ch := make(chan *prometheus.Desc, 100)
+	metrics.Describe(ch)
+	close(ch)
+
+	// Gauges over a sliding lookback window end in _sliding
+	for desc := range ch {
+		s := desc.String()
+		if !strings.Contains(s, "liding window") && !strings.Contains(s, "billing window") {
+			continue
+		}
+		fqName := s[strings.Index(s, `fqName: "`)+len(`fqName: "`):]
+		fqName = fqName[:strings.Index(fqName, `"`)]
+		if !strings.HasSuffix(fqName, "_sliding") {
+			t.Errorf("%s covers a sliding window but does not end in _sliding", fqName)
+		}
+	}
+}
+
 func TestMetricDescriptors_AllMetricsHaveDescriptions(t *testing.T) {
 	metrics := NewMetricDescriptors()
 
@@ -186,24 +323,61 @@ func TestMetricDescriptors_AllMetricsHaveDescriptions(t *testing.T) {
 	}{
 		{"BillingDBUs", metrics.BillingDBUs},
 		{"BillingCostEstimateUSD", metrics.BillingCostEstimateUSD},
+		{"BillingCorrectionsDBUs", metrics.BillingCorrectionsDBUs},
+		{"BillingCostEffective", metrics.BillingCostEffective},
 		{"PriceChangeEvents", metrics.PriceChangeEvents},
 		{"BillingScrapeErrors", metrics.BillingScrapeErrors},
+		{"BillingDBUsCalendar", metrics.BillingDBUsCalendar},
+		{"BillingCostCalendar", metrics.BillingCostCalendar},
+		{"BudgetAmount", metrics.BudgetAmount},
+		{"BudgetSpendMonthToDate", metrics.BudgetSpendMonthToDate},
+		{"BudgetBurnRatio", metrics.BudgetBurnRatio},
+		{"BudgetProjectedMonthEnd", metrics.BudgetProjectedMonthEnd},
+		{"BillingCostObserved", metrics.BillingCostObserved},
+		{"BillingCostExpected", metrics.BillingCostExpected},
+		{"BillingCostAnomalyScore", metrics.BillingCostAnomalyScore},
+		{"BillingDBUsByProduct", metrics.BillingDBUsByProduct},
+		{"BillingCostByProduct", metrics.BillingCostByProduct},
+		{"BillingDBUsByCompute", metrics.BillingDBUsByCompute},
+		{"BillingCostByCompute", metrics.BillingCostByCompute},
+		{"BillingDBUsByIdentity", metrics.BillingDBUsByIdentity},
+		{"BillingCostByIdentity", metrics.BillingCostByIdentity},
+		{"BillingAttributedDBUs", metrics.BillingAttributedDBUs},
+		{"BillingAttributedCost", metrics.BillingAttributedCost},
 		{"JobRuns", metrics.JobRuns},
 		{"JobRunStatus", metrics.JobRunStatus},
 		{"JobRunDurationSeconds", metrics.JobRunDurationSeconds},
+		{"JobRunDurationHistogram", metrics.JobRunDurationHistogram},
 		{"TaskRetries", metrics.TaskRetries},
 		{"JobSLAMiss", metrics.JobSLAMiss},
+		{"JobSLAThreshold", metrics.JobSLAThreshold},
+		{"JobRunTerminations", metrics.JobRunTerminations},
+		{"JobRunTerminationsByWorkspace", metrics.JobRunTerminationsByWorkspace},
+		{"JobRunPhaseDurationHistogram", metrics.JobRunPhaseDurationHistogram},
+		{"TaskRunPhaseDurationHistogram", metrics.TaskRunPhaseDurationHistogram},
+		{"JobRunsActive", metrics.JobRunsActive},
+		{"JobRunOldestActiveAge", metrics.JobRunOldestActiveAge},
+		{"JobCostEstimate", metrics.JobCostEstimate},
+		{"JobCostPerSuccessfulRun", metrics.JobCostPerSuccessfulRun},
 		{"PipelineRuns", metrics.PipelineRuns},
 		{"PipelineRunStatus", metrics.PipelineRunStatus},
 		{"PipelineRunDurationSeconds", metrics.PipelineRunDurationSeconds},
 		{"PipelineRetryEvents", metrics.PipelineRetryEvents},
 		{"PipelineFreshnessLagSeconds", metrics.PipelineFreshnessLagSeconds},
+		{"PipelineCostEstimate", metrics.PipelineCostEstimate},
+		{"PipelineCostPerSuccessfulRun", metrics.PipelineCostPerSuccessfulRun},
 		{"Queries", metrics.Queries},
 		{"QueryDurationSeconds", metrics.QueryDurationSeconds},
 		{"QueryErrors", metrics.QueryErrors},
 		{"QueriesRunning", metrics.QueriesRunning},
+		{"WarehouseIdleDBUs", metrics.WarehouseIdleDBUs},
+		{"WarehouseIdleCost", metrics.WarehouseIdleCost},
+		{"QueryCostByUser", metrics.QueryCostByUser},
+		{"QueryCostBySource", metrics.QueryCostBySource},
 		{"ExporterUp", metrics.ExporterUp},
 		{"ScrapeStatus", metrics.ScrapeStatus},
+		{"QueryScrapeDuration", metrics.QueryScrapeDuration},
+		{"ActiveWarehouse", metrics.ActiveWarehouse},
 	}
 
 	for _, tt := range tests {
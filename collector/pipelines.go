@@ -15,7 +15,7 @@ import (
 // PipelinesCollector collects pipeline-related metrics from Databricks.
 type PipelinesCollector struct {
 	logger *slog.Logger
-	db     *sql.DB
+	router *queryRouter // Resolves the warehouse for each query
 	ctx    context.Context
 	config *Config
 
@@ -31,10 +31,10 @@ type PipelinesCollector struct {
 }
 
 // NewPipelinesCollector creates a new PipelinesCollector.
-func NewPipelinesCollector(ctx context.Context, db *sql.DB, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *PipelinesCollector {
+func NewPipelinesCollector(ctx context.Context, router *queryRouter, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *PipelinesCollector {
 	return &PipelinesCollector{
 		logger:  logger,
-		db:      db,
+		router:  router,
 		metrics: metrics,
 		ctx:     ctx,
 		config:  config,
@@ -48,7 +48,10 @@ func (c *PipelinesCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.metrics.PipelineRunDurationSeconds
 	ch <- c.metrics.PipelineRetryEvents
 	ch <- c.metrics.PipelineFreshnessLagSeconds
+	ch <- c.metrics.PipelineCostEstimate
+	ch <- c.metrics.PipelineCostPerSuccessfulRun
 	ch <- c.metrics.ScrapeStatus
+	ch <- c.metrics.QueryScrapeDuration
 }
 
 // Collect fetches metrics from Databricks and sends them to Prometheus.
@@ -65,7 +68,7 @@ func (c *PipelinesCollector) Collect(ch chan<- prometheus.Metric) {
 	if !c.isTableAvailable() {
 		c.logger.Debug("Skipping pipeline metrics collection - table unavailable")
 		// Emit scrape status as 0 when table is unavailable
-		ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, 0, "pipelines")
+		ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, 0, collectorPipelines)
 		return
 	}
 
@@ -97,12 +100,20 @@ func (c *PipelinesCollector) Collect(ch chan<- prometheus.Metric) {
 		hasError = true
 	}
 
+	// Pipeline cost scans billing usage over the billing window, so it is opt-in
+	if c.config.CollectPipelineCost {
+		if err := c.collectPipelineCost(ch); err != nil {
+			c.handleCollectionError("pipeline cost", err)
+			hasError = true
+		}
+	}
+
 	// Emit scrape status
 	status := 1.0
 	if hasError {
 		status = 0.0
 	}
-	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, "pipelines")
+	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, collectorPipelines)
 
 	c.logger.Debug("Finished collecting pipeline metrics", "duration_seconds", time.Since(start).Seconds())
 }
@@ -153,7 +164,7 @@ func (c *PipelinesCollector) checkTableAvailability() {
 
 	// Try a simple query to check if the table exists
 	query := "SELECT 1 FROM system.lakeflow.pipeline_update_timeline LIMIT 1"
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, nil, queryPipelineTableCheck, query)
 	if rows != nil {
 		rows.Close()
 	}
@@ -248,7 +259,7 @@ func (c *PipelinesCollector) collectPipelineRuns(ch chan<- prometheus.Metric) er
 		lookback = DefaultPipelinesLookback
 	}
 	query := BuildPipelineRunsQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryPipelineRuns, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute pipeline runs query: %w", err)
 	}
@@ -284,7 +295,7 @@ func (c *PipelinesCollector) collectPipelineRunStatus(ch chan<- prometheus.Metri
 		lookback = DefaultPipelinesLookback
 	}
 	query := BuildPipelineRunStatusQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryPipelineRunStatus, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute pipeline run status query: %w", err)
 	}
@@ -321,7 +332,7 @@ func (c *PipelinesCollector) collectPipelineRunDuration(ch chan<- prometheus.Met
 		lookback = DefaultPipelinesLookback
 	}
 	query := BuildPipelineRunDurationQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryPipelineRunDuration, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute pipeline run duration query: %w", err)
 	}
@@ -380,7 +391,7 @@ func (c *PipelinesCollector) collectPipelineRetryEvents(ch chan<- prometheus.Met
 		lookback = DefaultPipelinesLookback
 	}
 	query := BuildPipelineRetryEventsQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryPipelineRetryEvents, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute pipeline retry events query: %w", err)
 	}
@@ -416,7 +427,7 @@ func (c *PipelinesCollector) collectPipelineFreshnessLag(ch chan<- prometheus.Me
 		lookback = DefaultPipelinesLookback
 	}
 	query := BuildPipelineFreshnessLagQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryPipelineFreshnessLag, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute pipeline freshness lag query: %w", err)
 	}
@@ -444,3 +455,45 @@ func (c *PipelinesCollector) collectPipelineFreshnessLag(ch chan<- prometheus.Me
 
 	return rows.Err()
 }
+
+// collectPipelineCost collects estimated cost per pipeline and its cost per successful update
+// over the billing window.
+func (c *PipelinesCollector) collectPipelineCost(ch chan<- prometheus.Metric) error {
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildPipelineCostQuery(lookback, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryPipelineCost, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute pipeline cost query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var workspaceID, pipelineID, pipelineName, currencyCode sql.NullString
+		var cost, successfulRuns sql.NullFloat64
+
+		if err := rows.Scan(&workspaceID, &pipelineID, &pipelineName, &currencyCode, &cost, &successfulRuns); err != nil {
+			return fmt.Errorf("failed to scan pipeline cost row: %w", err)
+		}
+		if !cost.Valid {
+			continue
+		}
+
+		labels := []string{workspaceID.String, pipelineID.String, pipelineName.String, currencyCode.String}
+		ch <- prometheus.MustNewConstMetric(c.metrics.PipelineCostEstimate, prometheus.GaugeValue, cost.Float64, labels...)
+
+		// Without a successful update in the window there is nothing to divide by
+		if successfulRuns.Float64 > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.metrics.PipelineCostPerSuccessfulRun,
+				prometheus.GaugeValue,
+				cost.Float64/successfulRuns.Float64,
+				labels...,
+			)
+		}
+	}
+
+	return rows.Err()
+}
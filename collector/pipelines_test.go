@@ -8,6 +8,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/promslog"
 )
 
@@ -20,7 +21,7 @@ func TestNewPipelinesCollector(t *testing.T) {
 	defer db.Close()
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	if collector == nil {
 		t.Fatal("expected collector to be created, got nil")
@@ -30,8 +31,8 @@ func TestNewPipelinesCollector(t *testing.T) {
 		t.Error("collector logger should not be nil")
 	}
 
-	if collector.db == nil {
-		t.Error("collector db should not be nil")
+	if collector.router == nil {
+		t.Error("collector router should not be nil")
 	}
 
 	if collector.metrics == nil {
@@ -48,7 +49,7 @@ func TestPipelinesCollector_Describe(t *testing.T) {
 	defer db.Close()
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	descCh := make(chan *prometheus.Desc, 10)
 	go func() {
@@ -61,7 +62,7 @@ func TestPipelinesCollector_Describe(t *testing.T) {
 		descriptions = append(descriptions, desc)
 	}
 
-	expectedCount := 6 // PipelineRuns, PipelineRunStatus, PipelineRunDuration, PipelineRetryEvents, PipelineFreshnessLag, ScrapeStatus
+	expectedCount := 9 // PipelineRuns, PipelineRunStatus, PipelineRunDuration, PipelineRetryEvents, PipelineFreshnessLag, PipelineCostEstimate, PipelineCostPerSuccessfulRun, ScrapeStatus, QueryScrapeDuration
 	if len(descriptions) != expectedCount {
 		t.Errorf("expected %d metric descriptions, got %d", expectedCount, len(descriptions))
 	}
@@ -87,7 +88,7 @@ func TestPipelinesCollector_CollectPipelineRuns(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.pipeline_update_timeline").WillReturnRows(rows)
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -150,7 +151,7 @@ func TestPipelinesCollector_CollectPipelineRunStatus(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "pipeline_id", "pipeline_name", "lag_seconds"}))
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -211,7 +212,7 @@ func TestPipelinesCollector_CollectPipelineRunDuration(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "pipeline_id", "pipeline_name", "lag_seconds"}))
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -270,7 +271,7 @@ func TestPipelinesCollector_CollectWithError(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "pipeline_id", "pipeline_name", "lag_seconds"}))
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	ch := make(chan prometheus.Metric, 10)
 	go func() {
@@ -320,7 +321,7 @@ func TestPipelinesCollector_CollectRetryEvents(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "pipeline_id", "pipeline_name", "lag_seconds"}))
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	ch := make(chan prometheus.Metric, 10)
 	go func() {
@@ -367,7 +368,7 @@ func TestPipelinesCollector_CollectFreshnessLag(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.lakeflow.pipeline_update_timeline").WillReturnRows(rows)
 
 	metrics := NewMetricDescriptors()
-	collector := NewPipelinesCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Use testutil to count metrics
 	count := testutil.CollectAndCount(collector)
@@ -381,3 +382,53 @@ func TestPipelinesCollector_CollectFreshnessLag(t *testing.T) {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
 }
+
+func TestPipelinesCollector_CollectPipelineCost(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"workspace_id", "pipeline_id", "pipeline_name", "currency_code", "cost_usd", "successful_runs"}).
+		AddRow("123456789", "pipeline1", "Nightly ETL", "USD", 30.0, 3).
+		AddRow("123456789", "pipeline2", "pipeline-pipeline2", "USD", 4.5, 0)
+	mock.ExpectQuery("SELECT(.+)FROM system.billing.usage").WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.CollectPipelineCost = true
+	collector := NewPipelinesCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, logger)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectPipelineCost(ch); err != nil {
+		t.Fatalf("collectPipelineCost failed: %v", err)
+	}
+	close(ch)
+
+	var cost, perRun []float64
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		switch m.Desc() {
+		case collector.metrics.PipelineCostEstimate:
+			cost = append(cost, pb.Gauge.GetValue())
+		case collector.metrics.PipelineCostPerSuccessfulRun:
+			perRun = append(perRun, pb.Gauge.GetValue())
+		}
+	}
+
+	if len(cost) != 2 || cost[0] != 30.0 || cost[1] != 4.5 {
+		t.Errorf("unexpected pipeline cost: %v", cost)
+	}
+	// Without a successful update there is no cost per update
+	if len(perRun) != 1 || perRun[0] != 10.0 {
+		t.Errorf("unexpected cost per successful update: %v", perRun)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// dateLayout is the format of override effective dates in the pricing overrides file.
+const dateLayout = "2006-01-02"
+
+// PricingOverrides is the contents of the pricing overrides file.
+type PricingOverrides struct {
+	Overrides []PriceOverride `yaml:"overrides"`
+}
+
+// PriceOverride replaces the list price of a SKU or billing origin product from a date on.
+// Exactly one of SKU or Product, and exactly one of DiscountPercent or UnitPrice, must be set.
+//
+// A discount applies to the list price in whatever currency it is reported in, after FX
+// conversion. A unit price is in the reporting currency: USD, or the reporting currency of the
+// FX rates file when one is configured. Currency may state it explicitly and must then match.
+type PriceOverride struct {
+	SKU             string   `yaml:"sku"`              // Matches usage sku_name
+	Product         string   `yaml:"product"`          // Matches usage billing_origin_product
+	DiscountPercent *float64 `yaml:"discount_percent"` // Percentage off the list price (0-100)
+	UnitPrice       *float64 `yaml:"unit_price"`       // Absolute price per usage unit in the reporting currency, replacing the list price
+	Currency        string   `yaml:"currency"`         // Currency code of UnitPrice; empty means the reporting currency
+	EffectiveFrom   string   `yaml:"effective_from"`   // First usage date the override applies to (YYYY-MM-DD)
+	EffectiveTo     string   `yaml:"effective_to"`     // First usage date it no longer applies to; empty means open-ended
+}
+
+var (
+	errOverrideTarget   = errors.New("exactly one of sku or product must be set")
+	errOverridePrice    = errors.New("exactly one of discount_percent or unit_price must be set")
+	errOverrideRange    = errors.New("discount_percent must be between 0 and 100 and unit_price must not be negative")
+	errOverrideCurrency = errors.New("currency must only be set with unit_price")
+)
+
+// LoadPricingOverrides reads and validates a YAML pricing overrides file.
+func LoadPricingOverrides(path string) ([]PriceOverride, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing overrides file: %w", err)
+	}
+
+	var file PricingOverrides
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing overrides file %s: %w", path, err)
+	}
+
+	if _, err := newPriceBook(file.Overrides); err != nil {
+		return nil, fmt.Errorf("invalid pricing overrides file %s: %w", path, err)
+	}
+	return file.Overrides, nil
+}
+
+// priceRule is a validated PriceOverride with parsed dates.
+type priceRule struct {
+	discount  float64 // Fraction off the list price; used when unitPrice is nil
+	unitPrice *float64
+	from      time.Time
+	to        time.Time // Zero means open-ended
+}
+
+// covers reports whether the rule applies to usage on the given date.
+func (r priceRule) covers(date time.Time) bool {
+	return !date.Before(r.from) && (r.to.IsZero() || date.Before(r.to))
+}
+
+// priceBook looks up the override in effect for a SKU or product on a usage date.
+type priceBook struct {
+	bySKU     map[string][]priceRule
+	byProduct map[string][]priceRule
+}
+
+// newPriceBook validates overrides and indexes them by target. Overrides for the
+// same SKU or product must not have overlapping effective windows.
+func newPriceBook(overrides []PriceOverride) (*priceBook, error) {
+	book := &priceBook{
+		bySKU:     make(map[string][]priceRule),
+		byProduct: make(map[string][]priceRule),
+	}
+
+	for i, o := range overrides {
+		rule, err := o.rule()
+		if err != nil {
+			return nil, fmt.Errorf("override %d: %w", i+1, err)
+		}
+		if o.SKU != "" {
+			book.bySKU[o.SKU] = append(book.bySKU[o.SKU], rule)
+		} else {
+			book.byProduct[o.Product] = append(book.byProduct[o.Product], rule)
+		}
+	}
+
+	for sku, rules := range book.bySKU {
+		if err := checkOverlap(rules); err != nil {
+			return nil, fmt.Errorf("overrides for sku %q: %w", sku, err)
+		}
+	}
+	for product, rules := range book.byProduct {
+		if err := checkOverlap(rules); err != nil {
+			return nil, fmt.Errorf("overrides for product %q: %w", product, err)
+		}
+	}
+
+	return book, nil
+}
+
+// rule validates the override and parses its effective window.
+func (o PriceOverride) rule() (priceRule, error) {
+	if (o.SKU == "") == (o.Product == "") {
+		return priceRule{}, errOverrideTarget
+	}
+	if (o.DiscountPercent == nil) == (o.UnitPrice == nil) {
+		return priceRule{}, errOverridePrice
+	}
+	if (o.DiscountPercent != nil && (*o.DiscountPercent < 0 || *o.DiscountPercent > 100)) ||
+		(o.UnitPrice != nil && *o.UnitPrice < 0) {
+		return priceRule{}, errOverrideRange
+	}
+	if o.Currency != "" && o.UnitPrice == nil {
+		return priceRule{}, errOverrideCurrency
+	}
+
+	rule := priceRule{unitPrice: o.UnitPrice}
+	if o.DiscountPercent != nil {
+		rule.discount = *o.DiscountPercent / 100
+	}
+
+	var err error
+	if rule.from, err = time.Parse(dateLayout, o.EffectiveFrom); err != nil {
+		return priceRule{}, fmt.Errorf("invalid effective_from %q: must be YYYY-MM-DD", o.EffectiveFrom)
+	}
+	if o.EffectiveTo != "" {
+		if rule.to, err = time.Parse(dateLayout, o.EffectiveTo); err != nil {
+			return priceRule{}, fmt.Errorf("invalid effective_to %q: must be YYYY-MM-DD", o.EffectiveTo)
+		}
+		if !rule.to.After(rule.from) {
+			return priceRule{}, fmt.Errorf("effective_to %s must be after effective_from %s", o.EffectiveTo, o.EffectiveFrom)
+		}
+	}
+
+	return rule, nil
+}
+
+// checkOverrideCurrencies rejects unit prices stated in a currency other than the reporting
+// currency, which the exporter has no rate to convert from.
+func checkOverrideCurrencies(overrides []PriceOverride, reportingCurrency string) error {
+	for i, o := range overrides {
+		if o.Currency != "" && o.Currency != reportingCurrency {
+			return fmt.Errorf("override %d: unit_price currency %s must be the reporting currency %s", i+1, o.Currency, reportingCurrency)
+		}
+	}
+	return nil
+}
+
+// checkOverlap sorts rules by start date and rejects overlapping windows.
+func checkOverlap(rules []priceRule) error {
+	sort.Slice(rules, func(i, j int) bool { return rules[i].from.Before(rules[j].from) })
+
+	for i := 1; i < len(rules); i++ {
+		prev, next := rules[i-1], rules[i]
+		if prev.to.IsZero() || prev.to.After(next.from) {
+			return fmt.Errorf("effective windows starting %s and %s overlap",
+				prev.from.Format(dateLayout), next.from.Format(dateLayout))
+		}
+	}
+	return nil
+}
+
+// lookup returns the rule for usage on date, preferring a SKU override over a product override.
+func (b *priceBook) lookup(sku, product string, date time.Time) (priceRule, bool) {
+	for _, rules := range [][]priceRule{b.bySKU[sku], b.byProduct[product]} {
+		for _, rule := range rules {
+			if rule.covers(date) {
+				return rule, true
+			}
+		}
+	}
+	return priceRule{}, false
+}
+
+// effectiveCost returns the cost of usage after overrides, given its list-price cost, and
+// whether a unit price set it, in which case the cost is in the reporting currency rather than
+// the currency of the list price. Usage without an override in effect keeps its list-price cost.
+func (b *priceBook) effectiveCost(sku, product string, date time.Time, quantity, listCost float64) (float64, bool) {
+	rule, ok := b.lookup(sku, product, date)
+	if !ok {
+		return listCost, false
+	}
+	if rule.unitPrice != nil {
+		return quantity * *rule.unitPrice, true
+	}
+	return listCost * (1 - rule.discount), false
+}
+
+// FXRates is the contents of the FX rates file. List prices in a currency with a rate are
+// converted into the reporting currency; prices in other currencies are left unconverted.
+type FXRates struct {
+	ReportingCurrency string             `yaml:"reporting_currency"` // Currency code of converted costs, e.g. EUR
+	Rates             map[string]float64 `yaml:"rates"`              // Reporting currency units per unit of each currency code
+}
+
+var (
+	errFXReportingCurrency = errors.New("reporting_currency must be set")
+	errFXRate              = errors.New("rates must be positive")
+)
+
+// LoadFXRates reads and validates a YAML FX rates file.
+func LoadFXRates(path string) (*FXRates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FX rates file: %w", err)
+	}
+
+	var rates FXRates
+	if err := yaml.UnmarshalStrict(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse FX rates file %s: %w", path, err)
+	}
+
+	if err := rates.validate(); err != nil {
+		return nil, fmt.Errorf("invalid FX rates file %s: %w", path, err)
+	}
+	return &rates, nil
+}
+
+// validate checks that a reporting currency is set and every rate is positive.
+func (r *FXRates) validate() error {
+	if r.ReportingCurrency == "" {
+		return errFXReportingCurrency
+	}
+	for currency, rate := range r.Rates {
+		if currency == "" || rate <= 0 {
+			return fmt.Errorf("rate %q: %w", currency, errFXRate)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Ptr(v float64) *float64 { return &v }
+
+func date(s string) time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestLoadPricingOverrides(t *testing.T) {
+	overrides, err := LoadPricingOverrides("testdata/pricing_overrides.yaml")
+	require.NoError(t, err)
+	require.Len(t, overrides, 3)
+
+	assert.Equal(t, "JOBS", overrides[0].Product)
+	assert.Equal(t, 20.0, *overrides[0].DiscountPercent)
+	assert.Equal(t, "PREMIUM_SERVERLESS_SQL", overrides[1].SKU)
+	assert.Equal(t, 0.55, *overrides[1].UnitPrice)
+	assert.Equal(t, "2026-03-01", overrides[1].EffectiveTo)
+}
+
+func TestLoadPricingOverrides_Errors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	_, err := LoadPricingOverrides(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read")
+
+	_, err = LoadPricingOverrides(write("unknown.yaml", "overrides:\n  - sku: A\n    discount: 10\n"))
+	assert.ErrorContains(t, err, "failed to parse", "unknown fields should be rejected")
+
+	_, err = LoadPricingOverrides(write("overlap.yaml", `overrides:
+  - sku: A
+    discount_percent: 10
+    effective_from: 2026-01-01
+  - sku: A
+    unit_price: 0.4
+    effective_from: 2026-06-01
+`))
+	assert.ErrorContains(t, err, "overlap")
+}
+
+func TestNewPriceBook_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		override PriceOverride
+		err      string
+	}{
+		{
+			name:     "no target",
+			override: PriceOverride{DiscountPercent: float64Ptr(10), EffectiveFrom: "2026-01-01"},
+			err:      errOverrideTarget.Error(),
+		},
+		{
+			name:     "sku and product",
+			override: PriceOverride{SKU: "A", Product: "JOBS", DiscountPercent: float64Ptr(10), EffectiveFrom: "2026-01-01"},
+			err:      errOverrideTarget.Error(),
+		},
+		{
+			name:     "no price",
+			override: PriceOverride{SKU: "A", EffectiveFrom: "2026-01-01"},
+			err:      errOverridePrice.Error(),
+		},
+		{
+			name:     "discount and unit price",
+			override: PriceOverride{SKU: "A", DiscountPercent: float64Ptr(10), UnitPrice: float64Ptr(0.5), EffectiveFrom: "2026-01-01"},
+			err:      errOverridePrice.Error(),
+		},
+		{
+			name:     "discount over 100",
+			override: PriceOverride{SKU: "A", DiscountPercent: float64Ptr(120), EffectiveFrom: "2026-01-01"},
+			err:      errOverrideRange.Error(),
+		},
+		{
+			name:     "negative unit price",
+			override: PriceOverride{SKU: "A", UnitPrice: float64Ptr(-1), EffectiveFrom: "2026-01-01"},
+			err:      errOverrideRange.Error(),
+		},
+		{
+			name:     "currency with discount",
+			override: PriceOverride{SKU: "A", DiscountPercent: float64Ptr(10), Currency: "EUR", EffectiveFrom: "2026-01-01"},
+			err:      errOverrideCurrency.Error(),
+		},
+		{
+			name:     "missing effective_from",
+			override: PriceOverride{SKU: "A", DiscountPercent: float64Ptr(10)},
+			err:      "invalid effective_from",
+		},
+		{
+			name:     "bad effective_to",
+			override: PriceOverride{SKU: "A", DiscountPercent: float64Ptr(10), EffectiveFrom: "2026-01-01", EffectiveTo: "March"},
+			err:      "invalid effective_to",
+		},
+		{
+			name:     "empty window",
+			override: PriceOverride{SKU: "A", DiscountPercent: float64Ptr(10), EffectiveFrom: "2026-01-01", EffectiveTo: "2026-01-01"},
+			err:      "must be after effective_from",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newPriceBook([]PriceOverride{tt.override})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Contains(t, err.Error(), "override 1")
+		})
+	}
+}
+
+func TestNewPriceBook_OverlappingWindows(t *testing.T) {
+	discount := func(target, from, to string) PriceOverride {
+		return PriceOverride{SKU: target, DiscountPercent: float64Ptr(10), EffectiveFrom: from, EffectiveTo: to}
+	}
+
+	tests := []struct {
+		name      string
+		overrides []PriceOverride
+		overlap   bool
+	}{
+		{
+			name:      "adjacent windows",
+			overrides: []PriceOverride{discount("A", "2026-01-01", "2026-02-01"), discount("A", "2026-02-01", "")},
+		},
+		{
+			name:      "adjacent windows out of order",
+			overrides: []PriceOverride{discount("A", "2026-02-01", ""), discount("A", "2026-01-01", "2026-02-01")},
+		},
+		{
+			name:      "gap between windows",
+			overrides: []PriceOverride{discount("A", "2026-01-01", "2026-02-01"), discount("A", "2026-03-01", "2026-04-01")},
+		},
+		{
+			name:      "different skus",
+			overrides: []PriceOverride{discount("A", "2026-01-01", ""), discount("B", "2026-01-01", "")},
+		},
+		{
+			name: "sku and product with the same window",
+			overrides: []PriceOverride{
+				discount("A", "2026-01-01", ""),
+				{Product: "A", DiscountPercent: float64Ptr(5), EffectiveFrom: "2026-01-01"},
+			},
+		},
+		{
+			name:      "partial overlap",
+			overrides: []PriceOverride{discount("A", "2026-01-01", "2026-02-15"), discount("A", "2026-02-01", "2026-03-01")},
+			overlap:   true,
+		},
+		{
+			name:      "open-ended window followed by another",
+			overrides: []PriceOverride{discount("A", "2026-01-01", ""), discount("A", "2026-06-01", "2026-07-01")},
+			overlap:   true,
+		},
+		{
+			name:      "window contained in another",
+			overrides: []PriceOverride{discount("A", "2026-01-01", "2026-12-01"), discount("A", "2026-03-01", "2026-04-01")},
+			overlap:   true,
+		},
+		{
+			name:      "same start date",
+			overrides: []PriceOverride{discount("A", "2026-01-01", "2026-02-01"), discount("A", "2026-01-01", "2026-03-01")},
+			overlap:   true,
+		},
+		{
+			name: "overlapping product windows",
+			overrides: []PriceOverride{
+				{Product: "JOBS", DiscountPercent: float64Ptr(10), EffectiveFrom: "2026-01-01"},
+				{Product: "JOBS", UnitPrice: float64Ptr(0.1), EffectiveFrom: "2026-05-01"},
+			},
+			overlap: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newPriceBook(tt.overrides)
+			if tt.overlap {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "overlap")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPriceBook_EffectiveCost(t *testing.T) {
+	book, err := newPriceBook([]PriceOverride{
+		{Product: "JOBS", DiscountPercent: float64Ptr(20), EffectiveFrom: "2026-01-01"},
+		{SKU: "PREMIUM_JOBS_COMPUTE", UnitPrice: float64Ptr(0.1), EffectiveFrom: "2026-03-01", EffectiveTo: "2026-04-01"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		sku        string
+		product    string
+		date       string
+		expected   float64
+		unitPriced bool
+	}{
+		{"before any override", "PREMIUM_JOBS_COMPUTE", "JOBS", "2025-12-31", 30, false},
+		{"product discount", "PREMIUM_JOBS_COMPUTE", "JOBS", "2026-02-28", 24, false},
+		{"sku price takes precedence", "PREMIUM_JOBS_COMPUTE", "JOBS", "2026-03-01", 10, true},
+		{"sku window end is exclusive", "PREMIUM_JOBS_COMPUTE", "JOBS", "2026-04-01", 24, false},
+		{"other product", "PREMIUM_SQL", "SQL", "2026-03-15", 30, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 100 DBUs at a list price of 0.30
+			cost, unitPriced := book.effectiveCost(tt.sku, tt.product, date(tt.date), 100, 30)
+			assert.InDelta(t, tt.expected, cost, 1e-9)
+			assert.Equal(t, tt.unitPriced, unitPriced)
+		})
+	}
+}
+
+func TestLoadFXRates(t *testing.T) {
+	rates, err := LoadFXRates("testdata/fx_rates.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "EUR", rates.ReportingCurrency)
+	assert.Equal(t, map[string]float64{"USD": 0.92, "GBP": 1.17}, rates.Rates)
+}
+
+func TestLoadFXRates_Errors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	_, err := LoadFXRates(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read")
+
+	_, err = LoadFXRates(write("unknown.yaml", "reporting_currency: EUR\nrate:\n  USD: 0.9\n"))
+	assert.ErrorContains(t, err, "failed to parse", "unknown fields should be rejected")
+
+	_, err = LoadFXRates(write("no_currency.yaml", "rates:\n  USD: 0.9\n"))
+	assert.ErrorIs(t, err, errFXReportingCurrency)
+
+	_, err = LoadFXRates(write("negative.yaml", "reporting_currency: EUR\nrates:\n  USD: -1\n"))
+	assert.ErrorIs(t, err, errFXRate)
+}
@@ -2,6 +2,9 @@ package collector
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -35,47 +38,383 @@ func durationToSQLInterval(d time.Duration) string {
 	return fmt.Sprintf("%d MINUTES", minutes)
 }
 
+// sqlStringLiteral quotes s as a Databricks SQL string literal. Used for configured
+// values (such as tag keys) that cannot be passed as query parameters.
+func sqlStringLiteral(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
 // ===== Billing & Cost Queries =====
 
+// Billing record types that correct earlier usage (see BuildBillingDBUsQuery).
+const (
+	recordTypeRetraction  = "RETRACTION"
+	recordTypeRestatement = "RESTATEMENT"
+)
+
+// defaultCurrencyCode is reported for usage without a list price, unless costs are converted
+// into a reporting currency.
+const defaultCurrencyCode = "USD"
+
+// priceJoin is the list price CTE and usage join shared by the cost queries.
+// Both forms expose the unit price as p.unit_price, and currency is the currency_code
+// expression for a joined usage row.
+type priceJoin struct {
+	cte      string
+	join     string
+	currency string
+}
+
+// billingPriceJoin returns the price join for a billing cost mode. The current mode joins
+// today's list prices (price_end_time IS NULL), which is cheap but misprices usage recorded
+// before a price change. The historical mode joins the price effective at usage_start_time.
+// With FX rates, prices in a currency with a rate are converted into the reporting currency.
+func billingPriceJoin(mode, interval string, fx *FXRates) priceJoin {
+	price, currency, fxCTE, fxJoin := "pricing.default", "currency_code", "", ""
+	unpriced := sqlStringLiteral(defaultCurrencyCode)
+	if fx != nil {
+		reporting := sqlStringLiteral(fx.ReportingCurrency)
+		unpriced = reporting
+		if len(fx.Rates) > 0 {
+			codes := make([]string, 0, len(fx.Rates))
+			for code := range fx.Rates {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+			values := make([]string, len(codes))
+			for i, code := range codes {
+				values[i] = fmt.Sprintf("(%s, %s)", sqlStringLiteral(code), strconv.FormatFloat(fx.Rates[code], 'g', -1, 64))
+			}
+			fxCTE = fmt.Sprintf(`fx_rates AS (
+			SELECT * FROM VALUES %s AS fx(fx_currency_code, fx_rate)
+		),
+		`, strings.Join(values, ", "))
+			fxJoin = "\n\t\t\tLEFT JOIN fx_rates ON currency_code = fx_currency_code"
+			price = "pricing.default * COALESCE(fx_rate, 1)"
+			currency = fmt.Sprintf("CASE WHEN fx_rate IS NULL THEN currency_code ELSE %s END", reporting)
+		}
+	}
+
+	if mode == BillingCostModeHistorical {
+		return priceJoin{
+			cte: fmt.Sprintf(`%seffective_prices AS (
+			SELECT sku_name, cloud, usage_unit, price_start_time, price_end_time, %s as unit_price, %s as currency_code
+			FROM system.billing.list_prices%s
+			WHERE price_end_time IS NULL
+				OR price_end_time >= current_date() - INTERVAL %s
+		)`, fxCTE, price, currency, fxJoin, interval),
+			join: `LEFT JOIN effective_prices p ON u.sku_name = p.sku_name AND u.cloud = p.cloud
+			AND u.usage_unit = p.usage_unit
+			AND u.usage_start_time >= p.price_start_time
+			AND (p.price_end_time IS NULL OR u.usage_start_time < p.price_end_time)`,
+			currency: fmt.Sprintf("COALESCE(p.currency_code, %s)", unpriced),
+		}
+	}
+
+	return priceJoin{
+		cte: fmt.Sprintf(`%scurrent_prices AS (
+			SELECT DISTINCT sku_name, cloud, %s as unit_price, %s as currency_code
+			FROM system.billing.list_prices%s
+			WHERE price_end_time IS NULL
+		)`, fxCTE, price, currency, fxJoin),
+		join:     `LEFT JOIN current_prices p ON u.sku_name = p.sku_name AND u.cloud = p.cloud`,
+		currency: fmt.Sprintf("COALESCE(p.currency_code, %s)", unpriced),
+	}
+}
+
 // BuildBillingDBUsQuery returns the query for DBU consumption with configurable lookback.
+//
+// Late corrections are recorded as RETRACTION rows, which carry the negated quantity of the
+// records they cancel, and RESTATEMENT rows with the corrected quantity. Summing every
+// record_type yields the corrected total; corrections_dbus is the net change they made.
+// Quantities are in usage_unit, which is DBU for compute but not for storage or networking.
 func BuildBillingDBUsQuery(lookback time.Duration) string {
 	interval := durationToSQLInterval(lookback)
 	return fmt.Sprintf(`
 		SELECT 
 			workspace_id,
 			sku_name,
-			SUM(usage_quantity) as dbus_total
+			usage_unit,
+			SUM(usage_quantity) as dbus_total,
+			SUM(CASE WHEN record_type IN ('%s', '%s') THEN usage_quantity ELSE 0 END) as corrections_dbus
 		FROM system.billing.usage
 		WHERE usage_date >= current_date() - INTERVAL %s
 			AND workspace_id IS NOT NULL
 			AND sku_name IS NOT NULL
-		GROUP BY workspace_id, sku_name
+		GROUP BY workspace_id, sku_name, usage_unit
 		ORDER BY workspace_id, sku_name
-	`, interval)
+	`, recordTypeRetraction, recordTypeRestatement, interval)
 }
 
 // BuildBillingCostEstimateQuery returns the query for cost estimates with configurable lookback.
-// Uses current prices only (price_end_time IS NULL) to avoid expensive temporal JOINs.
-func BuildBillingCostEstimateQuery(lookback time.Duration) string {
+// The price join depends on mode and fx (see billingPriceJoin).
+func BuildBillingCostEstimateQuery(lookback time.Duration, mode string, fx *FXRates) string {
 	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
 	return fmt.Sprintf(`
-		WITH current_prices AS (
-			SELECT DISTINCT sku_name, cloud, pricing.default as unit_price
-			FROM system.billing.list_prices
-			WHERE price_end_time IS NULL
-		)
+		WITH %[1]s
 		SELECT 
 			u.workspace_id,
 			u.sku_name,
+			%[3]s as currency_code,
 			SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_estimate_usd
 		FROM system.billing.usage u
-		LEFT JOIN current_prices p ON u.sku_name = p.sku_name AND u.cloud = p.cloud
-		WHERE u.usage_date >= current_date() - INTERVAL %s
+		%[2]s
+		WHERE u.usage_date >= current_date() - INTERVAL %[4]s
 			AND u.workspace_id IS NOT NULL
 			AND u.sku_name IS NOT NULL
-		GROUP BY u.workspace_id, u.sku_name
+		GROUP BY u.workspace_id, u.sku_name, %[3]s
 		ORDER BY u.workspace_id, u.sku_name
-	`, interval)
+	`, prices.cte, prices.join, prices.currency, interval)
+}
+
+// BuildBillingEffectiveCostQuery returns the query for DBUs and list-price cost per workspace, SKU,
+// billing origin product and usage date, with configurable lookback. Pricing overrides are
+// applied to these rows by date, so the query does not depend on the overrides file.
+func BuildBillingEffectiveCostQuery(lookback time.Duration, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
+	return fmt.Sprintf(`
+		WITH %[1]s
+		SELECT 
+			u.workspace_id,
+			u.sku_name,
+			u.billing_origin_product,
+			u.usage_date,
+			%[3]s as currency_code,
+			SUM(u.usage_quantity) as dbus_total,
+			SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_estimate_usd
+		FROM system.billing.usage u
+		%[2]s
+		WHERE u.usage_date >= current_date() - INTERVAL %[4]s
+			AND u.workspace_id IS NOT NULL
+			AND u.sku_name IS NOT NULL
+		GROUP BY u.workspace_id, u.sku_name, u.billing_origin_product, u.usage_date, %[3]s
+	`, prices.cte, prices.join, prices.currency, interval)
+}
+
+// Calendar periods reported by the calendar billing metrics.
+const (
+	periodToday         = "today"
+	periodYesterday     = "yesterday"
+	periodMonthToDate   = "month_to_date"
+	periodPreviousMonth = "previous_month"
+)
+
+// calendarPeriods lists the calendar periods in query column order.
+var calendarPeriods = []string{periodToday, periodYesterday, periodMonthToDate, periodPreviousMonth}
+
+// calendarBounds returns the start of today, yesterday, this month and the previous month in loc.
+func calendarBounds(now time.Time, loc *time.Location) (today, yesterday, month, previousMonth time.Time) {
+	now = now.In(loc)
+	today = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	yesterday = today.AddDate(0, 0, -1)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	previousMonth = month.AddDate(0, -1, 0)
+	return today, yesterday, month, previousMonth
+}
+
+// usageDateInterval returns the lookback for a usage_date partition filter covering usage since
+// start. usage_date is a UTC date, so the filter is padded by a day for time zones ahead of UTC;
+// queries still bound usage_start_time exactly.
+func usageDateInterval(now, start time.Time) string {
+	days := int(now.Sub(start).Hours()/24) + 2
+	return durationToSQLInterval(time.Duration(days) * 24 * time.Hour)
+}
+
+// BuildBillingCalendarQuery returns the query for DBUs and list-price cost per workspace and SKU
+// for today, yesterday, month-to-date and the previous full month. Period boundaries are midnight
+// in loc and are compared against usage_start_time, so they do not depend on the session time zone.
+func BuildBillingCalendarQuery(now time.Time, loc *time.Location, mode string, fx *FXRates) string {
+	today, yesterday, month, previousMonth := calendarBounds(now, loc)
+	ts := func(t time.Time) string { return "TIMESTAMP '" + t.Format(time.RFC3339) + "'" }
+
+	interval := usageDateInterval(now, previousMonth)
+	prices := billingPriceJoin(mode, interval, fx)
+
+	return fmt.Sprintf(`
+		WITH %[1]s
+		SELECT 
+			u.workspace_id,
+			u.sku_name,
+			u.usage_unit,
+			%[8]s as currency_code,
+			SUM(CASE WHEN u.usage_start_time >= %[3]s THEN u.usage_quantity ELSE 0 END) as today_dbus,
+			SUM(CASE WHEN u.usage_start_time >= %[4]s AND u.usage_start_time < %[3]s THEN u.usage_quantity ELSE 0 END) as yesterday_dbus,
+			SUM(CASE WHEN u.usage_start_time >= %[5]s THEN u.usage_quantity ELSE 0 END) as month_to_date_dbus,
+			SUM(CASE WHEN u.usage_start_time < %[5]s THEN u.usage_quantity ELSE 0 END) as previous_month_dbus,
+			SUM(CASE WHEN u.usage_start_time >= %[3]s THEN u.usage_quantity * COALESCE(p.unit_price, 0) ELSE 0 END) as today_cost_usd,
+			SUM(CASE WHEN u.usage_start_time >= %[4]s AND u.usage_start_time < %[3]s THEN u.usage_quantity * COALESCE(p.unit_price, 0) ELSE 0 END) as yesterday_cost_usd,
+			SUM(CASE WHEN u.usage_start_time >= %[5]s THEN u.usage_quantity * COALESCE(p.unit_price, 0) ELSE 0 END) as month_to_date_cost_usd,
+			SUM(CASE WHEN u.usage_start_time < %[5]s THEN u.usage_quantity * COALESCE(p.unit_price, 0) ELSE 0 END) as previous_month_cost_usd
+		FROM system.billing.usage u
+		%[2]s
+		WHERE u.usage_date >= current_date() - INTERVAL %[7]s
+			AND u.usage_start_time >= %[6]s
+			AND u.workspace_id IS NOT NULL
+			AND u.sku_name IS NOT NULL
+		GROUP BY u.workspace_id, u.sku_name, u.usage_unit, %[8]s
+		ORDER BY u.workspace_id, u.sku_name
+	`, prices.cte, prices.join, ts(today), ts(yesterday), ts(month), ts(previousMonth), interval, prices.currency)
+}
+
+// BuildBillingAnomalyQuery returns the query for list-price cost per workspace and SKU on the day
+// offsetDays before today in loc and on the same weekday in each of the previous weeks. Rows carry
+// weeks_ago, 0 for the scored day and 1 to weeks for the baseline days. Days with no usage have no
+// row.
+func BuildBillingAnomalyQuery(now time.Time, loc *time.Location, weeks, offsetDays int, mode string, fx *FXRates) string {
+	if weeks == 0 {
+		weeks = DefaultBillingAnomalyWeeks
+	}
+	if offsetDays == 0 {
+		offsetDays = DefaultBillingAnomalyOffset
+	}
+	today, _, _, _ := calendarBounds(now, loc)
+	scored := today.AddDate(0, 0, -offsetDays)
+	ts := func(t time.Time) string { return "TIMESTAMP '" + t.Format(time.RFC3339) + "'" }
+
+	cases := make([]string, weeks+1)
+	for k := range cases {
+		day := scored.AddDate(0, 0, -7*k)
+		cases[k] = fmt.Sprintf("WHEN u.usage_start_time >= %s AND u.usage_start_time < %s THEN %d", ts(day), ts(day.AddDate(0, 0, 1)), k)
+	}
+	start := scored.AddDate(0, 0, -7*weeks)
+
+	interval := usageDateInterval(now, start)
+	prices := billingPriceJoin(mode, interval, fx)
+
+	return fmt.Sprintf(`
+		WITH %[1]s
+		SELECT 
+			workspace_id,
+			sku_name,
+			currency_code,
+			weeks_ago,
+			SUM(cost) as cost_usd
+		FROM (
+			SELECT 
+				u.workspace_id,
+				u.sku_name,
+				%[3]s as currency_code,
+				CASE %[4]s END as weeks_ago,
+				u.usage_quantity * COALESCE(p.unit_price, 0) as cost
+			FROM system.billing.usage u
+			%[2]s
+			WHERE u.usage_date >= current_date() - INTERVAL %[5]s
+				AND u.usage_start_time >= %[6]s
+				AND u.workspace_id IS NOT NULL
+				AND u.sku_name IS NOT NULL
+		)
+		WHERE weeks_ago IS NOT NULL
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 1, 2, 3, 4
+	`, prices.cte, prices.join, prices.currency, strings.Join(cases, "\n\t\t\t\t\t"), interval, ts(start))
+}
+
+// budgetFilter returns the SQL condition matching the usage covered by a budget.
+func budgetFilter(b Budget) string {
+	var conds []string
+	if b.WorkspaceID != "" {
+		conds = append(conds, "u.workspace_id = "+sqlStringLiteral(b.WorkspaceID))
+	}
+	if b.SKU != "" {
+		conds = append(conds, "u.sku_name = "+sqlStringLiteral(b.SKU))
+	}
+	keys := make([]string, 0, len(b.Tags))
+	for key := range b.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		conds = append(conds, fmt.Sprintf("u.custom_tags[%s] = %s", sqlStringLiteral(key), sqlStringLiteral(b.Tags[key])))
+	}
+	if len(conds) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// BuildBudgetSpendQuery returns the query for list-price cost per hour and currency for each
+// budget, from the start of the current month in loc or forecastHistoryDays before today,
+// whichever is earlier. Hours are returned as Unix seconds so they can be bucketed into days in
+// loc. Costs in different currencies are never summed; prices without an FX rate keep their own
+// currency_code.
+// Returns an empty string when no budget is configured.
+func BuildBudgetSpendQuery(now time.Time, loc *time.Location, budgets []Budget, mode string, fx *FXRates) string {
+	if len(budgets) == 0 {
+		return ""
+	}
+
+	today, _, month, _ := calendarBounds(now, loc)
+	start := today.AddDate(0, 0, -forecastHistoryDays)
+	if month.Before(start) {
+		start = month
+	}
+
+	interval := usageDateInterval(now, start)
+	prices := billingPriceJoin(mode, interval, fx)
+
+	columns := make([]string, len(budgets))
+	for i, b := range budgets {
+		columns[i] = fmt.Sprintf("SUM(CASE WHEN %s THEN u.usage_quantity * COALESCE(p.unit_price, 0) ELSE 0 END) as budget_%d", budgetFilter(b), i)
+	}
+
+	return fmt.Sprintf(`
+		WITH %s
+		SELECT 
+			unix_timestamp(u.usage_start_time) div 3600 * 3600 as hour_start,
+			%s as currency_code,
+			%s
+		FROM system.billing.usage u
+		%s
+		WHERE u.usage_date >= current_date() - INTERVAL %s
+			AND u.usage_start_time >= TIMESTAMP '%s'
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, prices.cte, prices.currency, strings.Join(columns, ",\n\t\t\t"), prices.join, interval, start.Format(time.RFC3339))
+}
+
+// BuildChargebackQuery returns the query for usage and list-price cost per chargeback group,
+// workspace and SKU over the calendar month starting at month (midnight on the first, in its
+// location). Usage without a value for the group reports placeholder. Returns an empty string
+// when groupBy is not a supported grouping (see chargebackGroupExpr).
+func BuildChargebackQuery(now, month time.Time, groupBy, placeholder, mode string, fx *FXRates) string {
+	group, ok := chargebackGroupExpr(groupBy, placeholder)
+	if !ok {
+		return ""
+	}
+	end := month.AddDate(0, 1, 0)
+
+	// Historical prices must cover the whole month, however long ago it was
+	days := int(now.Sub(month).Hours()/24) + 2
+	prices := billingPriceJoin(mode, durationToSQLInterval(time.Duration(days)*24*time.Hour), fx)
+
+	// usage_date is a UTC date, so pad the partition filter by a day on both sides
+	return fmt.Sprintf(`
+		WITH %[1]s
+		SELECT 
+			%[3]s as chargeback_group,
+			u.workspace_id,
+			u.sku_name,
+			u.usage_unit,
+			%[4]s as currency_code,
+			SUM(u.usage_quantity) as usage_quantity,
+			SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost
+		FROM system.billing.usage u
+		%[2]s
+		WHERE u.usage_date >= DATE '%[5]s'
+			AND u.usage_date <= DATE '%[6]s'
+			AND u.usage_start_time >= TIMESTAMP '%[7]s'
+			AND u.usage_start_time < TIMESTAMP '%[8]s'
+			AND u.workspace_id IS NOT NULL
+			AND u.sku_name IS NOT NULL
+		GROUP BY 1, 2, 3, 4, 5
+		ORDER BY 1, 2, 3, 4, 5
+	`, prices.cte, prices.join, group, prices.currency,
+		month.AddDate(0, 0, -1).Format(time.DateOnly), end.Format(time.DateOnly),
+		month.Format(time.RFC3339), end.Format(time.RFC3339))
 }
 
 // BuildPriceChangeEventsQuery returns the query for price change events with configurable lookback.
@@ -94,8 +433,230 @@ func BuildPriceChangeEventsQuery(lookback time.Duration) string {
 	`, interval)
 }
 
+// BuildBillingByProductQuery returns the query for DBUs and list-price cost per workspace and
+// billing_origin_product (JOBS, DLT, SQL, MODEL_SERVING, INTERACTIVE, ...) with configurable lookback.
+func BuildBillingByProductQuery(lookback time.Duration, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
+	return fmt.Sprintf(`
+		WITH %[1]s
+		SELECT 
+			u.workspace_id,
+			u.billing_origin_product,
+			u.usage_unit,
+			%[3]s as currency_code,
+			SUM(u.usage_quantity) as dbus_total,
+			SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_estimate_usd
+		FROM system.billing.usage u
+		%[2]s
+		WHERE u.usage_date >= current_date() - INTERVAL %[4]s
+			AND u.workspace_id IS NOT NULL
+			AND u.billing_origin_product IS NOT NULL
+		GROUP BY u.workspace_id, u.billing_origin_product, u.usage_unit, %[3]s
+		ORDER BY u.workspace_id, u.billing_origin_product
+	`, prices.cte, prices.join, prices.currency, interval)
+}
+
+// Compute types reported in the compute_type label of the by-compute billing metrics.
+const (
+	computeServerless = "serverless"
+	computeClassic    = "classic"
+)
+
+// BuildBillingByComputeQuery returns the query for DBUs and list-price cost per workspace and
+// billing_origin_product, split into serverless and classic compute and by Photon use, with
+// configurable lookback. Usage counts as serverless when product_features.is_serverless is set
+// or its SKU is a serverless SKU, and as Photon when product_features.is_photon is set or its
+// SKU is a Photon SKU; the SKU patterns cover records without product features.
+func BuildBillingByComputeQuery(lookback time.Duration, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
+	return fmt.Sprintf(`
+		WITH %[1]s
+		SELECT 
+			u.workspace_id,
+			u.billing_origin_product,
+			CASE WHEN u.product_features.is_serverless OR u.sku_name LIKE '%%SERVERLESS%%' THEN '%[5]s' ELSE '%[6]s' END as compute_type,
+			CASE WHEN u.product_features.is_photon OR u.sku_name LIKE '%%PHOTON%%' THEN 'true' ELSE 'false' END as photon,
+			u.usage_unit,
+			%[3]s as currency_code,
+			SUM(u.usage_quantity) as dbus_total,
+			SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_estimate_usd
+		FROM system.billing.usage u
+		%[2]s
+		WHERE u.usage_date >= current_date() - INTERVAL %[4]s
+			AND u.workspace_id IS NOT NULL
+			AND u.billing_origin_product IS NOT NULL
+		GROUP BY 1, 2, 3, 4, 5, 6
+		ORDER BY 1, 2, 3, 4
+	`, prices.cte, prices.join, prices.currency, interval, computeServerless, computeClassic)
+}
+
+// BuildBillingByIdentityQuery returns the query for DBUs and list-price cost per workspace and
+// identity, the run_as identity of the usage or else its owner from identity_metadata. Per
+// workspace, only the limit identities with the highest cost are kept; the rest are rolled up
+// into identity '__other__'. Usage without an identity is not included.
+func BuildBillingByIdentityQuery(lookback time.Duration, limit int, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
+	if limit == 0 {
+		limit = DefaultBillingIdentityLimit
+	}
+	return fmt.Sprintf(`
+		WITH %[1]s,
+		identity_usage AS (
+			SELECT 
+				*,
+				DENSE_RANK() OVER (PARTITION BY workspace_id ORDER BY identity_cost DESC, identity) as cost_rank
+			FROM (
+				SELECT 
+					u.workspace_id,
+					COALESCE(u.identity_metadata.run_as, u.identity_metadata.owned_by) as identity,
+					u.usage_unit,
+					%[3]s as currency_code,
+					SUM(u.usage_quantity) as dbus_total,
+					SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_estimate_usd,
+					SUM(SUM(u.usage_quantity * COALESCE(p.unit_price, 0))) OVER (
+						PARTITION BY u.workspace_id, COALESCE(u.identity_metadata.run_as, u.identity_metadata.owned_by)
+					) as identity_cost
+				FROM system.billing.usage u
+				%[2]s
+				WHERE u.usage_date >= current_date() - INTERVAL %[4]s
+					AND u.workspace_id IS NOT NULL
+					AND COALESCE(u.identity_metadata.run_as, u.identity_metadata.owned_by) IS NOT NULL
+				GROUP BY 1, 2, 3, 4
+			)
+		)
+		SELECT 
+			workspace_id,
+			CASE WHEN cost_rank <= %[5]d THEN identity ELSE '%[6]s' END as identity,
+			usage_unit,
+			currency_code,
+			SUM(dbus_total) as dbus_total,
+			SUM(cost_estimate_usd) as cost_estimate_usd
+		FROM identity_usage
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 1, 2
+	`, prices.cte, prices.join, prices.currency, interval, limit, attributionOther)
+}
+
+// BuildBillingAttributionQuery returns the query for DBUs and list-price cost per workspace and
+// usage_metadata attribution value, for each key in limits. Per key and workspace, only the limit
+// values with the most DBUs are kept; the rest are rolled up into that workspace's attribution_value
+// '__other__'. Values are ranked by their DBUs across usage units. Returns an empty string when no key
+// is enabled.
+func BuildBillingAttributionQuery(lookback time.Duration, limits map[string]int, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+
+	var ctes, selects []string
+	for _, key := range billingAttributionKeys {
+		limit, ok := limits[key]
+		if !ok {
+			continue
+		}
+		if limit == 0 {
+			limit = DefaultBillingAttributionLimit
+		}
+		ctes = append(ctes, fmt.Sprintf(`
+		attr_%[1]s AS (
+			SELECT 
+				*,
+				DENSE_RANK() OVER (PARTITION BY workspace_id ORDER BY value_dbus DESC, attribution_value) as dbus_rank
+			FROM (
+				SELECT 
+					workspace_id,
+					usage_metadata.%[1]s as attribution_value,
+					usage_unit,
+					currency_code,
+					SUM(usage_quantity) as dbus_total,
+					SUM(cost) as cost_estimate_usd,
+					SUM(SUM(usage_quantity)) OVER (PARTITION BY workspace_id, usage_metadata.%[1]s) as value_dbus
+				FROM attributed_usage
+				WHERE usage_metadata.%[1]s IS NOT NULL
+				GROUP BY workspace_id, usage_metadata.%[1]s, usage_unit, currency_code
+			)
+		)`, key))
+		selects = append(selects, fmt.Sprintf(`
+		SELECT 
+			'%[1]s' as attribution_key,
+			workspace_id,
+			CASE WHEN dbus_rank <= %[2]d THEN attribution_value ELSE '%[3]s' END as attribution_value,
+			usage_unit,
+			currency_code,
+			SUM(dbus_total) as dbus_total,
+			SUM(cost_estimate_usd) as cost_estimate_usd
+		FROM attr_%[1]s
+		GROUP BY 1, 2, 3, 4, 5`, key, limit, attributionOther))
+	}
+	if len(selects) == 0 {
+		return ""
+	}
+	prices := billingPriceJoin(mode, interval, fx)
+
+	return fmt.Sprintf(`
+		WITH %s,
+		attributed_usage AS (
+			SELECT 
+				u.workspace_id,
+				u.usage_metadata,
+				u.usage_unit,
+				%s as currency_code,
+				u.usage_quantity,
+				u.usage_quantity * COALESCE(p.unit_price, 0) as cost
+			FROM system.billing.usage u
+			%s
+			WHERE u.usage_date >= current_date() - INTERVAL %s
+				AND u.workspace_id IS NOT NULL
+		),%s
+		%s
+	`, prices.cte, prices.currency, prices.join, interval, strings.Join(ctes, ","), strings.Join(selects, "\n\t\tUNION ALL"))
+}
+
+// BuildBillingByTagQuery returns the query for DBUs and list-price cost per workspace and
+// combination of the given custom_tags keys, with configurable lookback. Usage without a tag
+// (or with an empty value) reports placeholder for that tag. Returns an empty string when no
+// tag keys are given.
+func BuildBillingByTagQuery(lookback time.Duration, tagKeys []string, placeholder, mode string, fx *FXRates) string {
+	if len(tagKeys) == 0 {
+		return ""
+	}
+	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
+
+	columns := make([]string, len(tagKeys))
+	groupBy := []string{"1"}
+	for i, key := range tagKeys {
+		columns[i] = fmt.Sprintf("COALESCE(NULLIF(u.custom_tags[%s], ''), %s) as tag_%d",
+			sqlStringLiteral(key), sqlStringLiteral(placeholder), i)
+		groupBy = append(groupBy, fmt.Sprintf("%d", i+2))
+	}
+	columns = append(columns, "u.usage_unit", prices.currency+" as currency_code")
+	groupBy = append(groupBy, fmt.Sprintf("%d", len(tagKeys)+2), fmt.Sprintf("%d", len(tagKeys)+3))
+
+	return fmt.Sprintf(`
+		WITH %s
+		SELECT 
+			u.workspace_id,
+			%s,
+			SUM(u.usage_quantity) as dbus_total,
+			SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_estimate_usd
+		FROM system.billing.usage u
+		%s
+		WHERE u.usage_date >= current_date() - INTERVAL %s
+			AND u.workspace_id IS NOT NULL
+		GROUP BY %s
+	`, prices.cte, strings.Join(columns, ",\n\t\t\t"), prices.join, interval, strings.Join(groupBy, ", "))
+}
+
 // ===== Jobs Query Builders =====
 
+// latestJobNames selects the current name and tags of every job that is not deleted. It is the
+// single definition used to resolve job_id to job_name in every job query.
+const latestJobNames = `SELECT workspace_id, job_id, name, tags
+			FROM system.lakeflow.jobs
+			WHERE delete_time IS NULL
+			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, job_id ORDER BY change_time DESC) = 1`
+
 // BuildJobRunsQuery returns the query for job run counts with configurable lookback.
 func BuildJobRunsQuery(lookback time.Duration) string {
 	interval := durationToSQLInterval(lookback)
@@ -107,14 +668,11 @@ func BuildJobRunsQuery(lookback time.Duration) string {
 			COUNT(*) as run_count
 		FROM system.lakeflow.job_run_timeline t
 		LEFT JOIN (
-			SELECT workspace_id, job_id, name
-			FROM system.lakeflow.jobs
-			WHERE delete_time IS NULL
-			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, job_id ORDER BY change_time DESC) = 1
+			%s
 		) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
 		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 		GROUP BY t.workspace_id, t.job_id, j.name
-	`, interval)
+	`, latestJobNames, interval)
 }
 
 // BuildJobRunStatusQuery returns the query for job status counts with configurable lookback.
@@ -129,15 +687,50 @@ func BuildJobRunStatusQuery(lookback time.Duration) string {
 			COUNT(*) as run_count
 		FROM system.lakeflow.job_run_timeline t
 		LEFT JOIN (
-			SELECT workspace_id, job_id, name
-			FROM system.lakeflow.jobs
-			WHERE delete_time IS NULL
-			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, job_id ORDER BY change_time DESC) = 1
+			%s
 		) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
 		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 			AND t.result_state IS NOT NULL
 		GROUP BY t.workspace_id, t.job_id, j.name, t.result_state
-	`, interval)
+	`, latestJobNames, interval)
+}
+
+// BuildJobRunTerminationsQuery returns the query for job run counts by termination code with
+// configurable lookback.
+func BuildJobRunTerminationsQuery(lookback time.Duration) string {
+	interval := durationToSQLInterval(lookback)
+	return fmt.Sprintf(`
+		SELECT 
+			t.workspace_id,
+			t.job_id,
+			COALESCE(j.name, CONCAT('job-', t.job_id)) as job_name,
+			t.termination_code,
+			COUNT(*) as run_count
+		FROM system.lakeflow.job_run_timeline t
+		LEFT JOIN (
+			%s
+		) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
+		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
+			AND t.termination_code IS NOT NULL
+		GROUP BY t.workspace_id, t.job_id, j.name, t.termination_code
+	`, latestJobNames, interval)
+}
+
+// jobRunDurations returns the subquery for the duration of each job run that ended in the
+// lookback window, with the job name resolved.
+func jobRunDurations(interval string) string {
+	return fmt.Sprintf(`SELECT 
+				t.workspace_id,
+				t.job_id,
+				COALESCE(j.name, CONCAT('job-', t.job_id)) as job_name,
+				unix_timestamp(t.period_end_time) - unix_timestamp(t.period_start_time) as duration_seconds
+			FROM system.lakeflow.job_run_timeline t
+			LEFT JOIN (
+				%s
+			) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
+			WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
+				AND t.period_end_time IS NOT NULL
+				AND t.period_end_time > t.period_start_time`, latestJobNames, interval)
 }
 
 // BuildJobRunDurationQuery returns the query for job duration quantiles with configurable lookback.
@@ -151,25 +744,175 @@ func BuildJobRunDurationQuery(lookback time.Duration) string {
 			percentile_approx(duration_seconds, 0.5) as p50,
 			percentile_approx(duration_seconds, 0.95) as p95,
 			percentile_approx(duration_seconds, 0.99) as p99
+		FROM (
+			%s
+		)
+		GROUP BY workspace_id, job_id, job_name
+	`, jobRunDurations(interval))
+}
+
+// histogramColumns returns the aggregate columns of a histogram query over duration_seconds: one
+// cumulative count per bucket upper bound (bucket_0 for buckets[0] and so on), the run count and
+// the total duration, as a Prometheus histogram expects.
+func histogramColumns(buckets []float64) string {
+	columns := make([]string, len(buckets))
+	for i, bound := range buckets {
+		columns[i] = fmt.Sprintf("SUM(CASE WHEN duration_seconds <= %s THEN 1 ELSE 0 END) as bucket_%d",
+			strconv.FormatFloat(bound, 'f', -1, 64), i)
+	}
+	columns = append(columns, "COUNT(*) as run_count", "SUM(duration_seconds) as duration_sum")
+	return strings.Join(columns, ",\n\t\t\t")
+}
+
+// BuildJobRunDurationHistogramQuery returns the query for the job run duration distribution with
+// configurable lookback (see histogramColumns).
+func BuildJobRunDurationHistogramQuery(lookback time.Duration, buckets []float64) string {
+	interval := durationToSQLInterval(lookback)
+	return fmt.Sprintf(`
+		SELECT 
+			workspace_id,
+			job_id,
+			job_name,
+			%s
+		FROM (
+			%s
+		)
+		GROUP BY workspace_id, job_id, job_name
+	`, histogramColumns(buckets), jobRunDurations(interval))
+}
+
+// Phases of a job or task run, from the timeline duration columns. Task runs are not queued.
+const (
+	runPhaseQueue     = "queue"
+	runPhaseSetup     = "setup"
+	runPhaseExecution = "execution"
+	runPhaseCleanup   = "cleanup"
+)
+
+// BuildJobRunPhaseDurationHistogramQuery returns the query for the distribution of the queue,
+// setup, execution and cleanup durations of job runs that ended in the lookback window, per job
+// and phase (see histogramColumns). The duration columns are set on the final row of each run.
+func BuildJobRunPhaseDurationHistogramQuery(lookback time.Duration, buckets []float64) string {
+	interval := durationToSQLInterval(lookback)
+	return fmt.Sprintf(`
+		SELECT 
+			workspace_id,
+			job_id,
+			job_name,
+			phase,
+			%[1]s
 		FROM (
 			SELECT 
 				t.workspace_id,
 				t.job_id,
 				COALESCE(j.name, CONCAT('job-', t.job_id)) as job_name,
-				unix_timestamp(t.period_end_time) - unix_timestamp(t.period_start_time) as duration_seconds
+				p.phase,
+				p.duration_seconds
 			FROM system.lakeflow.job_run_timeline t
 			LEFT JOIN (
-				SELECT workspace_id, job_id, name
-				FROM system.lakeflow.jobs
-				WHERE delete_time IS NULL
-				QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, job_id ORDER BY change_time DESC) = 1
+				%[3]s
 			) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
-			WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
-				AND t.period_end_time IS NOT NULL
-				AND t.period_end_time > t.period_start_time
+			LATERAL VIEW stack(4,
+				'%[4]s', t.queue_duration_seconds,
+				'%[5]s', t.setup_duration_seconds,
+				'%[6]s', t.execution_duration_seconds,
+				'%[7]s', t.cleanup_duration_seconds
+			) p AS phase, duration_seconds
+			WHERE t.period_start_time >= current_timestamp() - INTERVAL %[2]s
+				AND t.result_state IS NOT NULL
+				AND p.duration_seconds IS NOT NULL
 		)
-		GROUP BY workspace_id, job_id, job_name
-	`, interval)
+		GROUP BY workspace_id, job_id, job_name, phase
+	`, histogramColumns(buckets), interval, latestJobNames, runPhaseQueue, runPhaseSetup, runPhaseExecution, runPhaseCleanup)
+}
+
+// BuildTaskRunPhaseDurationHistogramQuery returns the query for the distribution of the setup,
+// execution and cleanup durations of task runs that ended in the lookback window, per job, task
+// key and phase (see histogramColumns).
+func BuildTaskRunPhaseDurationHistogramQuery(lookback time.Duration, buckets []float64) string {
+	interval := durationToSQLInterval(lookback)
+	return fmt.Sprintf(`
+		SELECT 
+			workspace_id,
+			job_id,
+			job_name,
+			task_key,
+			phase,
+			%[1]s
+		FROM (
+			SELECT 
+				t.workspace_id,
+				t.job_id,
+				COALESCE(j.name, CONCAT('job-', t.job_id)) as job_name,
+				t.task_key,
+				p.phase,
+				p.duration_seconds
+			FROM system.lakeflow.job_task_run_timeline t
+			LEFT JOIN (
+				%[3]s
+			) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
+			LATERAL VIEW stack(3,
+				'%[4]s', t.setup_duration_seconds,
+				'%[5]s', t.execution_duration_seconds,
+				'%[6]s', t.cleanup_duration_seconds
+			) p AS phase, duration_seconds
+			WHERE t.period_start_time >= current_timestamp() - INTERVAL %[2]s
+				AND t.result_state IS NOT NULL
+				AND p.duration_seconds IS NOT NULL
+		)
+		GROUP BY workspace_id, job_id, job_name, task_key, phase
+	`, histogramColumns(buckets), interval, latestJobNames, runPhaseSetup, runPhaseExecution, runPhaseCleanup)
+}
+
+// Final result_state of a successful job run and pipeline update.
+const (
+	jobResultSucceeded      = "SUCCEEDED"
+	pipelineResultCompleted = "COMPLETED"
+)
+
+// BuildJobCostQuery returns the query for list-price cost per job over the billing window, from
+// usage_metadata.job_id, with the number of successful runs of the job in the same window.
+func BuildJobCostQuery(lookback time.Duration, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
+	return fmt.Sprintf(`
+		WITH %[1]s,
+		job_cost AS (
+			SELECT 
+				u.workspace_id,
+				u.usage_metadata.job_id as job_id,
+				%[3]s as currency_code,
+				SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_usd
+			FROM system.billing.usage u
+			%[2]s
+			WHERE u.usage_date >= current_date() - INTERVAL %[4]s
+				AND u.workspace_id IS NOT NULL
+				AND u.usage_metadata.job_id IS NOT NULL
+			GROUP BY 1, 2, 3
+		),
+		job_successes AS (
+			SELECT 
+				workspace_id,
+				job_id,
+				COUNT(DISTINCT run_id) as successful_runs
+			FROM system.lakeflow.job_run_timeline
+			WHERE period_start_time >= current_date() - INTERVAL %[4]s
+				AND result_state = '%[6]s'
+			GROUP BY 1, 2
+		)
+		SELECT 
+			c.workspace_id,
+			c.job_id,
+			COALESCE(j.name, CONCAT('job-', c.job_id)) as job_name,
+			c.currency_code,
+			c.cost_usd,
+			COALESCE(s.successful_runs, 0) as successful_runs
+		FROM job_cost c
+		LEFT JOIN job_successes s ON c.workspace_id = s.workspace_id AND c.job_id = s.job_id
+		LEFT JOIN (
+			%[5]s
+		) j ON c.workspace_id = j.workspace_id AND c.job_id = j.job_id
+	`, prices.cte, prices.join, prices.currency, interval, latestJobNames, jobResultSucceeded)
 }
 
 // BuildTaskRetriesQuery returns the query for task retries with configurable lookback.
@@ -184,51 +927,96 @@ func BuildTaskRetriesQuery(lookback time.Duration) string {
 			COUNT(*) - COUNT(DISTINCT CONCAT(t.job_run_id, '-', t.task_key)) as retry_count
 		FROM system.lakeflow.job_task_run_timeline t
 		LEFT JOIN (
-			SELECT workspace_id, job_id, name
-			FROM system.lakeflow.jobs
-			WHERE delete_time IS NULL
-			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, job_id ORDER BY change_time DESC) = 1
+			%s
 		) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
 		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 			AND t.job_run_id IS NOT NULL
 		GROUP BY t.workspace_id, t.job_id, j.name, t.task_key
 		HAVING COUNT(*) > COUNT(DISTINCT CONCAT(t.job_run_id, '-', t.task_key))
-	`, interval)
+	`, latestJobNames, interval)
 }
 
-// BuildJobSLAMissQuery returns the query for SLA misses with configurable lookback and threshold.
-func BuildJobSLAMissQuery(lookback time.Duration, slaThresholdSeconds int) string {
+// BuildJobSLAMissQuery returns the query for the SLA threshold and SLA misses of each job with
+// runs in the lookback window. A job's threshold is the first of slas that matches it, else the
+// positive whole number of seconds in its tagKey tag, else defaultThresholdSeconds.
+func BuildJobSLAMissQuery(lookback time.Duration, defaultThresholdSeconds int, slas []JobSLA, tagKey string) string {
 	interval := durationToSQLInterval(lookback)
 	return fmt.Sprintf(`
 		SELECT 
 			workspace_id,
 			job_id,
 			job_name,
-			COUNT(*) as sla_miss_count
+			threshold_seconds,
+			SUM(CASE WHEN duration_seconds > threshold_seconds THEN 1 ELSE 0 END) as sla_miss_count
 		FROM (
 			SELECT 
-				t.workspace_id,
-				t.job_id,
-				COALESCE(j.name, CONCAT('job-', t.job_id)) as job_name,
-				unix_timestamp(t.period_end_time) - unix_timestamp(t.period_start_time) as duration_seconds
-			FROM system.lakeflow.job_run_timeline t
-			LEFT JOIN (
-				SELECT workspace_id, job_id, name
-				FROM system.lakeflow.jobs
-				WHERE delete_time IS NULL
-				QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, job_id ORDER BY change_time DESC) = 1
-			) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
-			WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
-				AND t.period_end_time IS NOT NULL
-				AND t.period_end_time > t.period_start_time
+				workspace_id,
+				job_id,
+				job_name,
+				duration_seconds,
+				%[3]s as threshold_seconds
+			FROM (
+				SELECT 
+					t.workspace_id,
+					t.job_id,
+					COALESCE(j.name, CONCAT('job-', t.job_id)) as job_name,
+					j.tags[%[2]s] as sla_tag,
+					unix_timestamp(t.period_end_time) - unix_timestamp(t.period_start_time) as duration_seconds
+				FROM system.lakeflow.job_run_timeline t
+				LEFT JOIN (
+					%[4]s
+				) j ON t.workspace_id = j.workspace_id AND t.job_id = j.job_id
+				WHERE t.period_start_time >= current_timestamp() - INTERVAL %[1]s
+					AND t.period_end_time IS NOT NULL
+					AND t.period_end_time > t.period_start_time
+			)
 		)
-		WHERE duration_seconds > %d
-		GROUP BY workspace_id, job_id, job_name
-	`, interval, slaThresholdSeconds)
+		GROUP BY workspace_id, job_id, job_name, threshold_seconds
+	`, interval, sqlStringLiteral(tagKey), jobSLAThreshold(slas, defaultThresholdSeconds), latestJobNames)
+}
+
+// BuildJobRunsActiveQuery returns the query for the number of runs in progress and the age of
+// the oldest one per job. A run is in progress when none of its timeline rows since activeLookback
+// has a result_state and one of them ended within lookback, so runs whose final row is missing
+// drop out once the timeline stops reporting them. Ages are measured from the first row since
+// activeLookback, so they are capped at activeLookback.
+func BuildJobRunsActiveQuery(lookback, activeLookback time.Duration) string {
+	return fmt.Sprintf(`
+		WITH active_runs AS (
+			SELECT 
+				workspace_id,
+				job_id,
+				run_id,
+				MIN(period_start_time) as start_time
+			FROM system.lakeflow.job_run_timeline
+			WHERE period_start_time >= current_timestamp() - INTERVAL %[2]s
+			GROUP BY workspace_id, job_id, run_id
+			HAVING COUNT(result_state) = 0
+				AND MAX(COALESCE(period_end_time, period_start_time)) >= current_timestamp() - INTERVAL %[1]s
+		)
+		SELECT 
+			a.workspace_id,
+			a.job_id,
+			COALESCE(j.name, CONCAT('job-', a.job_id)) as job_name,
+			COUNT(*) as active_runs,
+			MAX(unix_timestamp(current_timestamp()) - unix_timestamp(a.start_time)) as oldest_age_seconds
+		FROM active_runs a
+		LEFT JOIN (
+			%[3]s
+		) j ON a.workspace_id = j.workspace_id AND a.job_id = j.job_id
+		GROUP BY a.workspace_id, a.job_id, j.name
+	`, durationToSQLInterval(lookback), durationToSQLInterval(activeLookback), latestJobNames)
 }
 
 // ===== Pipelines Query Builders =====
 
+// latestPipelineNames selects the current name of every pipeline that is not deleted. It is the
+// single definition used to resolve pipeline_id to pipeline_name in every pipeline query.
+const latestPipelineNames = `SELECT workspace_id, pipeline_id, name
+			FROM system.lakeflow.pipelines
+			WHERE delete_time IS NULL
+			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, pipeline_id ORDER BY change_time DESC) = 1`
+
 // BuildPipelineRunsQuery returns the query for pipeline run counts with configurable lookback.
 func BuildPipelineRunsQuery(lookback time.Duration) string {
 	interval := durationToSQLInterval(lookback)
@@ -240,14 +1028,11 @@ func BuildPipelineRunsQuery(lookback time.Duration) string {
 			COUNT(*) as run_count
 		FROM system.lakeflow.pipeline_update_timeline t
 		LEFT JOIN (
-			SELECT workspace_id, pipeline_id, name
-			FROM system.lakeflow.pipelines
-			WHERE delete_time IS NULL
-			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, pipeline_id ORDER BY change_time DESC) = 1
+			%s
 		) p ON t.workspace_id = p.workspace_id AND t.pipeline_id = p.pipeline_id
 		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 		GROUP BY t.workspace_id, t.pipeline_id, p.name
-	`, interval)
+	`, latestPipelineNames, interval)
 }
 
 // BuildPipelineRunStatusQuery returns the query for pipeline status counts with configurable lookback.
@@ -262,15 +1047,12 @@ func BuildPipelineRunStatusQuery(lookback time.Duration) string {
 			COUNT(*) as run_count
 		FROM system.lakeflow.pipeline_update_timeline t
 		LEFT JOIN (
-			SELECT workspace_id, pipeline_id, name
-			FROM system.lakeflow.pipelines
-			WHERE delete_time IS NULL
-			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, pipeline_id ORDER BY change_time DESC) = 1
+			%s
 		) p ON t.workspace_id = p.workspace_id AND t.pipeline_id = p.pipeline_id
 		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 			AND t.result_state IS NOT NULL
 		GROUP BY t.workspace_id, t.pipeline_id, p.name, t.result_state
-	`, interval)
+	`, latestPipelineNames, interval)
 }
 
 // BuildPipelineRunDurationQuery returns the query for pipeline duration quantiles with configurable lookback.
@@ -292,17 +1074,60 @@ func BuildPipelineRunDurationQuery(lookback time.Duration) string {
 				unix_timestamp(t.period_end_time) - unix_timestamp(t.period_start_time) as duration_seconds
 			FROM system.lakeflow.pipeline_update_timeline t
 			LEFT JOIN (
-				SELECT workspace_id, pipeline_id, name
-				FROM system.lakeflow.pipelines
-				WHERE delete_time IS NULL
-				QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, pipeline_id ORDER BY change_time DESC) = 1
+				%s
 			) p ON t.workspace_id = p.workspace_id AND t.pipeline_id = p.pipeline_id
 			WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 				AND t.period_end_time IS NOT NULL
 				AND t.period_end_time > t.period_start_time
 		)
 		GROUP BY workspace_id, pipeline_id, pipeline_name
-	`, interval)
+	`, latestPipelineNames, interval)
+}
+
+// BuildPipelineCostQuery returns the query for list-price cost per pipeline over the billing
+// window, from usage_metadata.dlt_pipeline_id, with the number of successful updates of the
+// pipeline in the same window.
+func BuildPipelineCostQuery(lookback time.Duration, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+	prices := billingPriceJoin(mode, interval, fx)
+	return fmt.Sprintf(`
+		WITH %[1]s,
+		pipeline_cost AS (
+			SELECT 
+				u.workspace_id,
+				u.usage_metadata.dlt_pipeline_id as pipeline_id,
+				%[3]s as currency_code,
+				SUM(u.usage_quantity * COALESCE(p.unit_price, 0)) as cost_usd
+			FROM system.billing.usage u
+			%[2]s
+			WHERE u.usage_date >= current_date() - INTERVAL %[4]s
+				AND u.workspace_id IS NOT NULL
+				AND u.usage_metadata.dlt_pipeline_id IS NOT NULL
+			GROUP BY 1, 2, 3
+		),
+		pipeline_successes AS (
+			SELECT 
+				workspace_id,
+				pipeline_id,
+				COUNT(DISTINCT update_id) as successful_runs
+			FROM system.lakeflow.pipeline_update_timeline
+			WHERE period_start_time >= current_date() - INTERVAL %[4]s
+				AND result_state = '%[6]s'
+			GROUP BY 1, 2
+		)
+		SELECT 
+			c.workspace_id,
+			c.pipeline_id,
+			COALESCE(n.name, CONCAT('pipeline-', c.pipeline_id)) as pipeline_name,
+			c.currency_code,
+			c.cost_usd,
+			COALESCE(s.successful_runs, 0) as successful_runs
+		FROM pipeline_cost c
+		LEFT JOIN pipeline_successes s ON c.workspace_id = s.workspace_id AND c.pipeline_id = s.pipeline_id
+		LEFT JOIN (
+			%[5]s
+		) n ON c.workspace_id = n.workspace_id AND c.pipeline_id = n.pipeline_id
+	`, prices.cte, prices.join, prices.currency, interval, latestPipelineNames, pipelineResultCompleted)
 }
 
 // BuildPipelineRetryEventsQuery returns the query for pipeline retry events with configurable lookback.
@@ -316,15 +1141,12 @@ func BuildPipelineRetryEventsQuery(lookback time.Duration) string {
 			COUNT(*) - COUNT(DISTINCT t.update_id) as retry_count
 		FROM system.lakeflow.pipeline_update_timeline t
 		LEFT JOIN (
-			SELECT workspace_id, pipeline_id, name
-			FROM system.lakeflow.pipelines
-			WHERE delete_time IS NULL
-			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, pipeline_id ORDER BY change_time DESC) = 1
+			%s
 		) p ON t.workspace_id = p.workspace_id AND t.pipeline_id = p.pipeline_id
 		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 		GROUP BY t.workspace_id, t.pipeline_id, p.name
 		HAVING COUNT(*) > COUNT(DISTINCT t.update_id)
-	`, interval)
+	`, latestPipelineNames, interval)
 }
 
 // BuildPipelineFreshnessLagQuery returns the query for pipeline freshness lag with configurable lookback.
@@ -338,16 +1160,13 @@ func BuildPipelineFreshnessLagQuery(lookback time.Duration) string {
 			AVG(unix_timestamp(current_timestamp()) - unix_timestamp(t.period_end_time)) as freshness_lag_seconds
 		FROM system.lakeflow.pipeline_update_timeline t
 		LEFT JOIN (
-			SELECT workspace_id, pipeline_id, name
-			FROM system.lakeflow.pipelines
-			WHERE delete_time IS NULL
-			QUALIFY ROW_NUMBER() OVER (PARTITION BY workspace_id, pipeline_id ORDER BY change_time DESC) = 1
+			%s
 		) p ON t.workspace_id = p.workspace_id AND t.pipeline_id = p.pipeline_id
 		WHERE t.period_start_time >= current_timestamp() - INTERVAL %s
 			AND t.period_end_time IS NOT NULL
 			AND t.result_state = 'COMPLETED'
 		GROUP BY t.workspace_id, t.pipeline_id, p.name
-	`, interval)
+	`, latestPipelineNames, interval)
 }
 
 // ===== SQL Warehouse Query Builders =====
@@ -405,6 +1224,183 @@ func BuildQueryDurationQuery(lookback time.Duration) string {
 	`, interval)
 }
 
+// warehouseQueriesCTEs returns the price CTE followed by the CTEs shared by the warehouse idle
+// and query cost queries. warehouse_usage holds the SQL warehouse usage records of the billing
+// window; warehouse usage is billed in records of up to an hour (usage_metadata.warehouse_id).
+// warehouse_queries pairs each record with the queries in system.query.history that ran on its
+// warehouse during it, where overlap_ms is the part of the query's run inside the record.
+func warehouseQueriesCTEs(lookback time.Duration, mode string, fx *FXRates) string {
+	interval := durationToSQLInterval(lookback)
+	// Queries that started before a usage record can still overlap it
+	queryInterval := durationToSQLInterval(lookback + 24*time.Hour)
+	prices := billingPriceJoin(mode, interval, fx)
+	return fmt.Sprintf(`%[1]s,
+		warehouse_usage AS (
+			SELECT 
+				u.record_id,
+				u.workspace_id,
+				u.usage_metadata.warehouse_id as warehouse_id,
+				u.usage_start_time,
+				u.usage_end_time,
+				u.usage_unit,
+				%[3]s as currency_code,
+				u.usage_quantity as dbus,
+				u.usage_quantity * COALESCE(p.unit_price, 0) as cost
+			FROM system.billing.usage u
+			%[2]s
+			WHERE u.usage_date >= current_date() - INTERVAL %[4]s
+				AND u.workspace_id IS NOT NULL
+				AND u.usage_metadata.warehouse_id IS NOT NULL
+		),
+		warehouse_queries AS (
+			SELECT 
+				w.record_id,
+				w.workspace_id,
+				w.warehouse_id,
+				w.currency_code,
+				w.cost,
+				q.executed_by,
+				%[6]s as query_source,
+				unix_millis(LEAST(COALESCE(q.end_time, current_timestamp()), w.usage_end_time))
+					- unix_millis(GREATEST(q.start_time, w.usage_start_time)) as overlap_ms,
+				q.execution_duration_ms,
+				q.total_duration_ms
+			FROM warehouse_usage w
+			JOIN system.query.history q
+				ON q.workspace_id = w.workspace_id
+				AND q.compute.warehouse_id = w.warehouse_id
+				AND q.start_time < w.usage_end_time
+				AND COALESCE(q.end_time, current_timestamp()) > w.usage_start_time
+			WHERE q.start_time >= current_date() - INTERVAL %[5]s
+		)`, prices.cte, prices.join, prices.currency, interval, queryInterval, querySourceType)
+}
+
+// BuildWarehouseIdleQuery returns the query for the DBUs and list-price cost of SQL warehouse
+// usage with no query activity, per workspace and warehouse over the billing window. A usage
+// record is idle when no query ran on the warehouse during it (see warehouseQueriesCTEs).
+// Records with any activity count as busy, so partially idle hours are not included and the
+// estimate is a lower bound.
+func BuildWarehouseIdleQuery(lookback time.Duration, mode string, fx *FXRates) string {
+	ctes := warehouseQueriesCTEs(lookback, mode, fx)
+	return fmt.Sprintf(`
+		WITH %s,
+		busy_usage AS (
+			SELECT DISTINCT record_id
+			FROM warehouse_queries
+		)
+		SELECT 
+			w.workspace_id,
+			w.warehouse_id,
+			w.usage_unit,
+			w.currency_code,
+			SUM(CASE WHEN b.record_id IS NULL THEN w.dbus ELSE 0 END) as idle_dbus,
+			SUM(CASE WHEN b.record_id IS NULL THEN w.cost ELSE 0 END) as idle_cost_usd
+		FROM warehouse_usage w
+		LEFT JOIN busy_usage b ON w.record_id = b.record_id
+		GROUP BY 1, 2, 3, 4
+		ORDER BY 1, 2
+	`, ctes)
+}
+
+// Query cost dimensions, returned in the dimension column of BuildQueryCostQuery.
+const (
+	queryCostByUser   = "executed_by"
+	queryCostBySource = "query_source"
+)
+
+// querySourceType classifies the query_source struct of system.query.history into the kind of
+// client that ran the query.
+const querySourceType = `CASE
+					WHEN q.query_source.dashboard_id IS NOT NULL OR q.query_source.legacy_dashboard_id IS NOT NULL THEN 'dashboard'
+					WHEN q.query_source.genie_space_id IS NOT NULL THEN 'genie'
+					WHEN q.query_source.notebook_id IS NOT NULL THEN 'notebook'
+					WHEN q.query_source.job_info.job_id IS NOT NULL THEN 'job'
+					WHEN q.query_source.alert_id IS NOT NULL THEN 'alert'
+					WHEN q.query_source.sql_query_id IS NOT NULL THEN 'sql_query'
+					ELSE 'unknown'
+				END`
+
+// BuildQueryCostQuery returns the query for SQL warehouse cost apportioned to the queries it
+// served, over the billing window. The cost of each warehouse usage record (up to an hour) is
+// split across the queries that ran during it (see warehouseQueriesCTEs), weighted by the part
+// of each query's run inside the record in milliseconds, scaled by its share of execution time
+// (excluding queueing and compilation).
+// Usage records without queries are not attributed (see BuildWarehouseIdleQuery).
+//
+// Rows are per dimension: executed_by, where per warehouse only the userLimit users with the
+// highest cost are kept and the rest are rolled up into '__other__', and query_source.
+func BuildQueryCostQuery(lookback time.Duration, userLimit int, mode string, fx *FXRates) string {
+	if userLimit == 0 {
+		userLimit = DefaultQueryCostUserLimit
+	}
+	ctes := warehouseQueriesCTEs(lookback, mode, fx)
+	return fmt.Sprintf(`
+		WITH %[1]s,
+		query_usage AS (
+			SELECT 
+				record_id,
+				workspace_id,
+				warehouse_id,
+				currency_code,
+				cost,
+				COALESCE(executed_by, 'unknown') as executed_by,
+				query_source,
+				overlap_ms * COALESCE(execution_duration_ms / NULLIF(total_duration_ms, 0), 1) as weight
+			FROM warehouse_queries
+		),
+		query_cost AS (
+			SELECT 
+				workspace_id,
+				warehouse_id,
+				executed_by,
+				query_source,
+				currency_code,
+				SUM(cost * weight / record_weight) as cost
+			FROM (
+				SELECT *, SUM(weight) OVER (PARTITION BY record_id) as record_weight
+				FROM query_usage
+			)
+			WHERE record_weight > 0
+			GROUP BY 1, 2, 3, 4, 5
+		),
+		user_cost AS (
+			SELECT 
+				*,
+				DENSE_RANK() OVER (PARTITION BY workspace_id, warehouse_id ORDER BY user_total DESC, executed_by) as cost_rank
+			FROM (
+				SELECT 
+					workspace_id,
+					warehouse_id,
+					executed_by,
+					currency_code,
+					SUM(cost) as cost,
+					SUM(SUM(cost)) OVER (PARTITION BY workspace_id, warehouse_id, executed_by) as user_total
+				FROM query_cost
+				GROUP BY 1, 2, 3, 4
+			)
+		)
+		SELECT 
+			'%[4]s' as dimension,
+			workspace_id,
+			warehouse_id,
+			CASE WHEN cost_rank <= %[2]d THEN executed_by ELSE '%[3]s' END as value,
+			currency_code,
+			SUM(cost) as cost_usd
+		FROM user_cost
+		GROUP BY 1, 2, 3, 4, 5
+		UNION ALL
+		SELECT 
+			'%[5]s' as dimension,
+			workspace_id,
+			warehouse_id,
+			query_source as value,
+			currency_code,
+			SUM(cost) as cost_usd
+		FROM query_cost
+		GROUP BY 1, 2, 3, 4, 5
+	`, ctes, userLimit, attributionOther, queryCostByUser, queryCostBySource)
+}
+
 // BuildQueriesRunningQuery returns the query for concurrent queries estimate with configurable lookback.
 func BuildQueriesRunningQuery(lookback time.Duration) string {
 	interval := durationToSQLInterval(lookback)
@@ -1,9 +1,13 @@
 package collector
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestDurationToSQLInterval tests the duration to SQL interval conversion function.
@@ -74,6 +78,12 @@ func TestBuildBillingDBUsQuery(t *testing.T) {
 			if !strings.Contains(query, "system.billing.usage") {
 				t.Error("Query should reference system.billing.usage")
 			}
+			if !strings.Contains(query, "record_type IN ('RETRACTION', 'RESTATEMENT')") {
+				t.Error("Query should separate corrections by record_type")
+			}
+			if !strings.Contains(query, "GROUP BY workspace_id, sku_name, usage_unit") {
+				t.Error("Query should group by usage_unit")
+			}
 			if !strings.Contains(query, "workspace_id") {
 				t.Error("Query should select workspace_id")
 			}
@@ -96,9 +106,9 @@ func TestBuildBillingCostEstimateQuery(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			query := BuildBillingCostEstimateQuery(tt.lookback)
+			query := BuildBillingCostEstimateQuery(tt.lookback, BillingCostModeCurrent, nil)
 			if !strings.Contains(query, tt.expectedWindow) {
-				t.Errorf("BuildBillingCostEstimateQuery(%v) should contain %q", tt.lookback, tt.expectedWindow)
+				t.Errorf("BuildBillingCostEstimateQuery(%v, nil) should contain %q", tt.lookback, tt.expectedWindow)
 			}
 			// Verify joins pricing data
 			if !strings.Contains(query, "system.billing.list_prices") {
@@ -108,6 +118,57 @@ func TestBuildBillingCostEstimateQuery(t *testing.T) {
 	}
 }
 
+func TestBuildBillingCostEstimateQuery_CostModes(t *testing.T) {
+	current := BuildBillingCostEstimateQuery(24*time.Hour, BillingCostModeCurrent, nil)
+	assert.Contains(t, current, "WHERE price_end_time IS NULL")
+	assert.NotContains(t, current, "usage_start_time")
+
+	historical := BuildBillingCostEstimateQuery(24*time.Hour, BillingCostModeHistorical, nil)
+	assert.Contains(t, historical, "effective_prices")
+	assert.Contains(t, historical, "u.usage_start_time >= p.price_start_time")
+	assert.Contains(t, historical, "(p.price_end_time IS NULL OR u.usage_start_time < p.price_end_time)")
+	assert.Contains(t, historical, "u.usage_unit = p.usage_unit")
+	// Price windows that ended before the lookback cannot match any usage
+	assert.Contains(t, historical, "OR price_end_time >= current_date() - INTERVAL 1 DAY")
+
+	// Every cost query uses the same price join
+	for name, query := range map[string]string{
+		"by product":  BuildBillingByProductQuery(24*time.Hour, BillingCostModeHistorical, nil),
+		"attribution": BuildBillingAttributionQuery(24*time.Hour, map[string]int{"job_id": 10}, BillingCostModeHistorical, nil),
+		"by tag":      BuildBillingByTagQuery(24*time.Hour, []string{"team"}, "untagged", BillingCostModeHistorical, nil),
+	} {
+		assert.Contains(t, query, "LEFT JOIN effective_prices p", name)
+		assert.NotContains(t, query, "current_prices", name)
+	}
+}
+
+func TestBuildBillingCostEstimateQuery_Currency(t *testing.T) {
+	query := BuildBillingCostEstimateQuery(24*time.Hour, BillingCostModeCurrent, nil)
+	assert.Contains(t, query, "pricing.default as unit_price, currency_code as currency_code")
+	assert.Contains(t, query, "COALESCE(p.currency_code, 'USD') as currency_code")
+	assert.Contains(t, query, "GROUP BY u.workspace_id, u.sku_name, COALESCE(p.currency_code, 'USD')")
+	assert.NotContains(t, query, "fx_rates")
+
+	fx := &FXRates{ReportingCurrency: "EUR", Rates: map[string]float64{"USD": 0.92, "GBP": 1.17}}
+	for _, mode := range []string{BillingCostModeCurrent, BillingCostModeHistorical} {
+		query := BuildBillingCostEstimateQuery(24*time.Hour, mode, fx)
+		assert.Contains(t, query, "SELECT * FROM VALUES ('GBP', 1.17), ('USD', 0.92) AS fx(fx_currency_code, fx_rate)", mode)
+		assert.Contains(t, query, "LEFT JOIN fx_rates ON currency_code = fx_currency_code", mode)
+		assert.Contains(t, query, "pricing.default * COALESCE(fx_rate, 1) as unit_price", mode)
+		assert.Contains(t, query, "CASE WHEN fx_rate IS NULL THEN currency_code ELSE 'EUR' END as currency_code", mode)
+		// Unpriced usage is reported in the reporting currency
+		assert.Contains(t, query, "COALESCE(p.currency_code, 'EUR') as currency_code", mode)
+	}
+}
+
+func TestBuildBillingEffectiveCostQuery(t *testing.T) {
+	query := BuildBillingEffectiveCostQuery(24*time.Hour, BillingCostModeCurrent, nil)
+
+	assert.Contains(t, query, "INTERVAL 1 DAY")
+	assert.Contains(t, query, "LEFT JOIN current_prices p")
+	assert.Contains(t, query, "GROUP BY u.workspace_id, u.sku_name, u.billing_origin_product, u.usage_date")
+}
+
 func TestBuildPriceChangeEventsQuery(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -131,6 +192,235 @@ func TestBuildPriceChangeEventsQuery(t *testing.T) {
 	}
 }
 
+func TestBuildBillingByProductQuery(t *testing.T) {
+	query := BuildBillingByProductQuery(24*time.Hour, BillingCostModeCurrent, nil)
+
+	assert.Contains(t, query, "INTERVAL 1 DAY")
+	assert.Contains(t, query, "billing_origin_product")
+	assert.Contains(t, query, "system.billing.list_prices")
+	assert.Contains(t, query, "GROUP BY u.workspace_id, u.billing_origin_product, u.usage_unit, COALESCE(p.currency_code, 'USD')")
+}
+
+func TestBuildBillingByComputeQuery(t *testing.T) {
+	query := BuildBillingByComputeQuery(24*time.Hour, BillingCostModeCurrent, nil)
+
+	assert.Contains(t, query, "INTERVAL 1 DAY")
+	assert.Contains(t, query, "u.product_features.is_serverless OR u.sku_name LIKE '%SERVERLESS%'")
+	assert.Contains(t, query, "THEN 'serverless' ELSE 'classic' END as compute_type")
+	assert.Contains(t, query, "u.product_features.is_photon OR u.sku_name LIKE '%PHOTON%'")
+	assert.Contains(t, query, "GROUP BY 1, 2, 3, 4, 5, 6")
+}
+
+func TestBuildBillingByIdentityQuery(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		query := BuildBillingByIdentityQuery(24*time.Hour, 0, BillingCostModeCurrent, nil)
+
+		assert.Contains(t, query, "INTERVAL 1 DAY")
+		assert.Contains(t, query, "COALESCE(u.identity_metadata.run_as, u.identity_metadata.owned_by) as identity")
+		assert.Contains(t, query, "PARTITION BY workspace_id ORDER BY identity_cost DESC, identity")
+		assert.Contains(t, query, fmt.Sprintf("WHEN cost_rank <= %d THEN identity ELSE '__other__' END", DefaultBillingIdentityLimit))
+	})
+
+	t.Run("custom limit", func(t *testing.T) {
+		query := BuildBillingByIdentityQuery(24*time.Hour, 3, BillingCostModeCurrent, nil)
+		assert.Contains(t, query, "WHEN cost_rank <= 3 THEN identity")
+	})
+}
+
+func TestBuildBillingAnomalyQuery(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	now := time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC)
+
+	query := BuildBillingAnomalyQuery(now, berlin, 2, 1, BillingCostModeCurrent, nil)
+
+	// Yesterday and the same weekday one and two weeks earlier, midnight to midnight in Berlin
+	assert.Contains(t, query, "WHEN u.usage_start_time >= TIMESTAMP '2026-09-14T00:00:00+02:00' AND u.usage_start_time < TIMESTAMP '2026-09-15T00:00:00+02:00' THEN 0")
+	assert.Contains(t, query, "WHEN u.usage_start_time >= TIMESTAMP '2026-09-07T00:00:00+02:00' AND u.usage_start_time < TIMESTAMP '2026-09-08T00:00:00+02:00' THEN 1")
+	assert.Contains(t, query, "WHEN u.usage_start_time >= TIMESTAMP '2026-08-31T00:00:00+02:00' AND u.usage_start_time < TIMESTAMP '2026-09-01T00:00:00+02:00' THEN 2")
+	assert.NotContains(t, query, "THEN 3")
+	assert.Contains(t, query, "u.usage_start_time >= TIMESTAMP '2026-08-31T00:00:00+02:00'")
+	assert.Contains(t, query, "INTERVAL 17 DAYS")
+	assert.Contains(t, query, "WHERE weeks_ago IS NOT NULL")
+
+	t.Run("default weeks", func(t *testing.T) {
+		query := BuildBillingAnomalyQuery(now, time.UTC, 0, 1, BillingCostModeCurrent, nil)
+		assert.Contains(t, query, fmt.Sprintf("THEN %d END as weeks_ago", DefaultBillingAnomalyWeeks))
+	})
+
+	t.Run("default offset", func(t *testing.T) {
+		// Billing lags 24-48 hours, so the day before yesterday is scored by default
+		query := BuildBillingAnomalyQuery(now, time.UTC, 1, 0, BillingCostModeCurrent, nil)
+		assert.Contains(t, query, "WHEN u.usage_start_time >= TIMESTAMP '2026-09-13T00:00:00Z' AND u.usage_start_time < TIMESTAMP '2026-09-14T00:00:00Z' THEN 0")
+		assert.Contains(t, query, "WHEN u.usage_start_time >= TIMESTAMP '2026-09-06T00:00:00Z' AND u.usage_start_time < TIMESTAMP '2026-09-07T00:00:00Z' THEN 1")
+	})
+}
+
+func TestBuildBillingAttributionQuery(t *testing.T) {
+	t.Run("no keys enabled", func(t *testing.T) {
+		assert.Empty(t, BuildBillingAttributionQuery(24*time.Hour, nil, BillingCostModeCurrent, nil))
+	})
+
+	t.Run("per-key limits", func(t *testing.T) {
+		query := BuildBillingAttributionQuery(48*time.Hour, map[string]int{
+			"job_id":       25,
+			"warehouse_id": 0, // default limit
+		}, BillingCostModeCurrent, nil)
+
+		assert.Contains(t, query, "INTERVAL 2 DAYS")
+		assert.Contains(t, query, "usage_metadata.job_id IS NOT NULL")
+		assert.Contains(t, query, "WHEN dbus_rank <= 25 THEN")
+		assert.Contains(t, query, "usage_metadata.warehouse_id IS NOT NULL")
+		assert.Contains(t, query, fmt.Sprintf("WHEN dbus_rank <= %d THEN", DefaultBillingAttributionLimit))
+		assert.Contains(t, query, "'__other__'")
+		assert.NotContains(t, query, "cluster_id")
+		assert.Equal(t, 1, strings.Count(query, "UNION ALL"))
+		// Keys are emitted in a fixed order so the query text is stable across scrapes
+		assert.Less(t, strings.Index(query, "attr_job_id"), strings.Index(query, "attr_warehouse_id"))
+	})
+
+	t.Run("ranks within each workspace", func(t *testing.T) {
+		query := BuildBillingAttributionQuery(24*time.Hour, map[string]int{"job_id": 10}, BillingCostModeCurrent, nil)
+
+		// Each key has its own CTE, so partitioning by workspace ranks per key and workspace,
+		// matching the per-workspace "__other__" rollup
+		assert.Contains(t, query, "DENSE_RANK() OVER (PARTITION BY workspace_id ORDER BY value_dbus DESC, attribution_value) as dbus_rank")
+		assert.Contains(t, query, "FROM attr_job_id\n\t\tGROUP BY 1, 2, 3, 4, 5")
+	})
+}
+
+func TestBuildBillingByTagQuery(t *testing.T) {
+	t.Run("no tag keys", func(t *testing.T) {
+		assert.Empty(t, BuildBillingByTagQuery(24*time.Hour, nil, "untagged", BillingCostModeCurrent, nil))
+	})
+
+	t.Run("tag columns and placeholder", func(t *testing.T) {
+		query := BuildBillingByTagQuery(24*time.Hour, []string{"team", "cost_center"}, "none", BillingCostModeCurrent, nil)
+
+		assert.Contains(t, query, "INTERVAL 1 DAY")
+		assert.Contains(t, query, "COALESCE(NULLIF(u.custom_tags['team'], ''), 'none') as tag_0")
+		assert.Contains(t, query, "COALESCE(NULLIF(u.custom_tags['cost_center'], ''), 'none') as tag_1")
+		assert.Contains(t, query, "GROUP BY 1, 2, 3")
+	})
+
+	t.Run("escapes configured values", func(t *testing.T) {
+		query := BuildBillingByTagQuery(24*time.Hour, []string{"it's"}, `n\a`, BillingCostModeCurrent, nil)
+		assert.Contains(t, query, `u.custom_tags['it\'s']`)
+		assert.Contains(t, query, `'n\\a'`)
+	})
+}
+
+func TestCalendarBounds(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                                   string
+		now                                    time.Time
+		loc                                    *time.Location
+		today, yesterday, month, previousMonth string
+	}{
+		{
+			name:          "mid month",
+			now:           time.Date(2026, 9, 15, 13, 30, 0, 0, time.UTC),
+			loc:           time.UTC,
+			today:         "2026-09-15T00:00:00Z",
+			yesterday:     "2026-09-14T00:00:00Z",
+			month:         "2026-09-01T00:00:00Z",
+			previousMonth: "2026-08-01T00:00:00Z",
+		},
+		{
+			name:          "first of month",
+			now:           time.Date(2026, 3, 1, 0, 5, 0, 0, time.UTC),
+			loc:           time.UTC,
+			today:         "2026-03-01T00:00:00Z",
+			yesterday:     "2026-02-28T00:00:00Z",
+			month:         "2026-03-01T00:00:00Z",
+			previousMonth: "2026-02-01T00:00:00Z",
+		},
+		{
+			name:          "year boundary",
+			now:           time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC),
+			loc:           time.UTC,
+			today:         "2026-01-01T00:00:00Z",
+			yesterday:     "2025-12-31T00:00:00Z",
+			month:         "2026-01-01T00:00:00Z",
+			previousMonth: "2025-12-01T00:00:00Z",
+		},
+		{
+			// 03:00 UTC on October 1st is still September 30th in New York
+			name:          "time zone behind UTC",
+			now:           time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC),
+			loc:           newYork,
+			today:         "2026-09-30T00:00:00-04:00",
+			yesterday:     "2026-09-29T00:00:00-04:00",
+			month:         "2026-09-01T00:00:00-04:00",
+			previousMonth: "2026-08-01T00:00:00-04:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			today, yesterday, month, previousMonth := calendarBounds(tt.now, tt.loc)
+			assert.Equal(t, tt.today, today.Format(time.RFC3339))
+			assert.Equal(t, tt.yesterday, yesterday.Format(time.RFC3339))
+			assert.Equal(t, tt.month, month.Format(time.RFC3339))
+			assert.Equal(t, tt.previousMonth, previousMonth.Format(time.RFC3339))
+		})
+	}
+}
+
+func TestBuildBillingCalendarQuery(t *testing.T) {
+	now := time.Date(2026, 9, 15, 13, 30, 0, 0, time.UTC)
+	query := BuildBillingCalendarQuery(now, time.UTC, BillingCostModeCurrent, nil)
+
+	assert.Contains(t, query, "FROM system.billing.usage u")
+	assert.Contains(t, query, "u.usage_start_time >= TIMESTAMP '2026-08-01T00:00:00Z'")
+	assert.Contains(t, query, "u.usage_start_time >= TIMESTAMP '2026-09-15T00:00:00Z'")
+	assert.Contains(t, query, "u.usage_start_time < TIMESTAMP '2026-09-01T00:00:00Z'")
+	for _, column := range []string{"today_dbus", "yesterday_dbus", "month_to_date_dbus", "previous_month_dbus",
+		"today_cost_usd", "yesterday_cost_usd", "month_to_date_cost_usd", "previous_month_cost_usd"} {
+		assert.Contains(t, query, " as "+column)
+	}
+	// 45 days since 2026-08-01, padded by two days for the UTC usage_date partition filter
+	assert.Contains(t, query, "INTERVAL 47 DAY")
+	assert.Contains(t, query, "GROUP BY u.workspace_id, u.sku_name")
+}
+
+func TestBuildBudgetSpendQuery(t *testing.T) {
+	t.Run("no budgets", func(t *testing.T) {
+		assert.Empty(t, BuildBudgetSpendQuery(time.Now(), time.UTC, nil, BillingCostModeCurrent, nil))
+	})
+
+	t.Run("budget filters", func(t *testing.T) {
+		now := time.Date(2026, 9, 15, 13, 30, 0, 0, time.UTC)
+		budgets := []Budget{
+			{Name: "account", Amount: 100},
+			{Name: "prod", Amount: 100, WorkspaceID: "123", SKU: "PREMIUM_JOBS_COMPUTE"},
+			{Name: "team", Amount: 100, Tags: map[string]string{"team": "data", "env": "it's"}},
+		}
+		query := BuildBudgetSpendQuery(now, time.UTC, budgets, BillingCostModeCurrent, nil)
+
+		assert.Contains(t, query, "unix_timestamp(u.usage_start_time) div 3600 * 3600 as hour_start")
+		// Costs are kept apart per currency
+		assert.Contains(t, query, "COALESCE(p.currency_code, 'USD') as currency_code")
+		assert.Contains(t, query, "GROUP BY 1, 2")
+		assert.Contains(t, query, "CASE WHEN TRUE THEN")
+		assert.Contains(t, query, "as budget_0")
+		assert.Contains(t, query, "u.workspace_id = '123' AND u.sku_name = 'PREMIUM_JOBS_COMPUTE'")
+		assert.Contains(t, query, `u.custom_tags['env'] = 'it\'s' AND u.custom_tags['team'] = 'data'`)
+		assert.Contains(t, query, "as budget_2")
+		// Four weeks of history reach back before the start of the month
+		assert.Contains(t, query, "u.usage_start_time >= TIMESTAMP '2026-08-18T00:00:00Z'")
+	})
+
+	t.Run("month start before history", func(t *testing.T) {
+		now := time.Date(2026, 8, 31, 13, 30, 0, 0, time.UTC)
+		query := BuildBudgetSpendQuery(now, time.UTC, []Budget{{Name: "a", Amount: 1}}, BillingCostModeCurrent, nil)
+		assert.Contains(t, query, "u.usage_start_time >= TIMESTAMP '2026-08-01T00:00:00Z'")
+	})
+}
+
 // ===== Jobs Query Builder Tests =====
 
 func TestBuildJobRunsQuery(t *testing.T) {
@@ -170,6 +460,19 @@ func TestBuildJobRunStatusQuery(t *testing.T) {
 	}
 }
 
+func TestBuildJobRunTerminationsQuery(t *testing.T) {
+	query := BuildJobRunTerminationsQuery(2 * time.Hour)
+	if !strings.Contains(query, "INTERVAL 2 HOURS") {
+		t.Error("Query should contain INTERVAL 2 HOURS")
+	}
+	if !strings.Contains(query, "t.termination_code IS NOT NULL") {
+		t.Error("Query should only count runs with a termination code")
+	}
+	if !strings.Contains(query, "GROUP BY t.workspace_id, t.job_id, j.name, t.termination_code") {
+		t.Error("Query should group by job and termination code")
+	}
+}
+
 func TestBuildJobRunDurationQuery(t *testing.T) {
 	query := BuildJobRunDurationQuery(2 * time.Hour)
 	if !strings.Contains(query, "INTERVAL 2 HOURS") {
@@ -186,6 +489,63 @@ func TestBuildJobRunDurationQuery(t *testing.T) {
 	}
 }
 
+func TestBuildJobRunDurationHistogramQuery(t *testing.T) {
+	query := BuildJobRunDurationHistogramQuery(2*time.Hour, []float64{30, 90.5, 3600})
+	if !strings.Contains(query, "INTERVAL 2 HOURS") {
+		t.Error("Query should contain INTERVAL 2 HOURS")
+	}
+	// Cumulative counts, one column per bucket in order
+	for _, col := range []string{
+		"SUM(CASE WHEN duration_seconds <= 30 THEN 1 ELSE 0 END) as bucket_0",
+		"SUM(CASE WHEN duration_seconds <= 90.5 THEN 1 ELSE 0 END) as bucket_1",
+		"SUM(CASE WHEN duration_seconds <= 3600 THEN 1 ELSE 0 END) as bucket_2",
+		"COUNT(*) as run_count",
+		"SUM(duration_seconds) as duration_sum",
+	} {
+		if !strings.Contains(query, col) {
+			t.Errorf("Query should contain %q", col)
+		}
+	}
+	if strings.Contains(query, "percentile_approx") {
+		t.Error("Histogram query should not compute quantiles")
+	}
+}
+
+func TestBuildJobRunPhaseDurationHistogramQuery(t *testing.T) {
+	query := BuildJobRunPhaseDurationHistogramQuery(2*time.Hour, []float64{10, 60})
+	if !strings.Contains(query, "INTERVAL 2 HOURS") {
+		t.Error("Query should contain INTERVAL 2 HOURS")
+	}
+	for _, col := range []string{
+		"'queue', t.queue_duration_seconds",
+		"'setup', t.setup_duration_seconds",
+		"'execution', t.execution_duration_seconds",
+		"'cleanup', t.cleanup_duration_seconds",
+		"SUM(CASE WHEN duration_seconds <= 60 THEN 1 ELSE 0 END) as bucket_1",
+		"GROUP BY workspace_id, job_id, job_name, phase",
+	} {
+		if !strings.Contains(query, col) {
+			t.Errorf("Query should contain %q", col)
+		}
+	}
+	if !strings.Contains(query, "t.result_state IS NOT NULL") {
+		t.Error("Query should only read the final row of each run")
+	}
+}
+
+func TestBuildTaskRunPhaseDurationHistogramQuery(t *testing.T) {
+	query := BuildTaskRunPhaseDurationHistogramQuery(2*time.Hour, []float64{10, 60})
+	if !strings.Contains(query, "system.lakeflow.job_task_run_timeline") {
+		t.Error("Query should reference system.lakeflow.job_task_run_timeline")
+	}
+	if strings.Contains(query, "queue_duration_seconds") {
+		t.Error("Task runs have no queue phase")
+	}
+	if !strings.Contains(query, "GROUP BY workspace_id, job_id, job_name, task_key, phase") {
+		t.Error("Query should group by task_key and phase")
+	}
+}
+
 func TestBuildTaskRetriesQuery(t *testing.T) {
 	query := BuildTaskRetriesQuery(2 * time.Hour)
 	if !strings.Contains(query, "INTERVAL 2 HOURS") {
@@ -200,16 +560,61 @@ func TestBuildTaskRetriesQuery(t *testing.T) {
 }
 
 func TestBuildJobSLAMissQuery(t *testing.T) {
-	query := BuildJobSLAMissQuery(2*time.Hour, 3600)
+	query := BuildJobSLAMissQuery(2*time.Hour, 3600, nil, DefaultJobSLATag)
 	if !strings.Contains(query, "INTERVAL 2 HOURS") {
 		t.Error("Query should contain INTERVAL 2 HOURS")
 	}
-	if !strings.Contains(query, "3600") {
-		t.Error("Query should use SLA threshold of 3600 seconds")
+	if !strings.Contains(query, "ELSE 3600") {
+		t.Error("Query should default to an SLA threshold of 3600 seconds")
 	}
 	if !strings.Contains(query, "sla_miss_count") {
 		t.Error("Query should calculate sla_miss_count")
 	}
+	if !strings.Contains(query, "threshold_seconds") {
+		t.Error("Query should return threshold_seconds")
+	}
+	if !strings.Contains(query, "j.tags['sla_seconds']") {
+		t.Error("Query should read the SLA job tag")
+	}
+}
+
+func TestBuildJobSLAMissQuery_Overrides(t *testing.T) {
+	slas := []JobSLA{
+		{JobID: "42", ThresholdSeconds: 600},
+		{JobName: "^nightly-.*", ThresholdSeconds: 7200},
+	}
+	query := BuildJobSLAMissQuery(2*time.Hour, 3600, slas, "owner's sla")
+
+	// Config rules come first, in order, then the tag, then the default
+	id := strings.Index(query, "WHEN job_id = '42' THEN 600")
+	name := strings.Index(query, "WHEN job_name RLIKE '^nightly-.*' THEN 7200")
+	tag := strings.Index(query, "TRY_CAST(sla_tag AS BIGINT)")
+	def := strings.Index(query, "ELSE 3600")
+	if id < 0 || name < 0 || tag < 0 || def < 0 {
+		t.Fatalf("Query should contain all threshold sources:\n%s", query)
+	}
+	if id >= name || name >= tag || tag >= def {
+		t.Error("Threshold sources should be in precedence order")
+	}
+	if !strings.Contains(query, `j.tags['owner\'s sla']`) {
+		t.Error("Query should escape the SLA tag key")
+	}
+}
+
+func TestBuildJobRunsActiveQuery(t *testing.T) {
+	query := BuildJobRunsActiveQuery(2*time.Hour, 48*time.Hour)
+	if !strings.Contains(query, "INTERVAL 2 DAYS") {
+		t.Error("Query should look for run starts within INTERVAL 2 DAYS")
+	}
+	if !strings.Contains(query, "INTERVAL 2 HOURS") {
+		t.Error("Query should require timeline rows within INTERVAL 2 HOURS")
+	}
+	if !strings.Contains(query, "COUNT(result_state) = 0") {
+		t.Error("Query should exclude runs with a result_state")
+	}
+	if !strings.Contains(query, "oldest_age_seconds") {
+		t.Error("Query should calculate oldest_age_seconds")
+	}
 }
 
 // ===== Pipelines Query Builder Tests =====
@@ -325,8 +730,152 @@ func TestBuildQueriesRunningQuery(t *testing.T) {
 	}
 }
 
+func TestBuildWarehouseIdleQuery(t *testing.T) {
+	query := BuildWarehouseIdleQuery(24*time.Hour, BillingCostModeCurrent, nil)
+
+	assert.Contains(t, query, "u.usage_date >= current_date() - INTERVAL 1 DAY")
+	assert.Contains(t, query, "u.usage_metadata.warehouse_id IS NOT NULL")
+	// Query history is padded so queries started before a usage record still count
+	assert.Contains(t, query, "q.start_time >= current_date() - INTERVAL 2 DAYS")
+	assert.Contains(t, query, "q.start_time < w.usage_end_time")
+	assert.Contains(t, query, "COALESCE(q.end_time, current_timestamp()) > w.usage_start_time")
+	assert.Contains(t, query, "SELECT DISTINCT record_id\n\t\t\tFROM warehouse_queries")
+	assert.Contains(t, query, "SUM(CASE WHEN b.record_id IS NULL THEN w.dbus ELSE 0 END) as idle_dbus")
+}
+
+func TestBuildQueryCostQuery(t *testing.T) {
+	t.Run("default user limit", func(t *testing.T) {
+		query := BuildQueryCostQuery(24*time.Hour, 0, BillingCostModeCurrent, nil)
+
+		assert.Contains(t, query, "u.usage_date >= current_date() - INTERVAL 1 DAY")
+		assert.Contains(t, query, "q.start_time >= current_date() - INTERVAL 2 DAYS")
+		// Overlap with the usage record, scaled by the share of execution time
+		assert.Contains(t, query, "unix_millis(LEAST(COALESCE(q.end_time, current_timestamp()), w.usage_end_time))")
+		assert.Contains(t, query, "overlap_ms * COALESCE(execution_duration_ms / NULLIF(total_duration_ms, 0), 1) as weight")
+		assert.Contains(t, query, "SUM(cost * weight / record_weight) as cost")
+		assert.Contains(t, query, fmt.Sprintf("WHEN cost_rank <= %d THEN executed_by ELSE '__other__' END", DefaultQueryCostUserLimit))
+		assert.Contains(t, query, "PARTITION BY workspace_id, warehouse_id ORDER BY user_total DESC, executed_by")
+		assert.Contains(t, query, "THEN 'genie'")
+		assert.Contains(t, query, "'executed_by' as dimension")
+		assert.Contains(t, query, "'query_source' as dimension")
+	})
+
+	t.Run("custom user limit", func(t *testing.T) {
+		query := BuildQueryCostQuery(24*time.Hour, 3, BillingCostModeCurrent, nil)
+		assert.Contains(t, query, "WHEN cost_rank <= 3 THEN executed_by")
+	})
+
+	t.Run("sub-second queries", func(t *testing.T) {
+		query := BuildQueryCostQuery(24*time.Hour, 0, BillingCostModeCurrent, nil)
+
+		// A query running from 12:00:00.200 to 12:00:00.700 overlaps its usage record by 500ms.
+		// Whole-second timestamps would give it a weight of 0 and drop its cost.
+		assert.Contains(t, query, "- unix_millis(GREATEST(q.start_time, w.usage_start_time)) as overlap_ms")
+		assert.NotContains(t, query, "unix_timestamp(")
+		assert.Contains(t, query, "WHERE record_weight > 0")
+	})
+}
+
+func TestBuildChargebackQuery(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	t.Run("month boundaries", func(t *testing.T) {
+		loc, err := time.LoadLocation("Europe/Berlin")
+		require.NoError(t, err)
+		month := time.Date(2026, 9, 1, 0, 0, 0, 0, loc)
+		query := BuildChargebackQuery(now, month, "product", "untagged", BillingCostModeCurrent, nil)
+
+		assert.Contains(t, query, "u.usage_date >= DATE '2026-08-31'")
+		assert.Contains(t, query, "u.usage_date <= DATE '2026-10-01'")
+		assert.Contains(t, query, "u.usage_start_time >= TIMESTAMP '2026-09-01T00:00:00+02:00'")
+		assert.Contains(t, query, "u.usage_start_time < TIMESTAMP '2026-10-01T00:00:00+02:00'")
+		assert.Contains(t, query, "COALESCE(u.billing_origin_product, 'untagged') as chargeback_group")
+		assert.Contains(t, query, "GROUP BY 1, 2, 3, 4, 5")
+	})
+
+	t.Run("historical prices cover the month", func(t *testing.T) {
+		month := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		query := BuildChargebackQuery(now, month, "product", "untagged", BillingCostModeHistorical, nil)
+		assert.Contains(t, query, "price_end_time >= current_date() - INTERVAL 49 DAYS")
+	})
+
+	t.Run("groupings", func(t *testing.T) {
+		month := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		tests := []struct {
+			groupBy string
+			want    string
+		}{
+			{"tag:team", "COALESCE(NULLIF(u.custom_tags['team'], ''), 'none') as chargeback_group"},
+			{"tag:it's", `u.custom_tags['it\'s']`},
+			{"identity", "COALESCE(COALESCE(u.identity_metadata.run_as, u.identity_metadata.owned_by), 'none')"},
+			{"job_id", "COALESCE(u.usage_metadata.job_id, 'none')"},
+		}
+		for _, tt := range tests {
+			assert.Contains(t, BuildChargebackQuery(now, month, tt.groupBy, "none", BillingCostModeCurrent, nil), tt.want)
+		}
+
+		assert.Empty(t, BuildChargebackQuery(now, month, "team", "none", BillingCostModeCurrent, nil))
+		assert.Empty(t, BuildChargebackQuery(now, month, "tag:", "none", BillingCostModeCurrent, nil))
+	})
+}
+
+func TestBuildJobCostQuery(t *testing.T) {
+	query := BuildJobCostQuery(24*time.Hour, BillingCostModeCurrent, nil)
+
+	assert.Contains(t, query, "u.usage_metadata.job_id IS NOT NULL")
+	assert.Contains(t, query, "u.usage_date >= current_date() - INTERVAL 1 DAY")
+	assert.Contains(t, query, "period_start_time >= current_date() - INTERVAL 1 DAY")
+	assert.Contains(t, query, "COUNT(DISTINCT run_id) as successful_runs")
+	assert.Contains(t, query, "result_state = 'SUCCEEDED'")
+	// Names resolve like the job run queries
+	assert.Contains(t, query, latestJobNames)
+	assert.Contains(t, query, "COALESCE(j.name, CONCAT('job-', c.job_id)) as job_name")
+}
+
+func TestBuildPipelineCostQuery(t *testing.T) {
+	query := BuildPipelineCostQuery(24*time.Hour, BillingCostModeCurrent, nil)
+
+	assert.Contains(t, query, "u.usage_metadata.dlt_pipeline_id IS NOT NULL")
+	assert.Contains(t, query, "u.usage_date >= current_date() - INTERVAL 1 DAY")
+	assert.Contains(t, query, "COUNT(DISTINCT update_id) as successful_runs")
+	assert.Contains(t, query, "result_state = 'COMPLETED'")
+	assert.Contains(t, query, latestPipelineNames)
+	assert.Contains(t, query, "COALESCE(n.name, CONCAT('pipeline-', c.pipeline_id)) as pipeline_name")
+}
+
 // ===== Query Properties Tests =====
 
+func TestQueriesShareNameResolution(t *testing.T) {
+	lookback := 24 * time.Hour
+	jobQueries := map[string]string{
+		"BuildJobRunsQuery":                       BuildJobRunsQuery(lookback),
+		"BuildJobRunStatusQuery":                  BuildJobRunStatusQuery(lookback),
+		"BuildJobRunTerminationsQuery":            BuildJobRunTerminationsQuery(lookback),
+		"BuildJobRunDurationQuery":                BuildJobRunDurationQuery(lookback),
+		"BuildJobRunPhaseDurationHistogramQuery":  BuildJobRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets),
+		"BuildTaskRunPhaseDurationHistogramQuery": BuildTaskRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets),
+		"BuildTaskRetriesQuery":                   BuildTaskRetriesQuery(lookback),
+		"BuildJobSLAMissQuery":                    BuildJobSLAMissQuery(lookback, 3600, nil, DefaultJobSLATag),
+		"BuildJobRunsActiveQuery":                 BuildJobRunsActiveQuery(lookback, DefaultJobsActiveLookback),
+		"BuildJobCostQuery":                       BuildJobCostQuery(lookback, BillingCostModeCurrent, nil),
+	}
+	for name, query := range jobQueries {
+		assert.Contains(t, query, latestJobNames, "%s should resolve job names with latestJobNames", name)
+	}
+
+	pipelineQueries := map[string]string{
+		"BuildPipelineRunsQuery":         BuildPipelineRunsQuery(lookback),
+		"BuildPipelineRunStatusQuery":    BuildPipelineRunStatusQuery(lookback),
+		"BuildPipelineRunDurationQuery":  BuildPipelineRunDurationQuery(lookback),
+		"BuildPipelineRetryEventsQuery":  BuildPipelineRetryEventsQuery(lookback),
+		"BuildPipelineFreshnessLagQuery": BuildPipelineFreshnessLagQuery(lookback),
+		"BuildPipelineCostQuery":         BuildPipelineCostQuery(lookback, BillingCostModeCurrent, nil),
+	}
+	for name, query := range pipelineQueries {
+		assert.Contains(t, query, latestPipelineNames, "%s should resolve pipeline names with latestPipelineNames", name)
+	}
+}
+
 func TestAllQueriesContainSelect(t *testing.T) {
 	lookback := 2 * time.Hour
 	billingLookback := 24 * time.Hour
@@ -336,13 +885,20 @@ func TestAllQueriesContainSelect(t *testing.T) {
 		query string
 	}{
 		{"BuildBillingDBUsQuery", BuildBillingDBUsQuery(billingLookback)},
-		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback)},
+		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback, BillingCostModeCurrent, nil)},
 		{"BuildPriceChangeEventsQuery", BuildPriceChangeEventsQuery(billingLookback)},
+		{"BuildBillingByProductQuery", BuildBillingByProductQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildBillingAttributionQuery", BuildBillingAttributionQuery(billingLookback, map[string]int{"job_id": 10}, BillingCostModeCurrent, nil)},
 		{"BuildJobRunsQuery", BuildJobRunsQuery(lookback)},
 		{"BuildJobRunStatusQuery", BuildJobRunStatusQuery(lookback)},
+		{"BuildJobRunTerminationsQuery", BuildJobRunTerminationsQuery(lookback)},
 		{"BuildJobRunDurationQuery", BuildJobRunDurationQuery(lookback)},
+		{"BuildJobRunDurationHistogramQuery", BuildJobRunDurationHistogramQuery(lookback, DefaultJobDurationBuckets)},
 		{"BuildTaskRetriesQuery", BuildTaskRetriesQuery(lookback)},
-		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600)},
+		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600, nil, DefaultJobSLATag)},
+		{"BuildJobRunsActiveQuery", BuildJobRunsActiveQuery(lookback, DefaultJobsActiveLookback)},
+		{"BuildJobRunPhaseDurationHistogramQuery", BuildJobRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets)},
+		{"BuildTaskRunPhaseDurationHistogramQuery", BuildTaskRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets)},
 		{"BuildPipelineRunsQuery", BuildPipelineRunsQuery(lookback)},
 		{"BuildPipelineRunStatusQuery", BuildPipelineRunStatusQuery(lookback)},
 		{"BuildPipelineRunDurationQuery", BuildPipelineRunDurationQuery(lookback)},
@@ -352,6 +908,10 @@ func TestAllQueriesContainSelect(t *testing.T) {
 		{"BuildQueryErrorsQuery", BuildQueryErrorsQuery(lookback)},
 		{"BuildQueryDurationQuery", BuildQueryDurationQuery(lookback)},
 		{"BuildQueriesRunningQuery", BuildQueriesRunningQuery(lookback)},
+		{"BuildWarehouseIdleQuery", BuildWarehouseIdleQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildQueryCostQuery", BuildQueryCostQuery(billingLookback, 10, BillingCostModeCurrent, nil)},
+		{"BuildJobCostQuery", BuildJobCostQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildPipelineCostQuery", BuildPipelineCostQuery(billingLookback, BillingCostModeCurrent, nil)},
 	}
 
 	for _, tt := range queries {
@@ -373,13 +933,20 @@ func TestAllQueriesContainTimeFilter(t *testing.T) {
 		query string
 	}{
 		{"BuildBillingDBUsQuery", BuildBillingDBUsQuery(billingLookback)},
-		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback)},
+		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback, BillingCostModeCurrent, nil)},
 		{"BuildPriceChangeEventsQuery", BuildPriceChangeEventsQuery(billingLookback)},
+		{"BuildBillingByProductQuery", BuildBillingByProductQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildBillingAttributionQuery", BuildBillingAttributionQuery(billingLookback, map[string]int{"job_id": 10}, BillingCostModeCurrent, nil)},
 		{"BuildJobRunsQuery", BuildJobRunsQuery(lookback)},
 		{"BuildJobRunStatusQuery", BuildJobRunStatusQuery(lookback)},
+		{"BuildJobRunTerminationsQuery", BuildJobRunTerminationsQuery(lookback)},
 		{"BuildJobRunDurationQuery", BuildJobRunDurationQuery(lookback)},
+		{"BuildJobRunDurationHistogramQuery", BuildJobRunDurationHistogramQuery(lookback, DefaultJobDurationBuckets)},
 		{"BuildTaskRetriesQuery", BuildTaskRetriesQuery(lookback)},
-		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600)},
+		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600, nil, DefaultJobSLATag)},
+		{"BuildJobRunsActiveQuery", BuildJobRunsActiveQuery(lookback, DefaultJobsActiveLookback)},
+		{"BuildJobRunPhaseDurationHistogramQuery", BuildJobRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets)},
+		{"BuildTaskRunPhaseDurationHistogramQuery", BuildTaskRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets)},
 		{"BuildPipelineRunsQuery", BuildPipelineRunsQuery(lookback)},
 		{"BuildPipelineRunStatusQuery", BuildPipelineRunStatusQuery(lookback)},
 		{"BuildPipelineRunDurationQuery", BuildPipelineRunDurationQuery(lookback)},
@@ -389,6 +956,10 @@ func TestAllQueriesContainTimeFilter(t *testing.T) {
 		{"BuildQueryErrorsQuery", BuildQueryErrorsQuery(lookback)},
 		{"BuildQueryDurationQuery", BuildQueryDurationQuery(lookback)},
 		{"BuildQueriesRunningQuery", BuildQueriesRunningQuery(lookback)},
+		{"BuildWarehouseIdleQuery", BuildWarehouseIdleQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildQueryCostQuery", BuildQueryCostQuery(billingLookback, 10, BillingCostModeCurrent, nil)},
+		{"BuildJobCostQuery", BuildJobCostQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildPipelineCostQuery", BuildPipelineCostQuery(billingLookback, BillingCostModeCurrent, nil)},
 	}
 
 	for _, tt := range queries {
@@ -414,13 +985,20 @@ func TestAllQueriesContainAggregation(t *testing.T) {
 		query string
 	}{
 		{"BuildBillingDBUsQuery", BuildBillingDBUsQuery(billingLookback)},
-		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback)},
+		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback, BillingCostModeCurrent, nil)},
 		{"BuildPriceChangeEventsQuery", BuildPriceChangeEventsQuery(billingLookback)},
+		{"BuildBillingByProductQuery", BuildBillingByProductQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildBillingAttributionQuery", BuildBillingAttributionQuery(billingLookback, map[string]int{"job_id": 10}, BillingCostModeCurrent, nil)},
 		{"BuildJobRunsQuery", BuildJobRunsQuery(lookback)},
 		{"BuildJobRunStatusQuery", BuildJobRunStatusQuery(lookback)},
+		{"BuildJobRunTerminationsQuery", BuildJobRunTerminationsQuery(lookback)},
 		{"BuildJobRunDurationQuery", BuildJobRunDurationQuery(lookback)},
+		{"BuildJobRunDurationHistogramQuery", BuildJobRunDurationHistogramQuery(lookback, DefaultJobDurationBuckets)},
 		{"BuildTaskRetriesQuery", BuildTaskRetriesQuery(lookback)},
-		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600)},
+		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600, nil, DefaultJobSLATag)},
+		{"BuildJobRunsActiveQuery", BuildJobRunsActiveQuery(lookback, DefaultJobsActiveLookback)},
+		{"BuildJobRunPhaseDurationHistogramQuery", BuildJobRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets)},
+		{"BuildTaskRunPhaseDurationHistogramQuery", BuildTaskRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets)},
 		{"BuildPipelineRunsQuery", BuildPipelineRunsQuery(lookback)},
 		{"BuildPipelineRunStatusQuery", BuildPipelineRunStatusQuery(lookback)},
 		{"BuildPipelineRunDurationQuery", BuildPipelineRunDurationQuery(lookback)},
@@ -430,6 +1008,10 @@ func TestAllQueriesContainAggregation(t *testing.T) {
 		{"BuildQueryErrorsQuery", BuildQueryErrorsQuery(lookback)},
 		{"BuildQueryDurationQuery", BuildQueryDurationQuery(lookback)},
 		{"BuildQueriesRunningQuery", BuildQueriesRunningQuery(lookback)},
+		{"BuildWarehouseIdleQuery", BuildWarehouseIdleQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildQueryCostQuery", BuildQueryCostQuery(billingLookback, 10, BillingCostModeCurrent, nil)},
+		{"BuildJobCostQuery", BuildJobCostQuery(billingLookback, BillingCostModeCurrent, nil)},
+		{"BuildPipelineCostQuery", BuildPipelineCostQuery(billingLookback, BillingCostModeCurrent, nil)},
 	}
 
 	for _, tt := range queries {
@@ -459,13 +1041,20 @@ func TestQueriesContainCorrectSystemTable(t *testing.T) {
 		tableName string
 	}{
 		{"BuildBillingDBUsQuery", BuildBillingDBUsQuery(billingLookback), "system.billing.usage"},
-		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback), "system.billing.usage"},
+		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback, BillingCostModeCurrent, nil), "system.billing.usage"},
 		{"BuildPriceChangeEventsQuery", BuildPriceChangeEventsQuery(billingLookback), "system.billing.list_prices"},
+		{"BuildBillingByProductQuery", BuildBillingByProductQuery(billingLookback, BillingCostModeCurrent, nil), "system.billing.usage"},
+		{"BuildBillingAttributionQuery", BuildBillingAttributionQuery(billingLookback, map[string]int{"job_id": 10}, BillingCostModeCurrent, nil), "system.billing.usage"},
 		{"BuildJobRunsQuery", BuildJobRunsQuery(lookback), "system.lakeflow.job_run_timeline"},
 		{"BuildJobRunStatusQuery", BuildJobRunStatusQuery(lookback), "system.lakeflow.job_run_timeline"},
+		{"BuildJobRunTerminationsQuery", BuildJobRunTerminationsQuery(lookback), "system.lakeflow.job_run_timeline"},
 		{"BuildJobRunDurationQuery", BuildJobRunDurationQuery(lookback), "system.lakeflow.job_run_timeline"},
+		{"BuildJobRunDurationHistogramQuery", BuildJobRunDurationHistogramQuery(lookback, DefaultJobDurationBuckets), "system.lakeflow.job_run_timeline"},
 		{"BuildTaskRetriesQuery", BuildTaskRetriesQuery(lookback), "system.lakeflow.job_task_run_timeline"},
-		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600), "system.lakeflow.job_run_timeline"},
+		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600, nil, DefaultJobSLATag), "system.lakeflow.job_run_timeline"},
+		{"BuildJobRunsActiveQuery", BuildJobRunsActiveQuery(lookback, DefaultJobsActiveLookback), "system.lakeflow.job_run_timeline"},
+		{"BuildJobRunPhaseDurationHistogramQuery", BuildJobRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets), "system.lakeflow.job_run_timeline"},
+		{"BuildTaskRunPhaseDurationHistogramQuery", BuildTaskRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets), "system.lakeflow.job_task_run_timeline"},
 		{"BuildPipelineRunsQuery", BuildPipelineRunsQuery(lookback), "system.lakeflow.pipeline_update_timeline"},
 		{"BuildPipelineRunStatusQuery", BuildPipelineRunStatusQuery(lookback), "system.lakeflow.pipeline_update_timeline"},
 		{"BuildPipelineRunDurationQuery", BuildPipelineRunDurationQuery(lookback), "system.lakeflow.pipeline_update_timeline"},
@@ -475,6 +1064,10 @@ func TestQueriesContainCorrectSystemTable(t *testing.T) {
 		{"BuildQueryErrorsQuery", BuildQueryErrorsQuery(lookback), "system.query.history"},
 		{"BuildQueryDurationQuery", BuildQueryDurationQuery(lookback), "system.query.history"},
 		{"BuildQueriesRunningQuery", BuildQueriesRunningQuery(lookback), "system.query.history"},
+		{"BuildWarehouseIdleQuery", BuildWarehouseIdleQuery(billingLookback, BillingCostModeCurrent, nil), "system.query.history"},
+		{"BuildQueryCostQuery", BuildQueryCostQuery(billingLookback, 10, BillingCostModeCurrent, nil), "system.query.history"},
+		{"BuildJobCostQuery", BuildJobCostQuery(billingLookback, BillingCostModeCurrent, nil), "system.lakeflow.job_run_timeline"},
+		{"BuildPipelineCostQuery", BuildPipelineCostQuery(billingLookback, BillingCostModeCurrent, nil), "system.lakeflow.pipeline_update_timeline"},
 	}
 
 	for _, tt := range tests {
@@ -496,13 +1089,20 @@ func TestQueriesContainWorkspaceID(t *testing.T) {
 		shouldContain bool
 	}{
 		{"BuildBillingDBUsQuery", BuildBillingDBUsQuery(billingLookback), true},
-		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback), true},
+		{"BuildBillingCostEstimateQuery", BuildBillingCostEstimateQuery(billingLookback, BillingCostModeCurrent, nil), true},
 		{"BuildPriceChangeEventsQuery", BuildPriceChangeEventsQuery(billingLookback), false}, // No workspace_id
+		{"BuildBillingByProductQuery", BuildBillingByProductQuery(billingLookback, BillingCostModeCurrent, nil), true},
+		{"BuildBillingAttributionQuery", BuildBillingAttributionQuery(billingLookback, map[string]int{"job_id": 10}, BillingCostModeCurrent, nil), true},
 		{"BuildJobRunsQuery", BuildJobRunsQuery(lookback), true},
 		{"BuildJobRunStatusQuery", BuildJobRunStatusQuery(lookback), true},
+		{"BuildJobRunTerminationsQuery", BuildJobRunTerminationsQuery(lookback), true},
 		{"BuildJobRunDurationQuery", BuildJobRunDurationQuery(lookback), true},
+		{"BuildJobRunDurationHistogramQuery", BuildJobRunDurationHistogramQuery(lookback, DefaultJobDurationBuckets), true},
 		{"BuildTaskRetriesQuery", BuildTaskRetriesQuery(lookback), true},
-		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600), true},
+		{"BuildJobSLAMissQuery", BuildJobSLAMissQuery(lookback, 3600, nil, DefaultJobSLATag), true},
+		{"BuildJobRunsActiveQuery", BuildJobRunsActiveQuery(lookback, DefaultJobsActiveLookback), true},
+		{"BuildJobRunPhaseDurationHistogramQuery", BuildJobRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets), true},
+		{"BuildTaskRunPhaseDurationHistogramQuery", BuildTaskRunPhaseDurationHistogramQuery(lookback, DefaultJobPhaseDurationBuckets), true},
 		{"BuildPipelineRunsQuery", BuildPipelineRunsQuery(lookback), true},
 		{"BuildPipelineRunStatusQuery", BuildPipelineRunStatusQuery(lookback), true},
 		{"BuildPipelineRunDurationQuery", BuildPipelineRunDurationQuery(lookback), true},
@@ -512,6 +1112,10 @@ func TestQueriesContainWorkspaceID(t *testing.T) {
 		{"BuildQueryErrorsQuery", BuildQueryErrorsQuery(lookback), true},
 		{"BuildQueryDurationQuery", BuildQueryDurationQuery(lookback), true},
 		{"BuildQueriesRunningQuery", BuildQueriesRunningQuery(lookback), true},
+		{"BuildWarehouseIdleQuery", BuildWarehouseIdleQuery(billingLookback, BillingCostModeCurrent, nil), true},
+		{"BuildQueryCostQuery", BuildQueryCostQuery(billingLookback, 10, BillingCostModeCurrent, nil), true},
+		{"BuildJobCostQuery", BuildJobCostQuery(billingLookback, BillingCostModeCurrent, nil), true},
+		{"BuildPipelineCostQuery", BuildPipelineCostQuery(billingLookback, BillingCostModeCurrent, nil), true},
 	}
 
 	for _, tt := range queries {
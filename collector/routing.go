@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector names, used as scrape_status labels and as warehouse route keys.
+const (
+	collectorBilling   = "billing"
+	collectorJobs      = "jobs"
+	collectorPipelines = "pipelines"
+	collectorQueries   = "queries"
+)
+
+// Query names, used as warehouse route keys and on per-query metrics.
+const (
+	queryBillingDBUs          = "billing_dbus"
+	queryBillingCost          = "billing_cost"
+	queryPriceChanges         = "price_changes"
+	queryBillingByProduct     = "billing_by_product"
+	queryBillingByCompute     = "billing_by_compute"
+	queryBillingByIdentity    = "billing_by_identity"
+	queryBillingAttribution   = "billing_attribution"
+	queryBillingByTag         = "billing_by_tag"
+	queryBillingCostEffective = "billing_cost_effective"
+	queryBillingCalendar      = "billing_calendar"
+	queryBudgetSpend          = "budget_spend"
+	queryBillingAnomaly       = "billing_anomaly"
+	queryWarehouseIdle        = "warehouse_idle"
+
+	queryJobRuns         = "job_runs"
+	queryJobRunStatus    = "job_run_status"
+	queryJobTerminations = "job_run_terminations"
+	queryJobRunDuration  = "job_run_duration"
+	queryJobRunHistogram = "job_run_duration_histogram"
+	queryJobRunPhases    = "job_run_phase_duration_histogram"
+	queryTaskRunPhases   = "task_run_phase_duration_histogram"
+	queryTaskRetries     = "task_retries"
+	queryJobSLAMiss      = "job_sla_miss"
+	queryJobRunsActive   = "job_runs_active"
+	queryJobCost         = "job_cost"
+
+	queryPipelineTableCheck   = "pipeline_table_check"
+	queryPipelineRuns         = "pipeline_runs"
+	queryPipelineRunStatus    = "pipeline_run_status"
+	queryPipelineRunDuration  = "pipeline_run_duration"
+	queryPipelineRetryEvents  = "pipeline_retry_events"
+	queryPipelineFreshnessLag = "pipeline_freshness_lag"
+	queryPipelineCost         = "pipeline_cost"
+
+	queryQueryCount     = "query_count"
+	queryQueryErrors    = "query_errors"
+	queryQueryDuration  = "query_duration"
+	queryQueriesRunning = "queries_running"
+	queryQueryCost      = "query_cost"
+)
+
+// queryCollectors maps each routable query to the collector that runs it.
+// A route for a query takes precedence over a route for its collector.
+var queryCollectors = map[string]string{
+	queryBillingDBUs:          collectorBilling,
+	queryBillingCost:          collectorBilling,
+	queryPriceChanges:         collectorBilling,
+	queryBillingByProduct:     collectorBilling,
+	queryBillingByCompute:     collectorBilling,
+	queryBillingByIdentity:    collectorBilling,
+	queryBillingAttribution:   collectorBilling,
+	queryBillingByTag:         collectorBilling,
+	queryBillingCostEffective: collectorBilling,
+	queryBillingCalendar:      collectorBilling,
+	queryBudgetSpend:          collectorBilling,
+	queryBillingAnomaly:       collectorBilling,
+	queryWarehouseIdle:        collectorBilling,
+
+	queryJobRuns:         collectorJobs,
+	queryJobRunStatus:    collectorJobs,
+	queryJobTerminations: collectorJobs,
+	queryJobRunDuration:  collectorJobs,
+	queryJobRunHistogram: collectorJobs,
+	queryJobRunPhases:    collectorJobs,
+	queryTaskRunPhases:   collectorJobs,
+	queryTaskRetries:     collectorJobs,
+	queryJobSLAMiss:      collectorJobs,
+	queryJobRunsActive:   collectorJobs,
+	queryJobCost:         collectorJobs,
+
+	queryPipelineTableCheck:   collectorPipelines,
+	queryPipelineRuns:         collectorPipelines,
+	queryPipelineRunStatus:    collectorPipelines,
+	queryPipelineRunDuration:  collectorPipelines,
+	queryPipelineRetryEvents:  collectorPipelines,
+	queryPipelineFreshnessLag: collectorPipelines,
+	queryPipelineCost:         collectorPipelines,
+
+	queryQueryCount:     collectorQueries,
+	queryQueryErrors:    collectorQueries,
+	queryQueryDuration:  collectorQueries,
+	queryQueriesRunning: collectorQueries,
+	queryQueryCost:      collectorQueries,
+}
+
+// isRouteKey reports whether key names a collector or a routable query.
+func isRouteKey(key string) bool {
+	if _, ok := queryCollectors[key]; ok {
+		return true
+	}
+	switch key {
+	case collectorBilling, collectorJobs, collectorPipelines, collectorQueries:
+		return true
+	}
+	return false
+}
+
+// routedDB is a connection pool for one SQL warehouse. err is set when the
+// warehouse could not be reached this scrape.
+type routedDB struct {
+	db   *sql.DB
+	path string
+	err  error
+}
+
+// queryRouter sends each named query to the SQL warehouse configured for it.
+type queryRouter struct {
+	fallback routedDB            // Default warehouse for unrouted queries
+	routes   map[string]routedDB // Keyed by query or collector name
+	metrics  *MetricDescriptors  // Per-query metrics are emitted when non-nil
+}
+
+// resolve returns the warehouse for a query, preferring a query route over its collector's route.
+func (r *queryRouter) resolve(name string) routedDB {
+	if target, ok := r.routes[name]; ok {
+		return target
+	}
+	if target, ok := r.routes[queryCollectors[name]]; ok {
+		return target
+	}
+	return r.fallback
+}
+
+// query runs a named query on its warehouse. When ch is non-nil and per-query
+// metrics are enabled, the query duration is emitted with the warehouse label.
+func (r *queryRouter) query(ctx context.Context, ch chan<- prometheus.Metric, name, query string) (*sql.Rows, error) {
+	target := r.resolve(name)
+	if target.err != nil {
+		return nil, fmt.Errorf("warehouse %s unavailable: %w", target.path, target.err)
+	}
+
+	start := time.Now()
+	rows, err := target.db.QueryContext(ctx, query)
+
+	if ch != nil && r.metrics != nil {
+		ch <- prometheus.MustNewConstMetric(
+			r.metrics.QueryScrapeDuration,
+			prometheus.GaugeValue,
+			time.Since(start).Seconds(),
+			name,
+			target.path,
+		)
+	}
+
+	return rows, err
+}
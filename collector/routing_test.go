@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/promslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSingleDBRouter returns a router that sends every query to db without per-query metrics.
+func newSingleDBRouter(db *sql.DB) *queryRouter {
+	return &queryRouter{fallback: routedDB{db: db}}
+}
+
+func TestIsRouteKey(t *testing.T) {
+	for _, key := range []string{"billing", "jobs", "pipelines", "queries", "billing_cost", "queries_running"} {
+		assert.True(t, isRouteKey(key), "expected %q to be a route key", key)
+	}
+	assert.False(t, isRouteKey("billing_everything"))
+	assert.False(t, isRouteKey(""))
+}
+
+func TestQueryRouter_Resolve(t *testing.T) {
+	primary := routedDB{path: "/primary"}
+	heavy := routedDB{path: "/heavy"}
+	light := routedDB{path: "/light"}
+
+	router := &queryRouter{
+		fallback: primary,
+		routes: map[string]routedDB{
+			collectorBilling:    heavy,
+			queryPriceChanges:   light,
+			queryQueriesRunning: heavy,
+		},
+	}
+
+	assert.Equal(t, "/heavy", router.resolve(queryBillingCost).path, "collector route should apply to its queries")
+	assert.Equal(t, "/light", router.resolve(queryPriceChanges).path, "query route should override collector route")
+	assert.Equal(t, "/heavy", router.resolve(queryQueriesRunning).path, "query route should apply without collector route")
+	assert.Equal(t, "/primary", router.resolve(queryQueryCount).path, "unrouted queries should use the primary")
+	assert.Equal(t, "/primary", router.resolve(queryJobRuns).path)
+}
+
+func TestQueryRouter_QueryEmitsDuration(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err, "failed to create mock db")
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"one"}).AddRow(1))
+
+	router := &queryRouter{
+		fallback: routedDB{db: db, path: "/sql/1.0/warehouses/primary"},
+		metrics:  NewMetricDescriptors(),
+	}
+
+	ch := make(chan prometheus.Metric, 1)
+	rows, err := router.query(t.Context(), ch, queryJobRuns, "SELECT 1")
+	require.NoError(t, err)
+	rows.Close()
+	close(ch)
+
+	m := <-ch
+	require.NotNil(t, m, "expected a per-query duration metric")
+	pb := &dto.Metric{}
+	require.NoError(t, m.Write(pb))
+
+	labels := make(map[string]string)
+	for _, lp := range pb.Label {
+		labels[lp.GetName()] = lp.GetValue()
+	}
+	assert.Equal(t, queryJobRuns, labels["query"])
+	assert.Equal(t, "/sql/1.0/warehouses/primary", labels["warehouse_http_path"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryRouter_UnavailableWarehouse(t *testing.T) {
+	router := &queryRouter{
+		routes: map[string]routedDB{
+			collectorBilling: {path: "/heavy", err: errors.New("connection refused")},
+		},
+		metrics: NewMetricDescriptors(),
+	}
+
+	ch := make(chan prometheus.Metric, 1)
+	_, err := router.query(t.Context(), ch, queryBillingCost, "SELECT 1")
+	close(ch)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/heavy")
+	assert.Empty(t, ch, "no duration should be emitted for queries that never ran")
+}
+
+func TestCollector_RoutesQueriesToDedicatedWarehouse(t *testing.T) {
+	primaryDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primaryDB.Close()
+
+	heavyDB, heavyMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer heavyDB.Close()
+
+	// All three default billing queries must run on the dedicated warehouse.
+	heavyMock.MatchExpectationsInOrder(false)
+	for range 3 {
+		heavyMock.ExpectQuery("system.billing").WillReturnRows(sqlmock.NewRows([]string{"unused"}))
+	}
+
+	config := &Config{
+		ServerHostname:    "test.databricks.com",
+		WarehouseHTTPPath: "/sql/1.0/warehouses/primary",
+		ClientID:          "test-id",
+		ClientSecret:      "test-secret",
+		WarehouseRoutes:   map[string]string{collectorBilling: "/sql/1.0/warehouses/heavy"},
+	}
+	collector := NewCollector(promslog.NewNopLogger(), config)
+	collector.openDatabase = func(_ *Config, httpPath string) (*sql.DB, error) {
+		if httpPath == "/sql/1.0/warehouses/heavy" {
+			return heavyDB, nil
+		}
+		return primaryDB, nil
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	metricFamilies, err := registry.Gather()
+	require.NoError(t, err)
+
+	require.NoError(t, heavyMock.ExpectationsWereMet(), "billing queries were not routed to the dedicated warehouse")
+
+	roles := make(map[string]string)
+	for _, mf := range metricFamilies {
+		if mf.GetName() != "databricks_exporter_active_warehouse_info" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			labels := make(map[string]string)
+			for _, lp := range m.Label {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			roles[labels["warehouse_http_path"]] = labels["role"]
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"/sql/1.0/warehouses/primary": warehouseRolePrimary,
+		"/sql/1.0/warehouses/heavy":   warehouseRoleRouted,
+	}, roles)
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// JobSLAs is the contents of the job SLA file.
+type JobSLAs struct {
+	JobSLAs []JobSLA `yaml:"job_slas"`
+}
+
+// JobSLA sets the run duration threshold of the jobs it matches. Exactly one of JobID or
+// JobName must be set. When several match a job, the first one in the file applies.
+type JobSLA struct {
+	JobID            string `yaml:"job_id"`            // Matches job_id exactly
+	JobName          string `yaml:"job_name"`          // Regular expression matched against job_name (RLIKE)
+	ThresholdSeconds int    `yaml:"threshold_seconds"` // Runs longer than this miss the SLA
+}
+
+var (
+	errJobSLATarget    = errors.New("exactly one of job_id or job_name must be set")
+	errJobSLAThreshold = errors.New("threshold_seconds must be positive")
+)
+
+// LoadJobSLAs reads and validates a YAML job SLA file.
+func LoadJobSLAs(path string) ([]JobSLA, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job SLA file: %w", err)
+	}
+
+	var file JobSLAs
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse job SLA file %s: %w", path, err)
+	}
+
+	if err := validateJobSLAs(file.JobSLAs); err != nil {
+		return nil, fmt.Errorf("invalid job SLA file %s: %w", path, err)
+	}
+	return file.JobSLAs, nil
+}
+
+// validateJobSLAs checks that every SLA has one target and a positive threshold. Name patterns
+// are matched by Databricks (Java regular expressions); compiling them here catches most
+// syntax errors at startup rather than on every scrape.
+func validateJobSLAs(slas []JobSLA) error {
+	for i, s := range slas {
+		if (s.JobID == "") == (s.JobName == "") {
+			return fmt.Errorf("job SLA %d: %w", i+1, errJobSLATarget)
+		}
+		if s.ThresholdSeconds <= 0 {
+			return fmt.Errorf("job SLA %d: %w", i+1, errJobSLAThreshold)
+		}
+		if s.JobName != "" {
+			if _, err := regexp.Compile(s.JobName); err != nil {
+				return fmt.Errorf("job SLA %d: invalid job_name pattern: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// jobSLAThreshold returns the SQL expression for the SLA threshold of a job run, from the job_id,
+// job_name and sla_tag columns: the first matching SLA, then a positive whole number of seconds in
+// the job tag, then defaultSeconds.
+func jobSLAThreshold(slas []JobSLA, defaultSeconds int) string {
+	expr := "CASE"
+	for _, s := range slas {
+		if s.JobID != "" {
+			expr += fmt.Sprintf("\n\t\t\t\t\tWHEN job_id = %s THEN %d", sqlStringLiteral(s.JobID), s.ThresholdSeconds)
+		} else {
+			expr += fmt.Sprintf("\n\t\t\t\t\tWHEN job_name RLIKE %s THEN %d", sqlStringLiteral(s.JobName), s.ThresholdSeconds)
+		}
+	}
+	expr += "\n\t\t\t\t\tWHEN TRY_CAST(sla_tag AS BIGINT) > 0 THEN TRY_CAST(sla_tag AS BIGINT)"
+	expr += fmt.Sprintf("\n\t\t\t\t\tELSE %d\n\t\t\t\tEND", defaultSeconds)
+	return expr
+}
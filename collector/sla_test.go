@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadJobSLAs(t *testing.T) {
+	slas, err := LoadJobSLAs("testdata/job_slas.yaml")
+	require.NoError(t, err)
+	require.Len(t, slas, 2)
+
+	assert.Equal(t, JobSLA{JobID: "123456789012345", ThresholdSeconds: 900}, slas[0])
+	assert.Equal(t, JobSLA{JobName: "^nightly-.*", ThresholdSeconds: 14400}, slas[1])
+}
+
+func TestLoadJobSLAs_Errors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	_, err := LoadJobSLAs(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read")
+
+	_, err = LoadJobSLAs(write("unknown.yaml", "job_slas:\n  - job_id: \"1\"\n    threshold: 60\n"))
+	assert.ErrorContains(t, err, "failed to parse", "unknown fields should be rejected")
+
+	_, err = LoadJobSLAs(write("pattern.yaml", "job_slas:\n  - job_name: \"nightly-(\"\n    threshold_seconds: 60\n"))
+	assert.ErrorContains(t, err, "invalid job_name pattern")
+}
+
+func TestValidateJobSLAs(t *testing.T) {
+	assert.NoError(t, validateJobSLAs(nil))
+	assert.NoError(t, validateJobSLAs([]JobSLA{{JobID: "1", ThresholdSeconds: 60}}))
+	assert.ErrorIs(t, validateJobSLAs([]JobSLA{{ThresholdSeconds: 60}}), errJobSLATarget)
+	assert.ErrorIs(t, validateJobSLAs([]JobSLA{{JobID: "1", JobName: "a", ThresholdSeconds: 60}}), errJobSLATarget)
+	assert.ErrorIs(t, validateJobSLAs([]JobSLA{{JobID: "1"}}), errJobSLAThreshold)
+}
@@ -13,7 +13,7 @@ import (
 // SQLWarehouseCollector collects SQL warehouse-related metrics from Databricks.
 type SQLWarehouseCollector struct {
 	logger *slog.Logger
-	db     *sql.DB
+	router *queryRouter // Resolves the warehouse for each query
 	ctx    context.Context
 	config *Config
 
@@ -22,10 +22,10 @@ type SQLWarehouseCollector struct {
 }
 
 // NewSQLWarehouseCollector creates a new SQLWarehouseCollector.
-func NewSQLWarehouseCollector(ctx context.Context, db *sql.DB, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *SQLWarehouseCollector {
+func NewSQLWarehouseCollector(ctx context.Context, router *queryRouter, metrics *MetricDescriptors, config *Config, logger *slog.Logger) *SQLWarehouseCollector {
 	return &SQLWarehouseCollector{
 		logger:  logger,
-		db:      db,
+		router:  router,
 		metrics: metrics,
 		ctx:     ctx,
 		config:  config,
@@ -38,7 +38,10 @@ func (c *SQLWarehouseCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.metrics.QueryDurationSeconds
 	ch <- c.metrics.QueryErrors
 	ch <- c.metrics.QueriesRunning
+	ch <- c.metrics.QueryCostByUser
+	ch <- c.metrics.QueryCostBySource
 	ch <- c.metrics.ScrapeStatus
+	ch <- c.metrics.QueryScrapeDuration
 }
 
 // Collect fetches metrics from Databricks and sends them to Prometheus.
@@ -69,12 +72,20 @@ func (c *SQLWarehouseCollector) Collect(ch chan<- prometheus.Metric) {
 		hasError = true
 	}
 
+	// Per-query cost attribution joins billing with query history, so it is opt-in
+	if c.config.CollectQueryCost {
+		if err := c.collectQueryCost(ch); err != nil {
+			c.logger.Error("Failed to collect query cost", "err", err)
+			hasError = true
+		}
+	}
+
 	// Emit scrape status
 	status := 1.0
 	if hasError {
 		status = 0.0
 	}
-	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, "queries")
+	ch <- prometheus.MustNewConstMetric(c.metrics.ScrapeStatus, prometheus.GaugeValue, status, collectorQueries)
 
 	c.logger.Debug("Finished collecting SQL warehouse metrics", "duration_seconds", time.Since(start).Seconds())
 }
@@ -86,7 +97,7 @@ func (c *SQLWarehouseCollector) collectQueries(ch chan<- prometheus.Metric) erro
 		lookback = DefaultQueriesLookback
 	}
 	query := BuildQueriesQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryQueryCount, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute queries query: %w", err)
 	}
@@ -121,7 +132,7 @@ func (c *SQLWarehouseCollector) collectQueryErrors(ch chan<- prometheus.Metric)
 		lookback = DefaultQueriesLookback
 	}
 	query := BuildQueryErrorsQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryQueryErrors, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute query errors query: %w", err)
 	}
@@ -156,7 +167,7 @@ func (c *SQLWarehouseCollector) collectQueryDuration(ch chan<- prometheus.Metric
 		lookback = DefaultQueriesLookback
 	}
 	query := BuildQueryDurationQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryQueryDuration, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute query duration query: %w", err)
 	}
@@ -217,7 +228,7 @@ func (c *SQLWarehouseCollector) collectQueriesRunning(ch chan<- prometheus.Metri
 		lookback = DefaultQueriesLookback
 	}
 	query := BuildQueriesRunningQuery(lookback)
-	rows, err := c.db.QueryContext(c.ctx, query)
+	rows, err := c.router.query(c.ctx, ch, queryQueriesRunning, query)
 	if err != nil {
 		return fmt.Errorf("failed to execute running queries query: %w", err)
 	}
@@ -244,3 +255,46 @@ func (c *SQLWarehouseCollector) collectQueriesRunning(ch chan<- prometheus.Metri
 
 	return rows.Err()
 }
+
+// collectQueryCost collects warehouse cost apportioned to queries, per user and per query source.
+func (c *SQLWarehouseCollector) collectQueryCost(ch chan<- prometheus.Metric) error {
+	lookback := c.config.BillingLookback
+	if lookback == 0 {
+		lookback = DefaultBillingLookback
+	}
+	query := BuildQueryCostQuery(lookback, c.config.QueryCostUserLimit, c.config.billingCostMode(), c.config.FXRates)
+	rows, err := c.router.query(c.ctx, ch, queryQueryCost, query)
+	if err != nil {
+		return fmt.Errorf("failed to execute query cost query: %w", err)
+	}
+	defer rows.Close()
+
+	descs := map[string]*prometheus.Desc{
+		queryCostByUser:   c.metrics.QueryCostByUser,
+		queryCostBySource: c.metrics.QueryCostBySource,
+	}
+	for rows.Next() {
+		var dimension, workspaceID, warehouseID, value, currencyCode sql.NullString
+		var cost sql.NullFloat64
+
+		if err := rows.Scan(&dimension, &workspaceID, &warehouseID, &value, &currencyCode, &cost); err != nil {
+			return fmt.Errorf("failed to scan query cost row: %w", err)
+		}
+
+		desc, ok := descs[dimension.String]
+		if !ok || !cost.Valid {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			desc,
+			prometheus.GaugeValue,
+			cost.Float64,
+			workspaceID.String,
+			warehouseID.String,
+			value.String,
+			currencyCode.String,
+		)
+	}
+
+	return rows.Err()
+}
@@ -3,11 +3,13 @@ package collector
 import (
 	"context"
 	"errors"
+	"maps"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/promslog"
 )
 
@@ -20,7 +22,7 @@ func TestNewSQLWarehouseCollector(t *testing.T) {
 	defer db.Close()
 
 	metrics := NewMetricDescriptors()
-	collector := NewSQLWarehouseCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	if collector == nil {
 		t.Fatal("expected collector to be created, got nil")
@@ -30,8 +32,8 @@ func TestNewSQLWarehouseCollector(t *testing.T) {
 		t.Error("collector logger should not be nil")
 	}
 
-	if collector.db == nil {
-		t.Error("collector db should not be nil")
+	if collector.router == nil {
+		t.Error("collector router should not be nil")
 	}
 
 	if collector.metrics == nil {
@@ -48,9 +50,9 @@ func TestSQLWarehouseCollector_Describe(t *testing.T) {
 	defer db.Close()
 
 	metrics := NewMetricDescriptors()
-	collector := NewSQLWarehouseCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
-	descCh := make(chan *prometheus.Desc, 10)
+	descCh := make(chan *prometheus.Desc, 20)
 	go func() {
 		collector.Describe(descCh)
 		close(descCh)
@@ -61,7 +63,7 @@ func TestSQLWarehouseCollector_Describe(t *testing.T) {
 		descriptions = append(descriptions, desc)
 	}
 
-	expectedCount := 5 // Queries, QueryDurationSeconds, QueryErrors, QueriesRunning, ScrapeStatus
+	expectedCount := 8 // Queries, QueryDurationSeconds, QueryErrors, QueriesRunning, QueryCostByUser, QueryCostBySource, ScrapeStatus, QueryScrapeDuration
 	if len(descriptions) != expectedCount {
 		t.Errorf("expected %d metric descriptions, got %d", expectedCount, len(descriptions))
 	}
@@ -83,7 +85,7 @@ func TestSQLWarehouseCollector_CollectQueries(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.query.history").WillReturnRows(rows)
 
 	metrics := NewMetricDescriptors()
-	collector := NewSQLWarehouseCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -139,7 +141,7 @@ func TestSQLWarehouseCollector_CollectQueryErrors(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "warehouse_id", "max_concurrent"}))
 
 	metrics := NewMetricDescriptors()
-	collector := NewSQLWarehouseCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -194,7 +196,7 @@ func TestSQLWarehouseCollector_CollectQueryDuration(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "warehouse_id", "max_concurrent"}))
 
 	metrics := NewMetricDescriptors()
-	collector := NewSQLWarehouseCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Create a registry and register the collector
 	registry := prometheus.NewRegistry()
@@ -247,7 +249,7 @@ func TestSQLWarehouseCollector_CollectWithError(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "warehouse_id", "max_concurrent"}))
 
 	metrics := NewMetricDescriptors()
-	collector := NewSQLWarehouseCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	ch := make(chan prometheus.Metric, 10)
 	go func() {
@@ -290,7 +292,7 @@ func TestSQLWarehouseCollector_CollectQueriesRunning(t *testing.T) {
 	mock.ExpectQuery("SELECT(.+)FROM system.query.history").WillReturnRows(rows)
 
 	metrics := NewMetricDescriptors()
-	collector := NewSQLWarehouseCollector(context.Background(), db, metrics, DefaultConfig(), logger)
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), metrics, DefaultConfig(), logger)
 
 	// Use testutil to count metrics
 	count := testutil.CollectAndCount(collector)
@@ -304,3 +306,65 @@ func TestSQLWarehouseCollector_CollectQueriesRunning(t *testing.T) {
 		t.Errorf("unfulfilled expectations: %v", err)
 	}
 }
+
+func TestSQLWarehouseCollector_CollectQueryCost(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"dimension", "workspace_id", "warehouse_id", "value", "currency_code", "cost_usd"}).
+		AddRow("executed_by", "123456789", "wh1", "analyst@example.com", "USD", 6.0).
+		AddRow("executed_by", "123456789", "wh1", "__other__", "USD", 2.0).
+		AddRow("query_source", "123456789", "wh1", "dashboard", "USD", 5.0).
+		AddRow("query_source", "123456789", "wh1", "genie", "USD", 3.0).
+		AddRow("unexpected", "123456789", "wh1", "x", "USD", 1.0)
+
+	mock.ExpectQuery("SELECT(.+)FROM user_cost(.+)UNION ALL(.+)FROM query_cost").WillReturnRows(rows)
+
+	config := DefaultConfig()
+	config.CollectQueryCost = true
+	collector := NewSQLWarehouseCollector(context.Background(), newSingleDBRouter(db), NewMetricDescriptors(), config, logger)
+
+	ch := make(chan prometheus.Metric, 10)
+	if err := collector.collectQueryCost(ch); err != nil {
+		t.Fatalf("collectQueryCost failed: %v", err)
+	}
+	close(ch)
+
+	byUser := make(map[string]float64)
+	bySource := make(map[string]float64)
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil {
+			t.Fatalf("failed to write metric: %v", err)
+		}
+		labels := make(map[string]string)
+		for _, lp := range pb.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		switch m.Desc() {
+		case collector.metrics.QueryCostByUser:
+			byUser[labels[labelExecutedBy]] = pb.Gauge.GetValue()
+		case collector.metrics.QueryCostBySource:
+			bySource[labels[labelQuerySource]] = pb.Gauge.GetValue()
+		default:
+			t.Errorf("unexpected metric %s", m.Desc())
+		}
+	}
+
+	expectedByUser := map[string]float64{"analyst@example.com": 6.0, "__other__": 2.0}
+	expectedBySource := map[string]float64{"dashboard": 5.0, "genie": 3.0}
+	if !maps.Equal(byUser, expectedByUser) {
+		t.Errorf("expected cost by user %v, got %v", expectedByUser, byUser)
+	}
+	if !maps.Equal(bySource, expectedBySource) {
+		t.Errorf("expected cost by source %v, got %v", expectedBySource, bySource)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
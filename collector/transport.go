@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/databricks/databricks-sql-go/auth"
+	"github.com/databricks/databricks-sql-go/auth/oauth/m2m"
+	"golang.org/x/oauth2"
+)
+
+// hasCustomTransport reports whether any outbound transport option is configured.
+// When none are set, the SQL connector keeps its own pooled transport and the
+// OAuth client uses http.DefaultTransport, matching the driver defaults.
+func (c Config) hasCustomTransport() bool {
+	return c.ProxyURL != "" || c.TLSCAFile != "" || c.TLSCertFile != ""
+}
+
+// newHTTPTransport builds the HTTP transport shared by the SQL connector and the
+// OAuth token client. Pool settings mirror the driver's PooledTransport.
+func newHTTPTransport(config *Config) (*http.Transport, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.TLSCAFile != "" {
+		// Extend (rather than replace) the system roots so that a proxy CA
+		// bundle does not break direct connections to Databricks.
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in CA file %s", config.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       180 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConnsPerHost:   10, // Only one Databricks host is used
+		MaxConnsPerHost:       100,
+	}, nil
+}
+
+// oauthClientTimeout bounds OIDC discovery and token requests.
+const oauthClientTimeout = 30 * time.Second
+
+// newConfiguredAuthenticator creates the M2M authenticator for config. Token requests
+// go through transport when it is non-nil, and through http.DefaultTransport otherwise,
+// and are bounded by oauthClientTimeout either way.
+func newConfiguredAuthenticator(config *Config, transport *http.Transport) auth.Authenticator {
+	client := &http.Client{Timeout: oauthClientTimeout}
+	if transport != nil {
+		client.Transport = transport
+	}
+	return newM2MAuthenticator(config.ClientID, config.ClientSecret, config.ServerHostname, client)
+}
+
+// m2mAuthenticator implements OAuth2 M2M authentication like the driver's m2m package,
+// but fetches tokens through a caller-provided HTTP client so that proxy and TLS
+// settings also apply to OIDC discovery and token requests.
+type m2mAuthenticator struct {
+	clientID     string
+	clientSecret string
+	hostName     string
+	httpClient   *http.Client // nil uses http.DefaultClient
+
+	mu          sync.Mutex
+	tokenSource oauth2.TokenSource
+}
+
+// newM2MAuthenticator creates an OAuth2 M2M authenticator that uses the given HTTP client.
+func newM2MAuthenticator(clientID, clientSecret, hostName string, httpClient *http.Client) auth.Authenticator {
+	return &m2mAuthenticator{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		hostName:     hostName,
+		httpClient:   httpClient,
+	}
+}
+
+// Authenticate sets the bearer token on the request, fetching or refreshing it as needed.
+func (a *m2mAuthenticator) Authenticate(r *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.tokenSource == nil {
+		ctx := context.Background()
+		if a.httpClient != nil {
+			ctx = context.WithValue(ctx, oauth2.HTTPClient, a.httpClient)
+		}
+
+		cfg, err := m2m.GetConfig(ctx, a.hostName, a.clientID, a.clientSecret, m2m.GetScopes(a.hostName, nil))
+		if err != nil {
+			return fmt.Errorf("unable to generate client credentials config: %w", err)
+		}
+		a.tokenSource = cfg.TokenSource(ctx)
+	}
+
+	token, err := a.tokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to fetch OAuth token: %w", err)
+	}
+	token.SetAuthHeader(r)
+	return nil
+}
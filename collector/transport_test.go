@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestKeyPair writes a self-signed certificate and key to dir and returns their paths.
+func writeTestKeyPair(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "databricks-exporter-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certPath, keyPath
+}
+
+func TestConfigHasCustomTransport(t *testing.T) {
+	assert.False(t, DefaultConfig().hasCustomTransport(), "default config should use driver transport")
+	assert.True(t, Config{ProxyURL: "http://proxy:3128"}.hasCustomTransport())
+	assert.True(t, Config{TLSCAFile: "/ca.pem"}.hasCustomTransport())
+	assert.True(t, Config{TLSCertFile: "/cert.pem", TLSKeyFile: "/key.pem"}.hasCustomTransport())
+}
+
+func TestNewHTTPTransport_Proxy(t *testing.T) {
+	config := DefaultConfig()
+	config.ProxyURL = "http://proxy.internal:3128"
+
+	transport, err := newHTTPTransport(config)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://dbc-abc123.cloud.databricks.com/oidc", nil)
+	require.NoError(t, err)
+
+	proxy, err := transport.Proxy(req)
+	require.NoError(t, err)
+	require.NotNil(t, proxy, "expected proxy to be set")
+	assert.Equal(t, "proxy.internal:3128", proxy.Host)
+}
+
+func TestNewHTTPTransport_CAAndClientCert(t *testing.T) {
+	certPath, keyPath := writeTestKeyPair(t, t.TempDir())
+
+	config := DefaultConfig()
+	config.TLSCAFile = certPath
+	config.TLSCertFile = certPath
+	config.TLSKeyFile = keyPath
+
+	transport, err := newHTTPTransport(config)
+	require.NoError(t, err)
+	require.NotNil(t, transport.TLSClientConfig.RootCAs, "expected custom root CAs")
+	assert.Len(t, transport.TLSClientConfig.Certificates, 1, "expected client certificate")
+}
+
+func TestNewHTTPTransport_InvalidCAFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+
+	config := DefaultConfig()
+	config.TLSCAFile = path
+
+	_, err := newHTTPTransport(config)
+	require.Error(t, err, "expected error for CA file without certificates")
+
+	config.TLSCAFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = newHTTPTransport(config)
+	require.Error(t, err, "expected error for missing CA file")
+}
+
+func TestM2MAuthenticator_UsesHTTPClient(t *testing.T) {
+	// The authenticator must send discovery and token requests through the
+	// provided client. A failing round tripper proves the client is used.
+	var called bool
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		called = true
+		return nil, http.ErrHandlerTimeout
+	})}
+
+	authr := newM2MAuthenticator("id", "secret", "dbc-abc123.cloud.databricks.com", client)
+
+	req := httptest.NewRequest(http.MethodGet, "https://dbc-abc123.cloud.databricks.com/sql", nil)
+	err := authr.Authenticate(req)
+
+	require.Error(t, err)
+	assert.True(t, called, "expected OAuth requests to use the provided HTTP client")
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
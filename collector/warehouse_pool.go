@@ -0,0 +1,261 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// pingTimeout bounds health checks on pooled connections.
+const pingTimeout = 5 * time.Second
+
+// Warehouse roles reported on the active warehouse info metric.
+const (
+	warehouseRolePrimary  = "primary"
+	warehouseRoleFailover = "failover"
+	warehouseRoleRouted   = "routed"
+)
+
+// warehousePool manages the connection pool for an ordered list of SQL warehouses.
+// The first path is the primary. After FailoverThreshold consecutive connection
+// failures the pool moves to the next path, and while on a failover warehouse it
+// retries the primary every FailbackInterval in the background.
+//
+// mu guards the pool state and is never held across network I/O, so a slow ping
+// doesn't block other scrapes or readiness checks. connMu lets only one caller
+// open a new connection at a time.
+type warehousePool struct {
+	paths  []string
+	config *Config
+	logger *slog.Logger
+	open   func(*Config, string) (*sql.DB, error)
+	now    func() time.Time // For testing
+
+	connMu sync.Mutex
+	probes sync.WaitGroup // running failback probes, waited on in tests
+
+	mu           sync.Mutex
+	db           *sql.DB
+	active       int       // index into paths
+	failures     int       // consecutive connection failures on the active path
+	lastFailback time.Time // last time the primary was retried
+	failingBack  bool      // a failback probe is running
+	lastUsed     time.Time // last call to get, zero until the first scrape
+	lastErr      error     // result of the last call to get
+}
+
+// newWarehousePool creates a pool for the given warehouse HTTP paths (primary first).
+func newWarehousePool(paths []string, config *Config, logger *slog.Logger, open func(*Config, string) (*sql.DB, error)) *warehousePool {
+	return &warehousePool{
+		paths:  paths,
+		config: config,
+		logger: logger,
+		open:   open,
+		now:    time.Now,
+	}
+}
+
+// get returns a healthy database connection, creating one if needed.
+// It tests the connection with Ping() and recreates it if unhealthy.
+// The outcome is kept for readiness checks (see lastResult).
+func (p *warehousePool) get() (db *sql.DB, err error) {
+	defer func() {
+		p.mu.Lock()
+		p.lastUsed, p.lastErr = p.now(), err
+		p.mu.Unlock()
+	}()
+
+	p.mu.Lock()
+	db, path := p.db, p.paths[p.active]
+	p.mu.Unlock()
+
+	if db != nil {
+		if err := ping(db); err == nil {
+			p.mu.Lock()
+			p.failures = 0
+			p.maybeFailback()
+			p.mu.Unlock()
+			return db, nil
+		}
+		p.logger.Warn("Existing connection unhealthy, reconnecting", "err", "ping failed", "warehouse_http_path", path)
+		// Close unhealthy connection, unless another caller already replaced it
+		p.mu.Lock()
+		if p.db == db {
+			p.db.Close()
+			p.db = nil
+		}
+		p.mu.Unlock()
+	}
+
+	return p.reconnect()
+}
+
+// reconnect creates a new connection to the active warehouse. Callers that wait on
+// connMu reuse the connection created while they waited.
+func (p *warehousePool) reconnect() (*sql.DB, error) {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+
+	p.mu.Lock()
+	db, active := p.db, p.active
+	p.mu.Unlock()
+	if db != nil {
+		return db, nil
+	}
+
+	db, err := p.connect(p.paths[active])
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		// A failback may have switched warehouses in the meantime
+		if p.active == active {
+			p.recordFailure()
+		}
+		return nil, err
+	}
+	if p.db != nil {
+		// A failback probe connected to the primary in the meantime
+		db.Close()
+		return p.db, nil
+	}
+
+	p.db = db
+	p.failures = 0
+	p.logger.Debug("Created new database connection pool", "warehouse_http_path", p.paths[p.active])
+	return db, nil
+}
+
+// connect opens a pool for path. With failover configured the new pool is pinged,
+// since sql.OpenDB is lazy and would otherwise never surface a dead warehouse.
+func (p *warehousePool) connect(path string) (*sql.DB, error) {
+	db, err := p.open(p.config, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.paths) > 1 {
+		if err := ping(db); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// recordFailure counts a connection failure and fails over once the threshold is reached.
+func (p *warehousePool) recordFailure() {
+	p.failures++
+	if len(p.paths) < 2 {
+		return
+	}
+
+	threshold := p.config.FailoverThreshold
+	if threshold == 0 {
+		threshold = DefaultFailoverThreshold
+	}
+	if p.failures < threshold {
+		return
+	}
+
+	from := p.paths[p.active]
+	p.active = (p.active + 1) % len(p.paths)
+	p.failures = 0
+	p.lastFailback = p.now()
+
+	p.logger.Warn("Failing over to next SQL warehouse",
+		"from", from,
+		"to", p.paths[p.active],
+		"consecutive_failures", threshold,
+	)
+}
+
+// maybeFailback starts a background probe of the primary warehouse while a failover
+// warehouse is active. At most one probe runs at a time. The caller must hold p.mu.
+func (p *warehousePool) maybeFailback() {
+	if p.active == 0 || p.failingBack {
+		return
+	}
+
+	interval := p.config.FailbackInterval
+	if interval == 0 {
+		interval = DefaultFailbackInterval
+	}
+	if p.now().Sub(p.lastFailback) < interval {
+		return
+	}
+	p.lastFailback = p.now()
+	p.failingBack = true
+
+	p.probes.Add(1)
+	go p.failback()
+}
+
+// failback retries the primary warehouse. The current connection is only replaced
+// once the primary answers a ping.
+func (p *warehousePool) failback() {
+	defer p.probes.Done()
+
+	db, err := p.open(p.config, p.paths[0])
+	if err == nil {
+		err = ping(db)
+		if err != nil {
+			db.Close()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failingBack = false
+
+	if err != nil {
+		p.logger.Debug("Primary SQL warehouse still unavailable", "warehouse_http_path", p.paths[0], "err", err)
+		return
+	}
+	if p.active == 0 {
+		// Failover wrapped around to the primary while the probe ran
+		db.Close()
+		return
+	}
+
+	p.logger.Info("Primary SQL warehouse is available again, failing back",
+		"from", p.paths[p.active],
+		"to", p.paths[0],
+	)
+	if p.db != nil {
+		p.db.Close()
+	}
+	p.db = db
+	p.active = 0
+	p.failures = 0
+}
+
+// lastResult returns the HTTP path of the active warehouse, when a scrape last connected to
+// it through get (zero if none has yet) and that attempt's error. It never contacts the
+// warehouse, so readiness checks don't keep an idle warehouse from auto-stopping.
+func (p *warehousePool) lastResult() (string, time.Time, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paths[p.active], p.lastUsed, p.lastErr
+}
+
+// activeWarehouse returns the HTTP path and role of the warehouse currently in use.
+func (p *warehousePool) activeWarehouse() (string, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active == 0 {
+		return p.paths[0], warehouseRolePrimary
+	}
+	return p.paths[p.active], warehouseRoleFailover
+}
+
+// ping checks a connection pool with a bounded timeout.
+func ping(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return db.PingContext(ctx)
+}
@@ -0,0 +1,215 @@
+package collector
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/common/promslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWarehouses opens sqlmock databases whose pings succeed or fail per HTTP path.
+type fakeWarehouses struct {
+	t     *testing.T
+	down  map[string]bool
+	opens map[string]int
+}
+
+func newFakeWarehouses(t *testing.T) *fakeWarehouses {
+	return &fakeWarehouses{t: t, down: map[string]bool{}, opens: map[string]int{}}
+}
+
+func (f *fakeWarehouses) open(_ *Config, path string) (*sql.DB, error) {
+	f.opens[path]++
+	// Unmonitored pings always succeed; monitored pings without expectations fail.
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(f.down[path]))
+	require.NoError(f.t, err)
+	if f.down[path] {
+		mock.ExpectPing().WillReturnError(errors.New("warehouse unavailable"))
+	}
+	return db, nil
+}
+
+func TestWarehousePool_FailoverAfterThreshold(t *testing.T) {
+	warehouses := newFakeWarehouses(t)
+	warehouses.down["/primary"] = true
+
+	config := DefaultConfig()
+	config.FailoverThreshold = 2
+	pool := newWarehousePool([]string{"/primary", "/secondary"}, config, promslog.NewNopLogger(), warehouses.open)
+
+	_, err := pool.get()
+	require.Error(t, err, "expected first connection failure")
+	path, role := pool.activeWarehouse()
+	assert.Equal(t, "/primary", path, "should not fail over before threshold")
+	assert.Equal(t, warehouseRolePrimary, role)
+
+	_, err = pool.get()
+	require.Error(t, err, "expected second connection failure")
+	path, role = pool.activeWarehouse()
+	assert.Equal(t, "/secondary", path, "should fail over once threshold is reached")
+	assert.Equal(t, warehouseRoleFailover, role)
+
+	db, err := pool.get()
+	require.NoError(t, err, "expected connection to failover warehouse")
+	assert.NotNil(t, db)
+	assert.Equal(t, 1, warehouses.opens["/secondary"])
+}
+
+func TestWarehousePool_Failback(t *testing.T) {
+	warehouses := newFakeWarehouses(t)
+	warehouses.down["/primary"] = true
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := DefaultConfig()
+	config.FailoverThreshold = 1
+	config.FailbackInterval = 10 * time.Minute
+	pool := newWarehousePool([]string{"/primary", "/secondary"}, config, promslog.NewNopLogger(), warehouses.open)
+	pool.now = func() time.Time { return now }
+
+	_, err := pool.get()
+	require.Error(t, err)
+	_, err = pool.get()
+	require.NoError(t, err, "expected connection to failover warehouse")
+
+	// Primary recovers, but the failback interval has not elapsed yet.
+	warehouses.down["/primary"] = false
+	now = now.Add(5 * time.Minute)
+	_, err = pool.get()
+	require.NoError(t, err)
+	pool.probes.Wait()
+	path, _ := pool.activeWarehouse()
+	assert.Equal(t, "/secondary", path, "should not fail back before interval")
+
+	now = now.Add(5 * time.Minute)
+	_, err = pool.get()
+	require.NoError(t, err)
+	pool.probes.Wait()
+	path, role := pool.activeWarehouse()
+	assert.Equal(t, "/primary", path, "should fail back once the primary answers")
+	assert.Equal(t, warehouseRolePrimary, role)
+}
+
+func TestWarehousePool_FailbackKeepsFailoverWhenPrimaryDown(t *testing.T) {
+	warehouses := newFakeWarehouses(t)
+	warehouses.down["/primary"] = true
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := DefaultConfig()
+	config.FailoverThreshold = 1
+	pool := newWarehousePool([]string{"/primary", "/secondary"}, config, promslog.NewNopLogger(), warehouses.open)
+	pool.now = func() time.Time { return now }
+
+	_, _ = pool.get()
+	_, err := pool.get()
+	require.NoError(t, err)
+
+	now = now.Add(DefaultFailbackInterval)
+	db, err := pool.get()
+	require.NoError(t, err, "failed failback must not break the active connection")
+	assert.NotNil(t, db)
+	pool.probes.Wait()
+	path, _ := pool.activeWarehouse()
+	assert.Equal(t, "/secondary", path)
+	assert.Equal(t, 2, warehouses.opens["/primary"], "expected primary to be retried once")
+}
+
+func TestWarehousePool_FailbackRunsInBackground(t *testing.T) {
+	warehouses := newFakeWarehouses(t)
+	warehouses.down["/primary"] = true
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := DefaultConfig()
+	config.FailoverThreshold = 1
+	pool := newWarehousePool([]string{"/primary", "/secondary"}, config, promslog.NewNopLogger(), warehouses.open)
+	pool.now = func() time.Time { return now }
+
+	_, _ = pool.get()
+	_, err := pool.get()
+	require.NoError(t, err)
+
+	// Block the probe of the recovered primary until the scrapes below have returned.
+	warehouses.down["/primary"] = false
+	release := make(chan struct{})
+	pool.open = func(config *Config, path string) (*sql.DB, error) {
+		if path == "/primary" {
+			<-release
+		}
+		return warehouses.open(config, path)
+	}
+
+	now = now.Add(DefaultFailbackInterval)
+	for range 2 {
+		db, err := pool.get()
+		require.NoError(t, err, "scrapes must not wait for the failback probe")
+		assert.NotNil(t, db)
+	}
+	path, _ := pool.activeWarehouse()
+	assert.Equal(t, "/secondary", path)
+
+	close(release)
+	pool.probes.Wait()
+	path, _ = pool.activeWarehouse()
+	assert.Equal(t, "/primary", path)
+	assert.Equal(t, 2, warehouses.opens["/primary"], "expected a single failback probe")
+}
+
+func TestWarehousePool_ConnectDoesNotHoldLock(t *testing.T) {
+	warehouses := newFakeWarehouses(t)
+	opening := make(chan struct{})
+	release := make(chan struct{})
+	open := func(config *Config, path string) (*sql.DB, error) {
+		close(opening)
+		<-release
+		return warehouses.open(config, path)
+	}
+	pool := newWarehousePool([]string{"/primary", "/secondary"}, DefaultConfig(), promslog.NewNopLogger(), open)
+
+	done := make(chan error)
+	go func() {
+		_, err := pool.get()
+		done <- err
+	}()
+	<-opening
+
+	// Readiness and the active warehouse metric must not wait for the connection.
+	result := make(chan string)
+	go func() {
+		path, _ := pool.activeWarehouse()
+		_, _, _ = pool.lastResult()
+		result <- path
+	}()
+	select {
+	case path := <-result:
+		assert.Equal(t, "/primary", path)
+	case <-time.After(time.Second):
+		t.Fatal("pool state is locked while connecting")
+	}
+
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestWarehousePool_SingleWarehouseOpensLazily(t *testing.T) {
+	warehouses := newFakeWarehouses(t)
+	warehouses.down["/primary"] = true
+
+	pool := newWarehousePool([]string{"/primary"}, DefaultConfig(), promslog.NewNopLogger(), warehouses.open)
+
+	// Without failover configured the pool keeps the lazy sql.OpenDB behavior.
+	db, err := pool.get()
+	require.NoError(t, err)
+	assert.NotNil(t, db)
+}
+
+func TestConfigWarehouseHTTPPaths(t *testing.T) {
+	config := Config{
+		WarehouseHTTPPath:          "/primary",
+		FailoverWarehouseHTTPPaths: []string{"/secondary", "/tertiary"},
+	}
+	assert.Equal(t, []string{"/primary", "/secondary", "/tertiary"}, config.warehouseHTTPPaths())
+}
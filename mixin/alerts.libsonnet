@@ -46,6 +46,38 @@
                  'which is above the critical threshold of %(alertsSpendSpikeCritical)s%%. Immediate investigation required.') % this.config,
             },
           },
+          {
+            alert: 'DatabricksWarnCostAnomaly',
+            expr: |||
+              databricks_billing_cost_anomaly_score{} > %(alertsCostAnomalyScoreWarning)s
+            ||| % this.config,
+            'for': '5m',
+            labels: {
+              severity: 'warning',
+            },
+            annotations: {
+              summary: 'Databricks SKU cost is unusually high compared with the same weekday in previous weeks.',
+              description:
+                ('The daily cost of {{$labels.sku_name}} on workspace {{$labels.workspace_id}} is {{ printf "%%.1f" $value }} standard deviations ' +
+                 'above its same-weekday baseline, which is above the warning threshold of %(alertsCostAnomalyScoreWarning)s. Check cost drivers.') % this.config,
+            },
+          },
+          {
+            alert: 'DatabricksCriticalCostAnomaly',
+            expr: |||
+              databricks_billing_cost_anomaly_score{} > %(alertsCostAnomalyScoreCritical)s
+            ||| % this.config,
+            'for': '5m',
+            labels: {
+              severity: 'critical',
+            },
+            annotations: {
+              summary: 'Databricks SKU cost is critically high compared with the same weekday in previous weeks.',
+              description:
+                ('The daily cost of {{$labels.sku_name}} on workspace {{$labels.workspace_id}} is {{ printf "%%.1f" $value }} standard deviations ' +
+                 'above its same-weekday baseline, which is above the critical threshold of %(alertsCostAnomalyScoreCritical)s. Immediate investigation required.') % this.config,
+            },
+          },
           {
             alert: 'DatabricksWarnNoBillingData',
             expr: |||
@@ -126,8 +158,8 @@
             alert: 'DatabricksWarnJobDurationRegression',
             expr: |||
               (
-                databricks_job_run_duration_seconds_sliding{quantile="0.95"}
-                / quantile_over_time(0.5, databricks_job_run_duration_seconds_sliding{quantile="0.95"}[7d])
+                histogram_quantile(0.95, databricks_job_run_duration_histogram_seconds_sliding_bucket)
+                / quantile_over_time(0.5, histogram_quantile(0.95, databricks_job_run_duration_histogram_seconds_sliding_bucket)[7d:5m])
               ) - 1 > (%(alertsJobDurationRegressionWarning)s / 100)
             ||| % this.config,
             'for': '5m',
@@ -145,8 +177,8 @@
             alert: 'DatabricksCriticalJobDurationRegression',
             expr: |||
               (
-                databricks_job_run_duration_seconds_sliding{quantile="0.95"}
-                / quantile_over_time(0.5, databricks_job_run_duration_seconds_sliding{quantile="0.95"}[7d])
+                histogram_quantile(0.95, databricks_job_run_duration_histogram_seconds_sliding_bucket)
+                / quantile_over_time(0.5, histogram_quantile(0.95, databricks_job_run_duration_histogram_seconds_sliding_bucket)[7d:5m])
               ) - 1 > (%(alertsJobDurationRegressionCritical)s / 100)
             ||| % this.config,
             'for': '5m',
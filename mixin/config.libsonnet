@@ -19,6 +19,8 @@
   // for alerts - Finance Persona
   alertsSpendSpikeWarning: '25',  // % DoD increase
   alertsSpendSpikeCritical: '50',  // % DoD increase
+  alertsCostAnomalyScoreWarning: '3',  // robust std devs above same-weekday baseline
+  alertsCostAnomalyScoreCritical: '6',  // robust std devs above same-weekday baseline
   alertsNoBillingDataWarningLookback: '2h',
   alertsNoBillingDataCriticalLookback: '4h',
 
@@ -16,7 +16,7 @@ function(this) {
       unit: 's',
       sources: {
         prometheus: {
-          expr: 'max(databricks_job_run_duration_seconds_sliding{%(queriesSelector)s, quantile="0.95"})',
+          expr: 'max(histogram_quantile(0.95, databricks_job_run_duration_histogram_seconds_sliding_bucket{%(queriesSelector)s}))',
           exprWrappers: [['last_over_time(', '[30m:])']],
           legendCustomTemplate: 'Jobs p95',
         },
@@ -265,7 +265,7 @@ function(this) {
       unit: 's',
       sources: {
         prometheus: {
-          expr: 'databricks_job_run_duration_seconds_sliding{%(queriesSelector)s, quantile="0.95"}',
+          expr: 'histogram_quantile(0.95, databricks_job_run_duration_histogram_seconds_sliding_bucket{%(queriesSelector)s})',
           exprWrappers: [['last_over_time(', '[30m:])']],
           legendCustomTemplate: '{{job_name}} (p95)',
         },
@@ -21,7 +21,7 @@ function(this) {
       unit: 'none',  // would be 'dbu' but no custom unit available
       sources: {
         prometheus: {
-          expr: 'databricks_billing_dbus_sliding{%(queriesSelector)s}',
+          expr: 'databricks_billing_dbus_sliding{%(queriesSelector)s,usage_unit="DBU"}',
           exprWrappers: [['last_over_time(', '[30m:])']],
           legendCustomTemplate: '{{workspace_id}} - {{sku_name}}',
         },
@@ -89,7 +89,7 @@ function(this) {
       unit: 'none',  // would be 'dbu' but no custom unit available
       sources: {
         prometheus: {
-          expr: 'sum(databricks_billing_dbus_sliding{%(queriesSelector)s})',
+          expr: 'sum(databricks_billing_dbus_sliding{%(queriesSelector)s,usage_unit="DBU"})',
           legendCustomTemplate: 'DBUs',
         },
       },
@@ -122,7 +122,7 @@ function(this) {
             last_over_time((
               sum by (sku_name) (databricks_billing_cost_estimate_usd_sliding{%(queriesSelector)s})
               /
-              sum by (sku_name) (databricks_billing_dbus_sliding{%(queriesSelector)s})
+              sum by (sku_name) (databricks_billing_dbus_sliding{%(queriesSelector)s,usage_unit="DBU"})
             )[30m:])
           |||,
           legendCustomTemplate: '{{sku_name}}',